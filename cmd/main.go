@@ -1,20 +1,34 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"flag"
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 	"github.com/wanafiq/feed-api/internal/config"
+	"github.com/wanafiq/feed-api/internal/constants"
 	"github.com/wanafiq/feed-api/internal/database"
+	"github.com/wanafiq/feed-api/internal/email"
 	"github.com/wanafiq/feed-api/internal/handlers"
 	"github.com/wanafiq/feed-api/internal/logger"
+	"github.com/wanafiq/feed-api/internal/mailer"
 	"github.com/wanafiq/feed-api/internal/middleware"
 	"github.com/wanafiq/feed-api/internal/repository"
 	"github.com/wanafiq/feed-api/internal/routes"
 	"github.com/wanafiq/feed-api/internal/services"
+	"github.com/wanafiq/feed-api/internal/storage"
 	"go.uber.org/zap"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
 )
 
 func init() {
@@ -30,23 +44,73 @@ type application struct {
 	logger *zap.SugaredLogger
 
 	// repositories
-	userRepo     repository.UserRepository
-	roleRepo     repository.RoleRepository
-	tokenRepo    repository.TokenRepository
-	followerRepo repository.FollowerRepository
-	postRepo     repository.PostRepository
-	tagRepo      repository.TagRepository
+	userRepo         repository.UserRepository
+	roleRepo         repository.RoleRepository
+	tokenRepo        repository.TokenRepository
+	followerRepo     repository.FollowerRepository
+	postRepo         repository.PostRepository
+	tagRepo          repository.TagRepository
+	blockRepo        repository.BlockRepository
+	muteRepo         repository.MuteRepository
+	requestRepo      repository.FollowRequestRepository
+	reactionRepo     repository.ReactionRepository
+	bookmarkRepo     repository.BookmarkRepository
+	analyticsRepo    repository.AnalyticsRepository
+	dashboardRepo    repository.DashboardRepository
+	seriesRepo       repository.SeriesRepository
+	mediaRepo        repository.MediaRepository
+	sitemapRepo      repository.SitemapRepository
+	importRepo       repository.ImportRepository
+	eventRepo        repository.EventRepository
+	notificationRepo repository.NotificationRepository
+	digestRepo       repository.DigestRepository
+	streamRepo       repository.StreamRepository
+	webhookRepo      repository.WebhookRepository
+	auditRepo        repository.AuditRepository
+
+	mediaStorage storage.Storage
+	mailer       mailer.Mailer
 
 	// services
-	authService  *services.AuthService
-	emailService *services.EmailService
-	userService  *services.UserService
-	postService  *services.PostService
+	authService         *services.AuthService
+	emailService        *services.EmailService
+	auditService        *services.AuditService
+	eventService        *services.EventService
+	userService         *services.UserService
+	postService         *services.PostService
+	bookmarkService     *services.BookmarkService
+	analyticsService    *services.AnalyticsService
+	dashboardService    *services.DashboardService
+	seriesService       *services.SeriesService
+	mediaService        *services.MediaService
+	feedService         *services.FeedService
+	sitemapService      *services.SitemapService
+	archiveService      *services.ArchiveService
+	wordPressService    *services.WordPressImportService
+	notificationService *services.NotificationService
+	digestService       *services.DigestService
+	streamService       *services.StreamService
+	webhookService      *services.WebhookService
 
 	// handlers
-	authHandler *handlers.AuthHandler
-	userHandler *handlers.UserHandler
-	postHandler *handlers.PostHandler
+	authHandler         *handlers.AuthHandler
+	userHandler         *handlers.UserHandler
+	postHandler         *handlers.PostHandler
+	bookmarkHandler     *handlers.BookmarkHandler
+	dashboardHandler    *handlers.DashboardHandler
+	seriesHandler       *handlers.SeriesHandler
+	mediaHandler        *handlers.MediaHandler
+	feedHandler         *handlers.FeedHandler
+	sitemapHandler      *handlers.SitemapHandler
+	archiveHandler      *handlers.ArchiveHandler
+	importHandler       *handlers.ImportHandler
+	eventHandler        *handlers.EventHandler
+	notificationHandler *handlers.NotificationHandler
+	digestHandler       *handlers.DigestHandler
+	streamHandler       *handlers.StreamHandler
+	webhookHandler      *handlers.WebhookHandler
+	auditHandler        *handlers.AuditHandler
+	mailHandler         *handlers.MailHandler
 
 	middleware *middleware.Middleware
 	router     *gin.Engine
@@ -87,9 +151,62 @@ func main() {
 	app.followerRepo = repository.NewFollowerRepository(app.db)
 	app.postRepo = repository.NewPostRepository(app.db)
 	app.tagRepo = repository.NewTagRepository(app.db)
+	app.blockRepo = repository.NewBlockRepository(app.db)
+	app.muteRepo = repository.NewMuteRepository(app.db)
+	app.requestRepo = repository.NewFollowRequestRepository(app.db)
+	app.reactionRepo = repository.NewReactionRepository(app.db)
+	app.bookmarkRepo = repository.NewBookmarkRepository(app.db)
+	app.analyticsRepo = repository.NewAnalyticsRepository(app.db)
+	app.dashboardRepo = repository.NewDashboardRepository(app.db)
+	app.seriesRepo = repository.NewSeriesRepository(app.db)
+	app.mediaRepo = repository.NewMediaRepository(app.db)
+	app.sitemapRepo = repository.NewSitemapRepository(app.db)
+	app.importRepo = repository.NewImportRepository(app.db)
+	app.eventRepo = repository.NewEventRepository(app.db)
+	app.notificationRepo = repository.NewNotificationRepository(app.db)
+	app.digestRepo = repository.NewDigestRepository(app.db)
+	app.streamRepo = repository.NewStreamRepository(app.db)
+	app.webhookRepo = repository.NewWebhookRepository(app.db)
+	app.auditRepo = repository.NewAuditRepository(app.db)
+
+	// media storage
+	switch app.config.Media.Storage {
+	case "local":
+		localStorage, err := storage.NewLocalStorage(app.config.Media.Dir, app.config.Media.PublicURL)
+		if err != nil {
+			log.Fatalf("media storage initialization, %v", err)
+		}
+		app.mediaStorage = localStorage
+	default:
+		log.Fatalf("unsupported media storage %q", app.config.Media.Storage)
+	}
+
+	// mail transport
+	switch app.config.Mail.Transport {
+	case mailer.TransportSMTP:
+		smtp := app.config.Smtp
+		app.mailer = mailer.NewSMTPMailer(smtp.Host, smtp.Port, smtp.Username, smtp.Password)
+	case mailer.TransportFile:
+		fileMailer, err := mailer.NewFileMailer(app.config.Mail.Dir)
+		if err != nil {
+			log.Fatalf("mail transport initialization, %v", err)
+		}
+		app.mailer = fileMailer
+	case mailer.TransportMemory:
+		app.mailer = mailer.NewMemoryMailer(app.config.Mail.CaptureSize)
+	default:
+		log.Fatalf("unsupported mail transport %q", app.config.Mail.Transport)
+	}
+
+	emailTemplates, err := email.NewRegistry(email.Templates, email.Definitions)
+	if err != nil {
+		log.Fatalf("email templates initialization, %v", err)
+	}
 
 	// services
-	app.emailService = services.NewEmailService(app.config, app.logger)
+	app.emailService = services.NewEmailService(app.config, app.logger, app.mailer, emailTemplates)
+	app.auditService = services.NewAuditService(app.config, app.db, app.logger, app.auditRepo)
+	app.eventService = services.NewEventService(app.config, app.db, app.logger, app.eventRepo, app.auditService)
 	app.authService = services.NewAuthService(
 		app.config,
 		app.db,
@@ -98,20 +215,236 @@ func main() {
 		app.roleRepo,
 		app.tokenRepo,
 		app.emailService,
+		app.eventService,
+		app.auditService,
+	)
+	app.userService = services.NewUserService(
+		app.config,
+		app.db,
+		app.logger,
+		app.userRepo,
+		app.followerRepo,
+		app.blockRepo,
+		app.muteRepo,
+		app.requestRepo,
+		app.eventService,
+		app.auditService,
+	)
+	app.sitemapService = services.NewSitemapService(app.config, app.db, app.logger, app.sitemapRepo)
+	app.postService = services.NewPostService(
+		app.config,
+		app.db,
+		app.logger,
+		app.postRepo,
+		app.tagRepo,
+		app.userRepo,
+		app.blockRepo,
+		app.followerRepo,
+		app.reactionRepo,
+		app.bookmarkRepo,
+		app.analyticsRepo,
+		app.seriesRepo,
+		app.mediaRepo,
+		app.sitemapService,
+		app.eventService,
+		app.auditService,
+	)
+	app.bookmarkService = services.NewBookmarkService(app.config, app.db, app.logger, app.bookmarkRepo, app.postService)
+	app.analyticsService = services.NewAnalyticsService(
+		app.config,
+		app.db,
+		app.logger,
+		app.analyticsRepo,
+		app.postRepo,
+		app.reactionRepo,
 	)
-	app.userService = services.NewUserService(app.config, app.db, app.logger, app.userRepo, app.followerRepo)
-	app.postService = services.NewPostService(app.config, app.db, app.logger, app.postRepo, app.tagRepo, app.userRepo)
+	app.dashboardService = services.NewDashboardService(app.config, app.db, app.logger, app.dashboardRepo)
+	app.seriesService = services.NewSeriesService(app.config, app.db, app.logger, app.seriesRepo, app.postRepo, app.postService)
+	app.mediaService = services.NewMediaService(app.config, app.db, app.logger, app.mediaRepo, app.mediaStorage)
+	app.feedService = services.NewFeedService(app.config, app.db, app.logger, app.userRepo, app.postRepo, app.postService)
+	app.archiveService = services.NewArchiveService(app.config, app.db, app.logger, app.postRepo, app.postService)
+	app.wordPressService = services.NewWordPressImportService(app.config, app.db, app.logger, app.userRepo, app.importRepo, app.postService, app.auditService)
+	app.streamService = services.NewStreamService(
+		app.config,
+		app.db,
+		app.logger,
+		app.streamRepo,
+		app.followerRepo,
+		app.postRepo,
+		app.tokenRepo,
+		app.eventService,
+	)
+	app.notificationService = services.NewNotificationService(
+		app.config,
+		app.db,
+		app.logger,
+		app.notificationRepo,
+		app.userRepo,
+		app.postRepo,
+		app.streamService,
+		app.eventService,
+	)
+	app.webhookService = services.NewWebhookService(app.config, app.db, app.logger, app.webhookRepo, app.auditService, app.eventService)
+	app.digestService = services.NewDigestService(
+		app.config,
+		app.db,
+		app.logger,
+		app.digestRepo,
+		app.notificationService,
+		app.emailService,
+	)
+
+	// one-off commands run instead of the server
+	if len(os.Args) > 1 && os.Args[1] == "import-wordpress" {
+		if err := app.importWordPress(os.Args[2:]); err != nil {
+			log.Fatalf("wordpress import, %v", err)
+		}
+		return
+	}
 
 	// handlers
 	app.authHandler = handlers.NewAuthHandler(app.logger, app.authService)
 	app.userHandler = handlers.NewUserHandler(app.logger, app.userService)
-	app.postHandler = handlers.NewPostHandler(app.logger, app.postService)
+	app.postHandler = handlers.NewPostHandler(app.logger, app.postService, app.analyticsService)
+	app.bookmarkHandler = handlers.NewBookmarkHandler(app.logger, app.bookmarkService)
+	app.dashboardHandler = handlers.NewDashboardHandler(app.logger, app.dashboardService)
+	app.seriesHandler = handlers.NewSeriesHandler(app.logger, app.seriesService)
+	app.mediaHandler = handlers.NewMediaHandler(app.logger, app.mediaService, app.config.Media.MaxUploadSize)
+	app.feedHandler = handlers.NewFeedHandler(app.logger, app.feedService)
+	app.sitemapHandler = handlers.NewSitemapHandler(app.logger, app.sitemapService)
+	app.archiveHandler = handlers.NewArchiveHandler(app.logger, app.archiveService, app.config.Post.ImportMaxSize)
+	app.importHandler = handlers.NewImportHandler(app.logger, app.wordPressService, app.config.Post.ImportMaxSize)
+	app.eventHandler = handlers.NewEventHandler(app.logger, app.eventService)
+	app.notificationHandler = handlers.NewNotificationHandler(app.logger, app.notificationService)
+	app.digestHandler = handlers.NewDigestHandler(app.logger, app.digestService)
+	app.streamHandler = handlers.NewStreamHandler(app.logger, app.streamService, app.config.Stream.HeartbeatInterval)
+	app.webhookHandler = handlers.NewWebhookHandler(app.logger, app.webhookService)
+	app.auditHandler = handlers.NewAuditHandler(app.logger, app.auditService)
+	if memoryMailer, ok := app.mailer.(*mailer.MemoryMailer); ok && app.config.Env == constants.DevEnv {
+		app.mailHandler = handlers.NewMailHandler(app.logger, memoryMailer)
+	}
+
+	app.middleware = middleware.NewMiddleware(app.config, app.logger, app.tokenRepo)
+	app.router = routes.NewRoutes(
+		app.middleware,
+		app.authHandler,
+		app.userHandler,
+		app.postHandler,
+		app.bookmarkHandler,
+		app.dashboardHandler,
+		app.seriesHandler,
+		app.mediaHandler,
+		app.feedHandler,
+		app.sitemapHandler,
+		app.archiveHandler,
+		app.importHandler,
+		app.eventHandler,
+		app.notificationHandler,
+		app.digestHandler,
+		app.streamHandler,
+		app.webhookHandler,
+		app.auditHandler,
+		app.mailHandler,
+	)
+
+	// without trusted proxies the client IP is the peer address, X-Forwarded-For could be forged
+	if err := app.router.SetTrustedProxies(app.config.TrustedProxies); err != nil {
+		log.Fatalf("trusted proxies, %v", err)
+	}
+
+	// files of the local storage are served by the API itself
+	if app.config.Media.Storage == "local" {
+		app.router.Static(app.config.Media.URLPath, app.config.Media.Dir)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// background workers
+	var workers sync.WaitGroup
+	workers.Add(6)
+	go func() {
+		defer workers.Done()
+		app.analyticsService.Run(ctx)
+	}()
+	go func() {
+		defer workers.Done()
+		app.mediaService.Run(ctx)
+	}()
+	go func() {
+		defer workers.Done()
+		app.eventService.Run(ctx)
+	}()
+	go func() {
+		defer workers.Done()
+		app.digestService.Run(ctx)
+	}()
+	go func() {
+		defer workers.Done()
+		app.streamService.Run(ctx)
+	}()
+	go func() {
+		defer workers.Done()
+		app.webhookService.Run(ctx)
+	}()
+
+	server := &http.Server{
+		Addr:    ":" + app.config.Port,
+		Handler: app.router,
+	}
+
+	go func() {
+		fmt.Printf("starting server on port %s...\n", app.config.Port)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("server error: %v", err)
+		}
+	}()
 
-	app.middleware = middleware.NewMiddleware(app.config, app.logger)
-	app.router = routes.NewRoutes(app.middleware, app.authHandler, app.userHandler, app.postHandler)
+	<-ctx.Done()
+	fmt.Println("shutting down server...")
 
-	fmt.Printf("starting server on port %s...\n", app.config.Port)
-	if err := app.router.Run(":" + app.config.Port); err != nil {
-		log.Fatalf("server error: %v", err)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("server shutdown error: %v", err)
 	}
+
+	workers.Wait()
+}
+
+// importWordPress imports a WXR file from the command line:
+//
+//	main import-wordpress -file export.xml [-default-author email]
+func (app *application) importWordPress(args []string) error {
+	flags := flag.NewFlagSet("import-wordpress", flag.ExitOnError)
+	path := flags.String("file", "", "WordPress export (WXR) to import")
+	defaultAuthor := flags.String("default-author", "", "email of the user receiving posts of unknown authors")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *path == "" {
+		flags.Usage()
+		return errors.New("-file is required")
+	}
+
+	file, err := os.Open(*path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	result, err := app.wordPressService.Import(context.Background(), middleware.UserContext{}, file, *defaultAuthor)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range result.Items {
+		fmt.Printf("%-8s %-6s %s %s\n", item.Status, item.SourceID, item.Title, item.Error)
+	}
+	if len(result.UnmappedAuthors) > 0 {
+		fmt.Printf("authors without an account: %s\n", strings.Join(result.UnmappedAuthors, ", "))
+	}
+	fmt.Printf("imported %d, skipped %d, failed %d\n", result.Imported, result.Skipped, result.Failed)
+
+	return nil
 }
@@ -1,18 +1,32 @@
 package config
 
 import (
+	"crypto/hkdf"
+	"crypto/sha256"
 	"errors"
+	"fmt"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 )
 
 type Config struct {
-	Env         string
-	DatabaseURL string
-	Port        string
-	Jwt         *jwt
-	Smtp        *smtp
-	Url         *url
+	Env            string
+	DatabaseURL    string
+	Port           string
+	TrustedProxies []string // networks whose X-Forwarded-For is believed, none by default
+	Jwt            *jwt
+	Smtp           *smtp
+	Mail           *mail
+	Url            *url
+	Post           *post
+	Analytics      *analytics
+	Media          *media
+	Events         *events
+	Digest         *digest
+	Stream         *stream
+	Webhook        *webhook
 }
 
 type jwt struct {
@@ -30,8 +44,79 @@ type smtp struct {
 	From     string
 }
 
+type mail struct {
+	Transport   string // "smtp", "file" or "memory"
+	Dir         string // maildir the file transport writes to
+	CaptureSize int    // messages kept by the memory transport
+}
+
 type url struct {
 	Web string
+	API string // public base URL of this API, for links in emails
+}
+
+type post struct {
+	ReactionTypes   []string
+	RenderCacheSize int
+	ImportMaxSize   int64
+}
+
+type analytics struct {
+	ViewDedupWindow   time.Duration
+	ViewFlushInterval time.Duration
+	ViewBufferSize    int
+}
+
+type events struct {
+	PollInterval time.Duration
+	BatchSize    int
+	MaxAttempts  int
+	RetryBackoff time.Duration // delay before the first retry, doubled on each attempt
+	MaxBackoff   time.Duration
+	Lease        time.Duration // how long a claimed event stays hidden from other dispatchers
+}
+
+type digest struct {
+	Interval          time.Duration // how often to look for due digests
+	BatchSize         int
+	DefaultFrequency  string // for users who never chose one: "off", "daily" or "weekly"
+	MaxPosts          int
+	MaxNotifications  int
+	UnsubscribeSecret string        // signs unsubscribe links, derived from the JWT secret when not set
+	UnsubscribeTTL    time.Duration // how long an unsubscribe link works
+}
+
+type stream struct {
+	MaxConnectionsPerUser int
+	HeartbeatInterval     time.Duration
+	BufferSize            int           // events queued per connection before it is dropped as too slow
+	Retention             time.Duration // how long events can be resumed with Last-Event-ID
+}
+
+type webhook struct {
+	PollInterval time.Duration
+	BatchSize    int
+	MaxAttempts  int
+	RetryBackoff time.Duration // delay before the first retry, doubled on each attempt
+	MaxBackoff   time.Duration
+	Lease        time.Duration // how long a claimed delivery stays hidden from other senders
+	Timeout      time.Duration
+	DisableAfter int // deliveries failing in a row before the webhook is turned off
+}
+
+type media struct {
+	Storage         string // only "local" for now
+	Dir             string
+	URLPath         string
+	PublicURL       string // absolute base URL of stored files, so they pass URL validation
+	MaxUploadSize   int64
+	MaxImageWidth   int
+	MaxImageHeight  int
+	MaxImagePixels  int64 // width times height, bounds the memory needed to decode an image
+	UserQuota       int64
+	ThumbnailWidths []int
+	OrphanTTL       time.Duration
+	GCInterval      time.Duration
 }
 
 func LoadConfig() (*Config, error) {
@@ -49,6 +134,13 @@ func LoadConfig() (*Config, error) {
 		port = "8080"
 	}
 
+	var trustedProxies []string
+	for _, proxy := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			trustedProxies = append(trustedProxies, proxy)
+		}
+	}
+
 	jwtExpiryInHours, err := strconv.Atoi(os.Getenv("JWT_EXPIRY_IN_HOURS"))
 	if err != nil {
 		return nil, err
@@ -74,17 +166,169 @@ func LoadConfig() (*Config, error) {
 		From:     os.Getenv("SMTP_FROM"),
 	}
 
+	mailTransport := "smtp"
+	if env == "development" {
+		mailTransport = "memory"
+	}
+
+	mail := &mail{
+		Transport:   getEnv("MAIL_TRANSPORT", mailTransport),
+		Dir:         getEnv("MAIL_DIR", "mail"),
+		CaptureSize: getEnvInt("MAIL_CAPTURE_SIZE", 100),
+	}
+
 	url := &url{
 		Web: os.Getenv("WEB_URL"),
+		API: getEnv("API_URL", "http://localhost:"+port),
+	}
+
+	reactionTypes := os.Getenv("REACTION_TYPES")
+	if reactionTypes == "" {
+		reactionTypes = "like,love,insightful"
+	}
+
+	post := &post{
+		ReactionTypes:   splitList(reactionTypes),
+		RenderCacheSize: getEnvInt("RENDER_CACHE_SIZE", 1000),
+		ImportMaxSize:   int64(getEnvInt("POST_IMPORT_MAX_SIZE_MB", 20)) << 20,
+	}
+
+	analytics := &analytics{
+		ViewDedupWindow:   time.Duration(getEnvInt("VIEW_DEDUP_WINDOW_MINUTES", 30)) * time.Minute,
+		ViewFlushInterval: time.Duration(getEnvInt("VIEW_FLUSH_INTERVAL_SECONDS", 30)) * time.Second,
+		ViewBufferSize:    getEnvInt("VIEW_BUFFER_SIZE", 1000),
+	}
+
+	thumbnailWidths, err := splitInts(getEnv("MEDIA_THUMBNAIL_WIDTHS", "320,640,1280"))
+	if err != nil {
+		return nil, err
+	}
+
+	mediaURLPath := getEnv("MEDIA_URL_PATH", "/uploads")
+
+	media := &media{
+		Storage:         getEnv("MEDIA_STORAGE", "local"),
+		Dir:             getEnv("MEDIA_DIR", "uploads"),
+		URLPath:         mediaURLPath,
+		PublicURL:       getEnv("MEDIA_PUBLIC_URL", strings.TrimSuffix(url.API, "/")+mediaURLPath),
+		MaxUploadSize:   int64(getEnvInt("MEDIA_MAX_UPLOAD_SIZE_MB", 10)) << 20,
+		MaxImageWidth:   getEnvInt("MEDIA_MAX_IMAGE_WIDTH", 8000),
+		MaxImageHeight:  getEnvInt("MEDIA_MAX_IMAGE_HEIGHT", 8000),
+		MaxImagePixels:  int64(getEnvInt("MEDIA_MAX_IMAGE_PIXELS", 40_000_000)),
+		UserQuota:       int64(getEnvInt("MEDIA_USER_QUOTA_MB", 500)) << 20,
+		ThumbnailWidths: thumbnailWidths,
+		OrphanTTL:       time.Duration(getEnvInt("MEDIA_ORPHAN_TTL_HOURS", 24)) * time.Hour,
+		GCInterval:      time.Duration(getEnvInt("MEDIA_GC_INTERVAL_MINUTES", 60)) * time.Minute,
+	}
+
+	events := &events{
+		PollInterval: time.Duration(getEnvInt("EVENT_POLL_INTERVAL_SECONDS", 5)) * time.Second,
+		BatchSize:    getEnvInt("EVENT_BATCH_SIZE", 50),
+		MaxAttempts:  getEnvInt("EVENT_MAX_ATTEMPTS", 8),
+		RetryBackoff: time.Duration(getEnvInt("EVENT_RETRY_BACKOFF_SECONDS", 30)) * time.Second,
+		MaxBackoff:   time.Duration(getEnvInt("EVENT_MAX_BACKOFF_MINUTES", 360)) * time.Minute,
+		Lease:        time.Duration(getEnvInt("EVENT_LEASE_SECONDS", 300)) * time.Second,
+	}
+
+	unsubscribeSecret := os.Getenv("DIGEST_UNSUBSCRIBE_SECRET")
+	if unsubscribeSecret == "" {
+		// a key of its own, so unsubscribe links are never signed with the JWT secret
+		key, err := hkdf.Key(sha256.New, []byte(os.Getenv("JWT_SECRET")), nil, "feed digest unsubscribe", 32)
+		if err != nil {
+			return nil, err
+		}
+		unsubscribeSecret = string(key)
+	}
+
+	digest := &digest{
+		Interval:          time.Duration(getEnvInt("DIGEST_INTERVAL_MINUTES", 15)) * time.Minute,
+		BatchSize:         getEnvInt("DIGEST_BATCH_SIZE", 50),
+		DefaultFrequency:  getEnv("DIGEST_DEFAULT_FREQUENCY", "weekly"),
+		MaxPosts:          getEnvInt("DIGEST_MAX_POSTS", 10),
+		MaxNotifications:  getEnvInt("DIGEST_MAX_NOTIFICATIONS", 5),
+		UnsubscribeSecret: unsubscribeSecret,
+		UnsubscribeTTL:    time.Duration(getEnvInt("DIGEST_UNSUBSCRIBE_TTL_DAYS", 90)) * 24 * time.Hour,
+	}
+
+	stream := &stream{
+		MaxConnectionsPerUser: getEnvInt("STREAM_MAX_CONNECTIONS_PER_USER", 5),
+		HeartbeatInterval:     time.Duration(getEnvInt("STREAM_HEARTBEAT_SECONDS", 25)) * time.Second,
+		BufferSize:            getEnvInt("STREAM_BUFFER_SIZE", 64),
+		Retention:             time.Duration(getEnvInt("STREAM_RETENTION_HOURS", 24)) * time.Hour,
+	}
+
+	webhook := &webhook{
+		PollInterval: time.Duration(getEnvInt("WEBHOOK_POLL_INTERVAL_SECONDS", 5)) * time.Second,
+		BatchSize:    getEnvInt("WEBHOOK_BATCH_SIZE", 20),
+		MaxAttempts:  getEnvInt("WEBHOOK_MAX_ATTEMPTS", 8),
+		RetryBackoff: time.Duration(getEnvInt("WEBHOOK_RETRY_BACKOFF_SECONDS", 60)) * time.Second,
+		MaxBackoff:   time.Duration(getEnvInt("WEBHOOK_MAX_BACKOFF_MINUTES", 360)) * time.Minute,
+		Lease:        time.Duration(getEnvInt("WEBHOOK_LEASE_SECONDS", 120)) * time.Second,
+		Timeout:      time.Duration(getEnvInt("WEBHOOK_TIMEOUT_SECONDS", 10)) * time.Second,
+		DisableAfter: getEnvInt("WEBHOOK_DISABLE_AFTER", 5),
+	}
+
+	settings := []positiveSetting{
+		{"MAIL_CAPTURE_SIZE", int64(mail.CaptureSize)},
+		{"RENDER_CACHE_SIZE", int64(post.RenderCacheSize)},
+		{"POST_IMPORT_MAX_SIZE_MB", post.ImportMaxSize},
+		{"VIEW_DEDUP_WINDOW_MINUTES", int64(analytics.ViewDedupWindow)},
+		{"VIEW_FLUSH_INTERVAL_SECONDS", int64(analytics.ViewFlushInterval)},
+		{"VIEW_BUFFER_SIZE", int64(analytics.ViewBufferSize)},
+		{"MEDIA_MAX_UPLOAD_SIZE_MB", media.MaxUploadSize},
+		{"MEDIA_MAX_IMAGE_WIDTH", int64(media.MaxImageWidth)},
+		{"MEDIA_MAX_IMAGE_HEIGHT", int64(media.MaxImageHeight)},
+		{"MEDIA_MAX_IMAGE_PIXELS", media.MaxImagePixels},
+		{"MEDIA_USER_QUOTA_MB", media.UserQuota},
+		{"MEDIA_ORPHAN_TTL_HOURS", int64(media.OrphanTTL)},
+		{"MEDIA_GC_INTERVAL_MINUTES", int64(media.GCInterval)},
+		{"EVENT_POLL_INTERVAL_SECONDS", int64(events.PollInterval)},
+		{"EVENT_BATCH_SIZE", int64(events.BatchSize)},
+		{"EVENT_MAX_ATTEMPTS", int64(events.MaxAttempts)},
+		{"EVENT_RETRY_BACKOFF_SECONDS", int64(events.RetryBackoff)},
+		{"EVENT_MAX_BACKOFF_MINUTES", int64(events.MaxBackoff)},
+		{"EVENT_LEASE_SECONDS", int64(events.Lease)},
+		{"DIGEST_INTERVAL_MINUTES", int64(digest.Interval)},
+		{"DIGEST_BATCH_SIZE", int64(digest.BatchSize)},
+		{"DIGEST_UNSUBSCRIBE_TTL_DAYS", int64(digest.UnsubscribeTTL)},
+		{"STREAM_MAX_CONNECTIONS_PER_USER", int64(stream.MaxConnectionsPerUser)},
+		{"STREAM_HEARTBEAT_SECONDS", int64(stream.HeartbeatInterval)},
+		{"STREAM_BUFFER_SIZE", int64(stream.BufferSize)},
+		{"STREAM_RETENTION_HOURS", int64(stream.Retention)},
+		{"WEBHOOK_POLL_INTERVAL_SECONDS", int64(webhook.PollInterval)},
+		{"WEBHOOK_BATCH_SIZE", int64(webhook.BatchSize)},
+		{"WEBHOOK_MAX_ATTEMPTS", int64(webhook.MaxAttempts)},
+		{"WEBHOOK_RETRY_BACKOFF_SECONDS", int64(webhook.RetryBackoff)},
+		{"WEBHOOK_MAX_BACKOFF_MINUTES", int64(webhook.MaxBackoff)},
+		{"WEBHOOK_LEASE_SECONDS", int64(webhook.Lease)},
+		{"WEBHOOK_TIMEOUT_SECONDS", int64(webhook.Timeout)},
+		{"WEBHOOK_DISABLE_AFTER", int64(webhook.DisableAfter)},
+	}
+	for _, width := range media.ThumbnailWidths {
+		settings = append(settings, positiveSetting{"MEDIA_THUMBNAIL_WIDTHS", int64(width)})
+	}
+
+	err = validatePositive(settings...)
+	if err != nil {
+		return nil, err
 	}
 
 	return &Config{
-		Env:         env,
-		Port:        port,
-		DatabaseURL: os.Getenv("DATABASE_URL"),
-		Jwt:         jwt,
-		Smtp:        smtp,
-		Url:         url,
+		Env:            env,
+		Port:           port,
+		TrustedProxies: trustedProxies,
+		DatabaseURL:    os.Getenv("DATABASE_URL"),
+		Jwt:            jwt,
+		Smtp:           smtp,
+		Mail:           mail,
+		Url:            url,
+		Post:           post,
+		Analytics:      analytics,
+		Media:          media,
+		Events:         events,
+		Digest:         digest,
+		Stream:         stream,
+		Webhook:        webhook,
 	}, nil
 }
 
@@ -131,3 +375,62 @@ func validateRequiredConfig() error {
 
 	return nil
 }
+
+// positiveSetting is a setting that must be greater than zero.
+type positiveSetting struct {
+	key   string
+	value int64
+}
+
+// validatePositive rejects zero or negative intervals and sizes, which would
+// otherwise panic the tickers and channels they configure.
+func validatePositive(settings ...positiveSetting) error {
+	for _, setting := range settings {
+		if setting.value <= 0 {
+			return fmt.Errorf("%s must be positive", setting.key)
+		}
+	}
+	return nil
+}
+
+// splitList splits a comma separated value, dropping empty entries.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// splitInts parses a comma separated list of integers.
+func splitInts(value string) ([]int, error) {
+	var ints []int
+	for _, item := range splitList(value) {
+		i, err := strconv.Atoi(item)
+		if err != nil {
+			return nil, err
+		}
+		ints = append(ints, i)
+	}
+	return ints, nil
+}
+
+// getEnv reads an optional value, falling back to a default if missing.
+func getEnv(key string, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+// getEnvInt reads an optional integer, falling back to a default if missing or invalid.
+func getEnvInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
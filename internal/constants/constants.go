@@ -7,10 +7,74 @@ const (
 
 	QueryTimeout = time.Second * 5
 
+	MaxStatsRange = time.Hour * 24 * 366 // longest date range of dashboard statistics
+
 	RoleUser      = "user"
 	RoleModerator = "moderator"
 	RoleAdmin     = "admin"
 
 	ConfirmationToken           = "confirmation_token"
 	ConfirmationTokenExpireTime = time.Hour * 24 * 3 // 3 days
+	StreamTicket                = "stream_ticket"
+	StreamTicketExpireTime      = time.Second * 30
+
+	FollowStatusFollowing = "following"
+	FollowStatusRequested = "requested"
+
+	PostVisibilityPublic    = "public"
+	PostVisibilityUnlisted  = "unlisted"
+	PostVisibilityFollowers = "followers"
+	PostVisibilityPrivate   = "private"
+
+	PostUserRoleAuthor     = "author"
+	PostUserRoleCoAuthor   = "coauthor"
+	PostUserStatusPending  = "pending"
+	PostUserStatusAccepted = "accepted"
+
+	TopPostsByViews     = "views"
+	TopPostsByReactions = "reactions"
+
+	EventStatusPending   = "pending"
+	EventStatusCompleted = "completed"
+	EventStatusDead      = "dead"
+
+	EventUserRegistered  = "user.registered"
+	EventUserFollowed    = "user.followed"
+	EventFollowRequested = "user.follow_requested"
+	EventPostReacted     = "post.reacted"
+	EventPostPublished   = "post.published"
+	EventPostUpdated     = "post.updated"
+
+	NotificationFollow         = "follow"
+	NotificationFollowRequest  = "follow_request"
+	NotificationFollowAccepted = "follow_accepted"
+	NotificationReaction       = "reaction"
+
+	DigestFrequencyOff    = "off"
+	DigestFrequencyDaily  = "daily"
+	DigestFrequencyWeekly = "weekly"
+
+	StreamEventNotification = "notification"
+	StreamEventPost         = "post"
+
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+
+	AuditLogin           = "auth.login"
+	AuditLoginFailed     = "auth.login_failed"
+	AuditUserDeactivated = "user.deactivated"
+	AuditPostUpdated     = "post.updated"
+	AuditPostDeleted     = "post.deleted"
+	AuditWebhookCreated  = "webhook.created"
+	AuditWebhookUpdated  = "webhook.updated"
+	AuditWebhookDeleted  = "webhook.deleted"
+	AuditEventRetried    = "event.retried"
+	AuditPostsImported   = "post.imported"
+
+	AuditTargetUser    = "user"
+	AuditTargetPost    = "post"
+	AuditTargetWebhook = "webhook"
+	AuditTargetEvent   = "event"
+	AuditTargetImport  = "import"
 )
@@ -8,4 +8,26 @@ var (
 	ErrInvalidSigningMethod = errors.New("invalid signing method")
 	ErrExpiredJWT           = errors.New("JWT expired")
 	ErrUnauthorized         = errors.New("unauthorized")
+	ErrForbidden            = errors.New("insufficient permissions")
+	ErrSelfAction           = errors.New("cannot perform this action on yourself")
+	ErrBlocked              = errors.New("action not allowed, user is blocked")
+	ErrInvalidReaction      = errors.New("invalid reaction type")
+	ErrInvalidCursor        = errors.New("invalid cursor")
+	ErrPostInSeries         = errors.New("post already belongs to a series")
+	ErrInvalidSeriesOrder   = errors.New("order must list every post of the series exactly once")
+	ErrMediaTooLarge        = errors.New("file exceeds the maximum upload size")
+	ErrUnsupportedMediaType = errors.New("unsupported media type")
+	ErrImageTooLarge        = errors.New("image dimensions exceed the maximum")
+	ErrMediaQuotaExceeded   = errors.New("media storage quota exceeded")
+	ErrInvalidArchive       = errors.New("file is not a valid zip archive")
+	ErrImportTooLarge       = errors.New("file exceeds the maximum import size")
+	ErrTooManyImportFiles   = errors.New("archive contains too many files")
+	ErrInvalidWXR           = errors.New("file is not a valid WordPress export")
+	ErrInvalidNotification  = errors.New("invalid notification type")
+	ErrInvalidUnsubscribe   = errors.New("invalid or expired unsubscribe link")
+	ErrTooManyStreams       = errors.New("too many open streams")
+	ErrDeliveryPending      = errors.New("delivery is still pending")
+	ErrInvalidWebhookURL    = errors.New("webhook url must be http or https on a public host")
+	ErrPrivateAddress       = errors.New("address is not public")
+	ErrCollectionExists     = errors.New("a collection with this name already exists")
 )
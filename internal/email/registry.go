@@ -0,0 +1,215 @@
+package email
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"io/fs"
+	"path"
+	"reflect"
+	"strings"
+	texttemplate "text/template"
+)
+
+// Definition declares an email type.
+type Definition struct {
+	Name string
+	// Subject is a text/template rendered with the email data. A locale can
+	// translate it by defining a "subject" template in its text template.
+	Subject string
+	// Data is a zero value of the data the templates are rendered with.
+	Data any
+}
+
+// Rendered is an email ready to be sent.
+type Rendered struct {
+	Subject string
+	HTML    string
+	Text    string
+}
+
+type templates struct {
+	subject *texttemplate.Template
+	html    *htmltemplate.Template
+	text    *texttemplate.Template
+}
+
+type registeredEmail struct {
+	dataType reflect.Type
+	locales  map[string]*templates
+}
+
+// Registry holds the parsed templates of every email.
+type Registry struct {
+	emails map[string]*registeredEmail
+}
+
+// NewRegistry parses the templates of definitions from fsys, laid out as
+// templates/<locale>/<name>.{html,txt}.tmpl. Every email needs both templates
+// in DefaultLocale, and other locales must provide both or neither. Each
+// template is executed once with the zero value of its data type, so a
+// reference to a field the data does not have fails here rather than when the
+// email is sent.
+func NewRegistry(fsys fs.FS, definitions []Definition) (*Registry, error) {
+	root, err := fs.Sub(fsys, "templates")
+	if err != nil {
+		return nil, err
+	}
+
+	dirs, err := fs.ReadDir(root, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	registry := &Registry{emails: make(map[string]*registeredEmail)}
+	for _, def := range definitions {
+		registry.emails[def.Name] = &registeredEmail{
+			dataType: reflect.TypeOf(def.Data),
+			locales:  make(map[string]*templates),
+		}
+	}
+
+	for _, dir := range dirs {
+		if !dir.IsDir() {
+			continue
+		}
+		locale := normalizeLocale(dir.Name())
+
+		files, err := fs.ReadDir(root, dir.Name())
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			name, _, _ := strings.Cut(file.Name(), ".")
+			if _, ok := registry.emails[name]; !ok {
+				return nil, fmt.Errorf("email template %s/%s: no email named %q", dir.Name(), file.Name(), name)
+			}
+		}
+
+		for _, def := range definitions {
+			tmpls, err := parseTemplates(root, dir.Name(), def)
+			if err != nil {
+				return nil, err
+			}
+			if tmpls != nil {
+				registry.emails[def.Name].locales[locale] = tmpls
+			}
+		}
+	}
+
+	for _, def := range definitions {
+		if _, ok := registry.emails[def.Name].locales[DefaultLocale]; !ok {
+			return nil, fmt.Errorf("email %q: no templates for locale %q", def.Name, DefaultLocale)
+		}
+	}
+
+	return registry, nil
+}
+
+// parseTemplates parses the templates of one email in one locale. It returns nil
+// when the locale has none.
+func parseTemplates(root fs.FS, dir string, def Definition) (*templates, error) {
+	htmlPath := path.Join(dir, def.Name+".html.tmpl")
+	textPath := path.Join(dir, def.Name+".txt.tmpl")
+
+	_, htmlErr := fs.Stat(root, htmlPath)
+	_, textErr := fs.Stat(root, textPath)
+	switch {
+	case errors.Is(htmlErr, fs.ErrNotExist) && errors.Is(textErr, fs.ErrNotExist):
+		return nil, nil
+	case htmlErr != nil:
+		return nil, fmt.Errorf("email %q: %w", def.Name, htmlErr)
+	case textErr != nil:
+		return nil, fmt.Errorf("email %q: %w", def.Name, textErr)
+	}
+
+	html, err := htmltemplate.ParseFS(root, htmlPath)
+	if err != nil {
+		return nil, err
+	}
+
+	text, err := texttemplate.ParseFS(root, textPath)
+	if err != nil {
+		return nil, err
+	}
+
+	subject := text.Lookup("subject")
+	if subject == nil {
+		subject, err = texttemplate.New("subject").Parse(def.Subject)
+		if err != nil {
+			return nil, fmt.Errorf("email %q: subject: %w", def.Name, err)
+		}
+	}
+
+	tmpls := &templates{subject: subject, html: html, text: text}
+	if err := tmpls.execute(io.Discard, io.Discard, io.Discard, def.Data); err != nil {
+		return nil, fmt.Errorf("email %q, locale %q: %w", def.Name, dir, err)
+	}
+
+	return tmpls, nil
+}
+
+func (t *templates) execute(subject, html, text io.Writer, data any) error {
+	if err := t.subject.Execute(subject, data); err != nil {
+		return err
+	}
+	if err := t.html.Execute(html, data); err != nil {
+		return err
+	}
+
+	return t.text.Execute(text, data)
+}
+
+// Render renders an email in the locale closest to locale: "ms-MY" falls back
+// to "ms", then to DefaultLocale. data must be of the type the email declares.
+func (r *Registry) Render(name string, locale string, data any) (*Rendered, error) {
+	email, ok := r.emails[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown email %q", name)
+	}
+	if dataType := reflect.TypeOf(data); dataType != email.dataType {
+		return nil, fmt.Errorf("email %q: got data of type %v, want %v", name, dataType, email.dataType)
+	}
+
+	tmpls := email.locales[DefaultLocale]
+	for _, candidate := range LocaleCandidates(locale) {
+		if t, ok := email.locales[candidate]; ok {
+			tmpls = t
+			break
+		}
+	}
+
+	var subject, html, text bytes.Buffer
+	if err := tmpls.execute(&subject, &html, &text, data); err != nil {
+		return nil, err
+	}
+
+	return &Rendered{
+		Subject: strings.TrimSpace(subject.String()),
+		HTML:    html.String(),
+		Text:    text.String(),
+	}, nil
+}
+
+// LocaleCandidates returns locale and its parents, most specific first.
+func LocaleCandidates(locale string) []string {
+	locale = normalizeLocale(locale)
+
+	var candidates []string
+	for locale != "" {
+		candidates = append(candidates, locale)
+		i := strings.LastIndex(locale, "-")
+		if i < 0 {
+			break
+		}
+		locale = locale[:i]
+	}
+
+	return candidates
+}
+
+func normalizeLocale(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(locale, "_", "-"))
+}
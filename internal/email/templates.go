@@ -1,11 +1,33 @@
 package email
 
-import "embed"
+import (
+	"embed"
+	"github.com/wanafiq/feed-api/internal/types"
+)
 
-//go:embed "*.tmpl"
+//go:embed templates
 var Templates embed.FS
 
-var (
-	ConfirmationEmail         = "confirmation_email"
-	ConfirmationEmailTemplate = "confirmation_email.tmpl"
+// DefaultLocale is the locale every email has templates for, used when there
+// are none for the recipient's locale.
+const DefaultLocale = "en"
+
+const (
+	ConfirmationEmail = "confirmation_email"
+	DigestEmail       = "digest_email"
 )
+
+// Definitions lists every email the application sends. The templates of an
+// email live in templates/<locale>/<name>.html.tmpl and <name>.txt.tmpl.
+var Definitions = []Definition{
+	{
+		Name:    ConfirmationEmail,
+		Subject: "Complete Registration with Feed",
+		Data:    types.ConfirmationEmailData{},
+	},
+	{
+		Name:    DigestEmail,
+		Subject: "Your {{.Frequency}} digest from Feed",
+		Data:    types.DigestEmailData{},
+	},
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/wanafiq/feed-api/internal/constants"
+	"github.com/wanafiq/feed-api/internal/middleware"
+	"github.com/wanafiq/feed-api/internal/response"
+	"github.com/wanafiq/feed-api/internal/services"
+	"go.uber.org/zap"
+	"net/http"
+	"time"
+)
+
+type ArchiveHandler struct {
+	logger         *zap.SugaredLogger
+	archiveService *services.ArchiveService
+	importMaxSize  int64
+}
+
+func NewArchiveHandler(logger *zap.SugaredLogger, archiveService *services.ArchiveService, importMaxSize int64) *ArchiveHandler {
+	return &ArchiveHandler{
+		logger:         logger,
+		archiveService: archiveService,
+		importMaxSize:  importMaxSize,
+	}
+}
+
+// Export streams a zip of the current user's posts.
+func (h *ArchiveHandler) Export(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		response.Unauthorized(c, nil)
+		return
+	}
+
+	filename := fmt.Sprintf("posts-%s.zip", time.Now().Format("20060102"))
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+
+	// once streaming started the status cannot change, so a failure only ends the download early
+	if err := h.archiveService.Export(context.Background(), userCtx.ID, c.Writer); err != nil {
+		if !c.Writer.Written() {
+			c.Header("Content-Type", "")
+			c.Header("Content-Disposition", "")
+			response.InternalServerError(c)
+			return
+		}
+		h.logger.Errorw("failed to export posts", "userID", userCtx.ID, "error", err.Error())
+	}
+}
+
+// Import accepts a zip in the "file" field of a multipart form. With ?dryRun=true
+// nothing is saved.
+func (h *ArchiveHandler) Import(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		response.Unauthorized(c, nil)
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.importMaxSize+multipartOverhead)
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			response.RequestEntityTooLarge(c, constants.ErrImportTooLarge)
+			return
+		}
+		response.BadRequest(c, errors.New("file is required"))
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	defer file.Close()
+
+	dryRun := c.Query("dryRun") == "true"
+
+	result, err := h.archiveService.Import(context.Background(), userCtx.ID, file, fileHeader.Size, dryRun)
+	if err != nil {
+		switch {
+		case errors.Is(err, constants.ErrInvalidArchive), errors.Is(err, constants.ErrTooManyImportFiles):
+			response.BadRequest(c, err)
+		default:
+			response.InternalServerError(c)
+		}
+		return
+	}
+
+	response.OK(c, result, nil)
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/wanafiq/feed-api/internal/models"
+	"github.com/wanafiq/feed-api/internal/response"
+	"github.com/wanafiq/feed-api/internal/services"
+	"github.com/wanafiq/feed-api/internal/utils"
+	"go.uber.org/zap"
+)
+
+type AuditHandler struct {
+	logger       *zap.SugaredLogger
+	auditService *services.AuditService
+}
+
+func NewAuditHandler(logger *zap.SugaredLogger, auditService *services.AuditService) *AuditHandler {
+	return &AuditHandler{
+		logger:       logger,
+		auditService: auditService,
+	}
+}
+
+// GetAll lists audit logs, newest first, filtered by ?actorId=, ?action=,
+// ?targetType=, ?targetId=, ?since= and ?until=.
+func (h *AuditHandler) GetAll(c *gin.Context) {
+	offset := utils.ParseQueryInt(c, "offset", 0)
+
+	limit := utils.ParseQueryInt(c, "limit", 10)
+	if limit > 100 {
+		limit = 100
+	}
+
+	filter := models.AuditLogFilter{
+		ActorID:    c.Query("actorId"),
+		Action:     c.Query("action"),
+		TargetType: c.Query("targetType"),
+		TargetID:   c.Query("targetId"),
+		Since:      utils.ParseQueryTime(c, "since"),
+		Until:      utils.ParseQueryTime(c, "until"),
+		Offset:     offset,
+		Limit:      limit,
+	}
+
+	logs, count, err := h.auditService.GetAll(context.Background(), filter)
+	if err != nil {
+		response.InternalServerError(c)
+		return
+	}
+
+	pagination := response.Pagination{
+		Total:  count,
+		Limit:  limit,
+		Offset: offset,
+		Next:   utils.Min(offset+limit, count),
+		Prev:   utils.Max(offset-limit, 0),
+	}
+
+	response.OK(c, logs, &pagination)
+}
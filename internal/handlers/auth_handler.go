@@ -5,6 +5,7 @@ import (
 	"errors"
 	"github.com/gin-gonic/gin"
 	"github.com/wanafiq/feed-api/internal/constants"
+	"github.com/wanafiq/feed-api/internal/middleware"
 	"github.com/wanafiq/feed-api/internal/response"
 	"github.com/wanafiq/feed-api/internal/services"
 	"github.com/wanafiq/feed-api/internal/types"
@@ -46,7 +47,7 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	token, err := h.authService.Login(context.Background(), &req)
+	token, err := h.authService.Login(context.Background(), middleware.ClientContext(c), &req)
 	if err != nil {
 		switch {
 		case errors.Is(err, constants.ErrUnauthorized):
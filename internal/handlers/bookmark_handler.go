@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/wanafiq/feed-api/internal/constants"
+	"github.com/wanafiq/feed-api/internal/middleware"
+	"github.com/wanafiq/feed-api/internal/response"
+	"github.com/wanafiq/feed-api/internal/services"
+	"github.com/wanafiq/feed-api/internal/types"
+	"github.com/wanafiq/feed-api/internal/utils"
+	"go.uber.org/zap"
+	"io"
+)
+
+type BookmarkHandler struct {
+	logger          *zap.SugaredLogger
+	bookmarkService *services.BookmarkService
+}
+
+func NewBookmarkHandler(logger *zap.SugaredLogger, bookmarkService *services.BookmarkService) *BookmarkHandler {
+	return &BookmarkHandler{
+		logger:          logger,
+		bookmarkService: bookmarkService,
+	}
+}
+
+func (h *BookmarkHandler) Add(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		response.Unauthorized(c, nil)
+		return
+	}
+
+	postID := c.Param("postID")
+	if postID == "" {
+		response.BadRequest(c, errors.New("postID is required"))
+		return
+	}
+
+	// the body is optional, a bookmark without collection is allowed
+	var req types.BookmarkRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		response.BadRequest(c, err)
+		return
+	}
+
+	bookmark, err := h.bookmarkService.Add(context.Background(), userCtx.ID, postID, req.CollectionID)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			response.NotFound(c, nil)
+		default:
+			response.InternalServerError(c)
+		}
+		return
+	}
+
+	response.OK(c, bookmark, nil)
+}
+
+func (h *BookmarkHandler) Remove(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		response.Unauthorized(c, nil)
+		return
+	}
+
+	postID := c.Param("postID")
+	if postID == "" {
+		response.BadRequest(c, errors.New("postID is required"))
+		return
+	}
+
+	if err := h.bookmarkService.Remove(context.Background(), userCtx.ID, postID); err != nil {
+		response.InternalServerError(c)
+		return
+	}
+
+	response.NoContent(c)
+}
+
+func (h *BookmarkHandler) GetAll(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		response.Unauthorized(c, nil)
+		return
+	}
+
+	limit := utils.ParseQueryInt(c, "limit", 10)
+	if limit > 100 {
+		limit = 100
+	}
+	if limit < 1 {
+		limit = 10
+	}
+
+	cursor := c.Query("cursor")
+	collectionID := c.Query("collectionId")
+
+	bookmarks, next, err := h.bookmarkService.GetAll(context.Background(), userCtx.ID, collectionID, limit, cursor)
+	if err != nil {
+		switch {
+		case errors.Is(err, constants.ErrInvalidCursor):
+			response.BadRequest(c, err)
+		default:
+			response.InternalServerError(c)
+		}
+		return
+	}
+
+	response.OKWithCursor(c, bookmarks, &response.Cursor{
+		Limit: limit,
+		Next:  next,
+	})
+}
+
+func (h *BookmarkHandler) CreateCollection(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		response.Unauthorized(c, nil)
+		return
+	}
+
+	var req types.BookmarkCollectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+
+	collection, err := h.bookmarkService.CreateCollection(context.Background(), userCtx.ID, req.Name)
+	if err != nil {
+		switch {
+		case errors.Is(err, constants.ErrCollectionExists):
+			response.Conflict(c, err)
+		default:
+			response.InternalServerError(c)
+		}
+		return
+	}
+
+	response.Created(c, collection)
+}
+
+func (h *BookmarkHandler) GetCollections(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		response.Unauthorized(c, nil)
+		return
+	}
+
+	collections, err := h.bookmarkService.GetCollections(context.Background(), userCtx.ID)
+	if err != nil {
+		response.InternalServerError(c)
+		return
+	}
+
+	response.OK(c, collections, nil)
+}
+
+func (h *BookmarkHandler) DeleteCollection(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		response.Unauthorized(c, nil)
+		return
+	}
+
+	collectionID := c.Param("collectionID")
+	if collectionID == "" {
+		response.BadRequest(c, errors.New("collectionID is required"))
+		return
+	}
+
+	if err := h.bookmarkService.DeleteCollection(context.Background(), userCtx.ID, collectionID); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			response.NotFound(c, nil)
+		default:
+			response.InternalServerError(c)
+		}
+		return
+	}
+
+	response.NoContent(c)
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/wanafiq/feed-api/internal/constants"
+	"github.com/wanafiq/feed-api/internal/middleware"
+	"github.com/wanafiq/feed-api/internal/response"
+	"github.com/wanafiq/feed-api/internal/services"
+	"github.com/wanafiq/feed-api/internal/utils"
+	"go.uber.org/zap"
+	"time"
+)
+
+type DashboardHandler struct {
+	logger           *zap.SugaredLogger
+	dashboardService *services.DashboardService
+}
+
+func NewDashboardHandler(logger *zap.SugaredLogger, dashboardService *services.DashboardService) *DashboardHandler {
+	return &DashboardHandler{
+		logger:           logger,
+		dashboardService: dashboardService,
+	}
+}
+
+func (h *DashboardHandler) Get(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		response.Unauthorized(c, nil)
+		return
+	}
+
+	to := time.Now()
+	if t := utils.ParseQueryTime(c, "to"); t != nil {
+		to = *t
+	}
+
+	from := to.AddDate(0, 0, -30)
+	if t := utils.ParseQueryTime(c, "from"); t != nil {
+		from = *t
+	}
+
+	if from.After(to) {
+		response.BadRequest(c, errors.New("from must be before to"))
+		return
+	}
+
+	if to.Sub(from) > constants.MaxStatsRange {
+		response.BadRequest(c, errors.New("date range must not exceed 366 days"))
+		return
+	}
+
+	dashboard, err := h.dashboardService.Get(context.Background(), userCtx.ID, from, to)
+	if err != nil {
+		response.InternalServerError(c)
+		return
+	}
+
+	response.OK(c, dashboard, nil)
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/wanafiq/feed-api/internal/constants"
+	"github.com/wanafiq/feed-api/internal/middleware"
+	"github.com/wanafiq/feed-api/internal/response"
+	"github.com/wanafiq/feed-api/internal/services"
+	"github.com/wanafiq/feed-api/internal/types"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+// confirmUnsubscribePage posts back to the link it was opened from, so the
+// signed query is kept.
+const confirmUnsubscribePage = `<!doctype html>
+<html>
+    <head><meta name="viewport" content="width=device-width" /><title>Unsubscribe</title></head>
+    <body>
+        <p>Stop receiving digest emails from Feed?</p>
+        <form method="post">
+            <input type="hidden" name="List-Unsubscribe" value="One-Click" />
+            <button type="submit">Unsubscribe</button>
+        </form>
+    </body>
+</html>
+`
+
+const unsubscribedPage = `<!doctype html>
+<html>
+    <head><meta name="viewport" content="width=device-width" /><title>Unsubscribed</title></head>
+    <body><p>You will no longer receive digest emails from Feed. You can turn them back on in your settings.</p></body>
+</html>
+`
+
+type DigestHandler struct {
+	logger        *zap.SugaredLogger
+	digestService *services.DigestService
+}
+
+func NewDigestHandler(logger *zap.SugaredLogger, digestService *services.DigestService) *DigestHandler {
+	return &DigestHandler{
+		logger:        logger,
+		digestService: digestService,
+	}
+}
+
+func (h *DigestHandler) GetSettings(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		response.Unauthorized(c, nil)
+		return
+	}
+
+	settings, err := h.digestService.GetSettings(context.Background(), userCtx.ID)
+	if err != nil {
+		response.InternalServerError(c)
+		return
+	}
+
+	response.OK(c, settings, nil)
+}
+
+func (h *DigestHandler) UpdateSettings(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		response.Unauthorized(c, nil)
+		return
+	}
+
+	var req types.DigestSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+
+	settings, err := h.digestService.UpdateSettings(context.Background(), userCtx.ID, &req)
+	if err != nil {
+		response.InternalServerError(c)
+		return
+	}
+
+	response.OK(c, settings, nil)
+}
+
+// ConfirmUnsubscribe answers a click on the link in a digest with a page to
+// confirm. It changes nothing, as mail scanners open links to check them.
+func (h *DigestHandler) ConfirmUnsubscribe(c *gin.Context) {
+	err := h.digestService.CheckUnsubscribe(c.Query("user"), c.Query("expires"), c.Query("token"))
+	if err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(confirmUnsubscribePage))
+}
+
+// Unsubscribe turns digests off from the signed link in a digest, without
+// logging in. It serves both RFC 8058 one-click unsubscribe from mail clients
+// and the confirmation page.
+func (h *DigestHandler) Unsubscribe(c *gin.Context) {
+	err := h.digestService.Unsubscribe(context.Background(), c.Query("user"), c.Query("expires"), c.Query("token"))
+	if err != nil {
+		switch {
+		case errors.Is(err, constants.ErrInvalidUnsubscribe):
+			response.BadRequest(c, err)
+		default:
+			response.InternalServerError(c)
+		}
+		return
+	}
+
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(unsubscribedPage))
+}
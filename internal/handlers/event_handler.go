@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/wanafiq/feed-api/internal/constants"
+	"github.com/wanafiq/feed-api/internal/middleware"
+	"github.com/wanafiq/feed-api/internal/response"
+	"github.com/wanafiq/feed-api/internal/services"
+	"github.com/wanafiq/feed-api/internal/utils"
+	"go.uber.org/zap"
+	"strconv"
+)
+
+type EventHandler struct {
+	logger       *zap.SugaredLogger
+	eventService *services.EventService
+}
+
+func NewEventHandler(logger *zap.SugaredLogger, eventService *services.EventService) *EventHandler {
+	return &EventHandler{
+		logger:       logger,
+		eventService: eventService,
+	}
+}
+
+// GetAll lists outbox events, e.g. ?status=dead for the dead letters.
+func (h *EventHandler) GetAll(c *gin.Context) {
+	status := c.Query("status")
+	switch status {
+	case "", constants.EventStatusPending, constants.EventStatusCompleted, constants.EventStatusDead:
+	default:
+		response.BadRequest(c, errors.New("invalid status"))
+		return
+	}
+
+	offset := utils.ParseQueryInt(c, "offset", 0)
+
+	limit := utils.ParseQueryInt(c, "limit", 10)
+	if limit > 100 {
+		limit = 100
+	}
+
+	events, count, err := h.eventService.GetAll(context.Background(), status, offset, limit)
+	if err != nil {
+		response.InternalServerError(c)
+		return
+	}
+
+	pagination := response.Pagination{
+		Total:  count,
+		Limit:  limit,
+		Offset: offset,
+		Next:   utils.Min(offset+limit, count),
+		Prev:   utils.Max(offset-limit, 0),
+	}
+
+	response.OK(c, events, &pagination)
+}
+
+// Retry queues a dead-lettered event again.
+func (h *EventHandler) Retry(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		response.Unauthorized(c, nil)
+		return
+	}
+
+	eventID, err := strconv.ParseInt(c.Param("eventID"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, errors.New("invalid eventID"))
+		return
+	}
+
+	if err := h.eventService.Retry(context.Background(), userCtx, eventID); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			response.NotFound(c, nil)
+		default:
+			response.InternalServerError(c)
+		}
+		return
+	}
+
+	response.NoContent(c)
+}
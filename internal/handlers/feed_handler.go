@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/wanafiq/feed-api/internal/response"
+	"github.com/wanafiq/feed-api/internal/services"
+	"go.uber.org/zap"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+type FeedHandler struct {
+	logger      *zap.SugaredLogger
+	feedService *services.FeedService
+}
+
+func NewFeedHandler(logger *zap.SugaredLogger, feedService *services.FeedService) *FeedHandler {
+	return &FeedHandler{
+		logger:      logger,
+		feedService: feedService,
+	}
+}
+
+func (h *FeedHandler) GetSite(c *gin.Context) {
+	feed, err := h.feedService.GetSiteFeed(context.Background(), c.Request.URL.Path)
+	if err != nil {
+		response.InternalServerError(c)
+		return
+	}
+
+	h.write(c, feed)
+}
+
+func (h *FeedHandler) GetByUser(c *gin.Context) {
+	userID := c.Param("userID")
+	if userID == "" {
+		response.BadRequest(c, errors.New("userID is required"))
+		return
+	}
+
+	feed, err := h.feedService.GetAuthorFeed(context.Background(), userID, c.Request.URL.Path)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			response.NotFound(c, nil)
+		default:
+			response.InternalServerError(c)
+		}
+		return
+	}
+
+	h.write(c, feed)
+}
+
+func (h *FeedHandler) GetByTag(c *gin.Context) {
+	tag := c.Param("tag")
+	if tag == "" {
+		response.BadRequest(c, errors.New("tag is required"))
+		return
+	}
+
+	feed, err := h.feedService.GetTagFeed(context.Background(), tag, c.Request.URL.Path)
+	if err != nil {
+		response.InternalServerError(c)
+		return
+	}
+
+	h.write(c, feed)
+}
+
+// write answers conditional requests with 304 when the feed has not changed,
+// otherwise encodes the feed in the format named by the route's extension.
+func (h *FeedHandler) write(c *gin.Context, feed *services.Feed) {
+	c.Header("ETag", feed.ETag)
+	c.Header("Cache-Control", "public, max-age=300")
+	if !feed.LastModified.IsZero() {
+		c.Header("Last-Modified", feed.LastModified.UTC().Format(http.TimeFormat))
+	}
+
+	if notModified(c, feed) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	if err := h.feedService.LoadPosts(context.Background(), feed); err != nil {
+		response.InternalServerError(c)
+		return
+	}
+
+	format := strings.TrimPrefix(path.Ext(c.FullPath()), ".")
+
+	body, contentType, err := h.feedService.Encode(feed, format)
+	if err != nil {
+		h.logger.Errorw("failed to encode feed", "format", format, "error", err.Error())
+		response.InternalServerError(c)
+		return
+	}
+
+	c.Data(http.StatusOK, contentType, body)
+}
+
+// notModified follows RFC 9110: If-None-Match takes precedence over If-Modified-Since.
+func notModified(c *gin.Context, feed *services.Feed) bool {
+	if match := c.GetHeader("If-None-Match"); match != "" {
+		for _, tag := range strings.Split(match, ",") {
+			tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+			if tag == feed.ETag || tag == "*" {
+				return true
+			}
+		}
+		return false
+	}
+
+	since, err := http.ParseTime(c.GetHeader("If-Modified-Since"))
+	if err != nil || feed.LastModified.IsZero() {
+		return false
+	}
+
+	// header dates have second precision
+	return !feed.LastModified.Truncate(time.Second).After(since)
+}
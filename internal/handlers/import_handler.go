@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/wanafiq/feed-api/internal/constants"
+	"github.com/wanafiq/feed-api/internal/middleware"
+	"github.com/wanafiq/feed-api/internal/response"
+	"github.com/wanafiq/feed-api/internal/services"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+type ImportHandler struct {
+	logger                 *zap.SugaredLogger
+	wordPressImportService *services.WordPressImportService
+	importMaxSize          int64
+}
+
+func NewImportHandler(logger *zap.SugaredLogger, wordPressImportService *services.WordPressImportService, importMaxSize int64) *ImportHandler {
+	return &ImportHandler{
+		logger:                 logger,
+		wordPressImportService: wordPressImportService,
+		importMaxSize:          importMaxSize,
+	}
+}
+
+// WordPress imports a WXR export sent in the "file" field of a multipart form.
+// Posts of authors without an account go to the user of the optional
+// "defaultAuthorEmail" field.
+func (h *ImportHandler) WordPress(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		response.Unauthorized(c, nil)
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.importMaxSize+multipartOverhead)
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			response.RequestEntityTooLarge(c, constants.ErrImportTooLarge)
+			return
+		}
+		response.BadRequest(c, errors.New("file is required"))
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	defer file.Close()
+
+	result, err := h.wordPressImportService.Import(context.Background(), userCtx, file, c.PostForm("defaultAuthorEmail"))
+	if err != nil {
+		switch {
+		case errors.Is(err, constants.ErrInvalidWXR):
+			response.BadRequest(c, err)
+		case errors.Is(err, sql.ErrNoRows):
+			response.BadRequest(c, errors.New("default author not found"))
+		default:
+			response.InternalServerError(c)
+		}
+		return
+	}
+
+	response.OK(c, result, nil)
+}
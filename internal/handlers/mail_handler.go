@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/wanafiq/feed-api/internal/mailer"
+	"github.com/wanafiq/feed-api/internal/response"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+// MailHandler shows the emails captured by the memory transport. It is only
+// routed in development.
+type MailHandler struct {
+	logger *zap.SugaredLogger
+	mailer *mailer.MemoryMailer
+}
+
+func NewMailHandler(logger *zap.SugaredLogger, mailer *mailer.MemoryMailer) *MailHandler {
+	return &MailHandler{
+		logger: logger,
+		mailer: mailer,
+	}
+}
+
+// GetAll lists captured messages, newest first.
+func (h *MailHandler) GetAll(c *gin.Context) {
+	response.OK(c, h.mailer.Messages(), nil)
+}
+
+// GetByID renders a captured message as the recipient would see it, or its
+// plain text part with ?format=text.
+func (h *MailHandler) GetByID(c *gin.Context) {
+	msg, ok := h.mailer.Message(c.Param("messageID"))
+	if !ok {
+		response.NotFound(c, nil)
+		return
+	}
+
+	if c.Query("format") == "text" {
+		c.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(msg.Text))
+		return
+	}
+
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(msg.HTML))
+}
+
+// DeleteAll drops every captured message.
+func (h *MailHandler) DeleteAll(c *gin.Context) {
+	h.mailer.Reset()
+	response.NoContent(c)
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/wanafiq/feed-api/internal/constants"
+	"github.com/wanafiq/feed-api/internal/middleware"
+	"github.com/wanafiq/feed-api/internal/response"
+	"github.com/wanafiq/feed-api/internal/services"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+// multipartOverhead leaves room for the multipart boundaries and headers around the file.
+const multipartOverhead = 1 << 20
+
+type MediaHandler struct {
+	logger        *zap.SugaredLogger
+	mediaService  *services.MediaService
+	maxUploadSize int64
+}
+
+func NewMediaHandler(logger *zap.SugaredLogger, mediaService *services.MediaService, maxUploadSize int64) *MediaHandler {
+	return &MediaHandler{
+		logger:        logger,
+		mediaService:  mediaService,
+		maxUploadSize: maxUploadSize,
+	}
+}
+
+// Upload accepts a single image in the "file" field of a multipart form.
+func (h *MediaHandler) Upload(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		response.Unauthorized(c, nil)
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxUploadSize+multipartOverhead)
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			response.RequestEntityTooLarge(c, constants.ErrMediaTooLarge)
+			return
+		}
+		response.BadRequest(c, errors.New("file is required"))
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	defer file.Close()
+
+	media, err := h.mediaService.Upload(context.Background(), userCtx.ID, file, fileHeader.Size)
+	if err != nil {
+		switch {
+		case errors.Is(err, constants.ErrMediaTooLarge), errors.Is(err, constants.ErrImageTooLarge):
+			response.RequestEntityTooLarge(c, err)
+		case errors.Is(err, constants.ErrUnsupportedMediaType):
+			response.UnsupportedMediaType(c, err)
+		case errors.Is(err, constants.ErrMediaQuotaExceeded):
+			response.Forbidden(c, err)
+		default:
+			response.InternalServerError(c)
+		}
+		return
+	}
+
+	response.Created(c, media)
+}
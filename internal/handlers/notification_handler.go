@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/wanafiq/feed-api/internal/constants"
+	"github.com/wanafiq/feed-api/internal/middleware"
+	"github.com/wanafiq/feed-api/internal/models"
+	"github.com/wanafiq/feed-api/internal/response"
+	"github.com/wanafiq/feed-api/internal/services"
+	"github.com/wanafiq/feed-api/internal/utils"
+	"go.uber.org/zap"
+	"strconv"
+)
+
+type NotificationHandler struct {
+	logger              *zap.SugaredLogger
+	notificationService *services.NotificationService
+}
+
+func NewNotificationHandler(logger *zap.SugaredLogger, notificationService *services.NotificationService) *NotificationHandler {
+	return &NotificationHandler{
+		logger:              logger,
+		notificationService: notificationService,
+	}
+}
+
+// GetAll lists the caller's notifications, grouped, with ?unread=true for the
+// unread ones only.
+func (h *NotificationHandler) GetAll(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		response.Unauthorized(c, nil)
+		return
+	}
+
+	offset := utils.ParseQueryInt(c, "offset", 0)
+
+	limit := utils.ParseQueryInt(c, "limit", 20)
+	if limit > 100 {
+		limit = 100
+	}
+
+	filter := models.NotificationFilter{
+		UnreadOnly: c.Query("unread") == "true",
+		Offset:     offset,
+		Limit:      limit,
+	}
+
+	list, count, err := h.notificationService.GetAll(context.Background(), userCtx.ID, filter)
+	if err != nil {
+		response.InternalServerError(c)
+		return
+	}
+
+	pagination := response.Pagination{
+		Total:  count,
+		Limit:  limit,
+		Offset: offset,
+		Next:   utils.Min(offset+limit, count),
+		Prev:   utils.Max(offset-limit, 0),
+	}
+
+	response.OK(c, list, &pagination)
+}
+
+// MarkRead marks a notification, and those grouped with it, as read.
+func (h *NotificationHandler) MarkRead(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		response.Unauthorized(c, nil)
+		return
+	}
+
+	notificationID, err := strconv.ParseInt(c.Param("notificationID"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, errors.New("invalid notificationID"))
+		return
+	}
+
+	if err := h.notificationService.MarkRead(context.Background(), userCtx.ID, notificationID); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			response.NotFound(c, nil)
+		default:
+			response.InternalServerError(c)
+		}
+		return
+	}
+
+	response.NoContent(c)
+}
+
+func (h *NotificationHandler) MarkAllRead(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		response.Unauthorized(c, nil)
+		return
+	}
+
+	if err := h.notificationService.MarkAllRead(context.Background(), userCtx.ID); err != nil {
+		response.InternalServerError(c)
+		return
+	}
+
+	response.NoContent(c)
+}
+
+func (h *NotificationHandler) GetPreferences(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		response.Unauthorized(c, nil)
+		return
+	}
+
+	preferences, err := h.notificationService.GetPreferences(context.Background(), userCtx.ID)
+	if err != nil {
+		response.InternalServerError(c)
+		return
+	}
+
+	response.OK(c, preferences, nil)
+}
+
+// UpdatePreferences takes a map of notification type to whether it is enabled,
+// e.g. {"reaction": false}.
+func (h *NotificationHandler) UpdatePreferences(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		response.Unauthorized(c, nil)
+		return
+	}
+
+	var req map[string]bool
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+
+	preferences, err := h.notificationService.UpdatePreferences(context.Background(), userCtx.ID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, constants.ErrInvalidNotification):
+			response.BadRequest(c, err)
+		default:
+			response.InternalServerError(c)
+		}
+		return
+	}
+
+	response.OK(c, preferences, nil)
+}
@@ -2,9 +2,12 @@ package handlers
 
 import (
 	"context"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
 	"errors"
 	"github.com/gin-gonic/gin"
+	"github.com/wanafiq/feed-api/internal/constants"
 	"github.com/wanafiq/feed-api/internal/middleware"
 	"github.com/wanafiq/feed-api/internal/models"
 	"github.com/wanafiq/feed-api/internal/response"
@@ -12,18 +15,22 @@ import (
 	"github.com/wanafiq/feed-api/internal/types"
 	"github.com/wanafiq/feed-api/internal/utils"
 	"go.uber.org/zap"
+	"slices"
 	"strings"
+	"time"
 )
 
 type PostHandler struct {
-	logger      *zap.SugaredLogger
-	postService *services.PostService
+	logger           *zap.SugaredLogger
+	postService      *services.PostService
+	analyticsService *services.AnalyticsService
 }
 
-func NewPostHandler(logger *zap.SugaredLogger, postService *services.PostService) *PostHandler {
+func NewPostHandler(logger *zap.SugaredLogger, postService *services.PostService, analyticsService *services.AnalyticsService) *PostHandler {
 	return &PostHandler{
-		logger:      logger,
-		postService: postService,
+		logger:           logger,
+		postService:      postService,
+		analyticsService: analyticsService,
 	}
 }
 
@@ -50,59 +57,62 @@ func (h *PostHandler) Save(c *gin.Context) {
 }
 
 func (h *PostHandler) GetAll(c *gin.Context) {
-	offset := utils.ParseQueryInt(c, "offset", 0)
+	userCtx, _ := middleware.GetUserContext(c)
 
-	limit := utils.ParseQueryInt(c, "limit", 10)
-	if limit > 100 {
-		limit = 100
-	}
+	h.listPosts(c, postFilter(c, userCtx.ID))
+}
 
-	search := c.DefaultQuery("search", "")
+// GetByUser lists the posts a user wrote or co-wrote.
+func (h *PostHandler) GetByUser(c *gin.Context) {
+	userCtx, _ := middleware.GetUserContext(c)
 
-	sort := strings.ToLower(c.DefaultQuery("sort", "desc"))
-	if sort != "asc" && sort != "desc" {
-		sort = "desc"
+	userID := c.Param("userID")
+	if userID == "" {
+		response.BadRequest(c, errors.New("userID is required"))
+		return
 	}
 
-	tags := c.QueryArray("tags")
-	dateFrom := utils.ParseQueryTime(c, "from")
-	dateTo := utils.ParseQueryTime(c, "to")
+	filter := postFilter(c, userCtx.ID)
+	filter.AuthorID = userID
 
-	filter := models.PostFilter{
-		Offset:   offset,
-		Limit:    limit,
-		Search:   search,
-		Sort:     sort,
-		DateFrom: dateFrom,
-		DateTo:   dateTo,
-		Tags:     tags,
-	}
+	h.listPosts(c, filter)
+}
 
+func (h *PostHandler) listPosts(c *gin.Context, filter models.PostFilter) {
 	posts, count, err := h.postService.GetAll(context.Background(), filter)
 	if err != nil {
 		response.InternalServerError(c)
 		return
 	}
 
+	if renderHTML(c) {
+		if err := h.postService.RenderHTML(posts...); err != nil {
+			response.InternalServerError(c)
+			return
+		}
+	}
+
 	pagination := response.Pagination{
 		Total:  count,
-		Limit:  limit,
-		Offset: offset,
-		Next:   utils.Min(offset+limit, count),
-		Prev:   utils.Max(offset-limit, 0),
+		Limit:  filter.Limit,
+		Offset: filter.Offset,
+		Next:   utils.Min(filter.Offset+filter.Limit, count),
+		Prev:   utils.Max(filter.Offset-filter.Limit, 0),
 	}
 
 	response.OK(c, posts, &pagination)
 }
 
 func (h *PostHandler) GetByID(c *gin.Context) {
+	userCtx, _ := middleware.GetUserContext(c)
+
 	postID := c.Param("postID")
 	if postID == "" {
 		response.BadRequest(c, errors.New("postID is required"))
 		return
 	}
 
-	post, err := h.postService.GetPostByID(context.Background(), postID)
+	post, err := h.postService.GetPostByID(context.Background(), userCtx.ID, postID)
 	if err != nil {
 		switch {
 		case errors.Is(err, sql.ErrNoRows):
@@ -113,9 +123,89 @@ func (h *PostHandler) GetByID(c *gin.Context) {
 		return
 	}
 
+	if renderHTML(c) {
+		if err := h.postService.RenderHTML(post); err != nil {
+			response.InternalServerError(c)
+			return
+		}
+	}
+
+	// authors reading their own posts are not counted
+	if !isPostAuthor(post, userCtx.ID) {
+		h.analyticsService.RecordView(post.ID, viewerKey(c, userCtx.ID))
+	}
+
 	response.OK(c, post, nil)
 }
 
+// GetMeta returns the head tags of a post page for server-side rendering.
+func (h *PostHandler) GetMeta(c *gin.Context) {
+	userCtx, _ := middleware.GetUserContext(c)
+
+	postID := c.Param("postID")
+	if postID == "" {
+		response.BadRequest(c, errors.New("postID is required"))
+		return
+	}
+
+	meta, err := h.postService.GetMeta(context.Background(), userCtx.ID, postID)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			response.NotFound(c, nil)
+		default:
+			response.InternalServerError(c)
+		}
+		return
+	}
+
+	response.OK(c, meta, nil)
+}
+
+func (h *PostHandler) GetStats(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		response.Unauthorized(c, nil)
+		return
+	}
+
+	postID := c.Param("postID")
+	if postID == "" {
+		response.BadRequest(c, errors.New("postID is required"))
+		return
+	}
+
+	to := time.Now()
+	if t := utils.ParseQueryTime(c, "to"); t != nil {
+		to = *t
+	}
+
+	from := to.AddDate(0, 0, -30)
+	if t := utils.ParseQueryTime(c, "from"); t != nil {
+		from = *t
+	}
+
+	if from.After(to) {
+		response.BadRequest(c, errors.New("from must be before to"))
+		return
+	}
+
+	stats, err := h.analyticsService.GetPostStats(context.Background(), userCtx, postID, from, to)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			response.NotFound(c, nil)
+		case errors.Is(err, constants.ErrForbidden):
+			response.Forbidden(c, err)
+		default:
+			response.InternalServerError(c)
+		}
+		return
+	}
+
+	response.OK(c, stats, nil)
+}
+
 func (h *PostHandler) Update(c *gin.Context) {
 	userCtx, exists := middleware.GetUserContext(c)
 	if !exists {
@@ -140,26 +230,242 @@ func (h *PostHandler) Update(c *gin.Context) {
 		switch {
 		case errors.Is(err, sql.ErrNoRows):
 			response.NotFound(c, nil)
+		case errors.Is(err, constants.ErrForbidden):
+			response.Forbidden(c, err)
 		default:
 			response.InternalServerError(c)
 		}
+		return
 	}
 
 	response.OK(c, post, nil)
 }
 
 func (h *PostHandler) Delete(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		response.Unauthorized(c, nil)
+		return
+	}
+
 	postID := c.Param("postID")
 	if postID == "" {
 		response.BadRequest(c, errors.New("postID is required"))
 		return
 	}
 
-	err := h.postService.Delete(context.Background(), postID)
+	err := h.postService.Delete(context.Background(), userCtx, postID)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			response.NotFound(c, nil)
+		case errors.Is(err, constants.ErrForbidden):
+			response.Forbidden(c, err)
+		default:
+			response.InternalServerError(c)
+		}
+		return
+	}
+
+	response.NoContent(c)
+}
+
+func (h *PostHandler) InviteCoAuthor(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		response.Unauthorized(c, nil)
+		return
+	}
+
+	postID := c.Param("postID")
+	if postID == "" {
+		response.BadRequest(c, errors.New("postID is required"))
+		return
+	}
+
+	var req types.CoAuthorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+
+	err := h.postService.InviteCoAuthor(context.Background(), userCtx.ID, postID, req.UserID)
+	h.handleCoAuthorResult(c, err)
+}
+
+func (h *PostHandler) RemoveCoAuthor(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		response.Unauthorized(c, nil)
+		return
+	}
+
+	postID := c.Param("postID")
+	if postID == "" {
+		response.BadRequest(c, errors.New("postID is required"))
+		return
+	}
+
+	userID := c.Param("userID")
+	if userID == "" {
+		response.BadRequest(c, errors.New("userID is required"))
+		return
+	}
+
+	err := h.postService.RemoveCoAuthor(context.Background(), userCtx.ID, postID, userID)
+	h.handleCoAuthorResult(c, err)
+}
+
+func (h *PostHandler) AcceptCoAuthor(c *gin.Context) {
+	h.respondToInvitation(c, h.postService.AcceptCoAuthor)
+}
+
+func (h *PostHandler) DeclineCoAuthor(c *gin.Context) {
+	h.respondToInvitation(c, h.postService.DeclineCoAuthor)
+}
+
+func (h *PostHandler) GetCoAuthorInvitations(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		response.Unauthorized(c, nil)
+		return
+	}
+
+	invitations, err := h.postService.GetCoAuthorInvitations(context.Background(), userCtx.ID)
 	if err != nil {
 		response.InternalServerError(c)
 		return
 	}
 
+	response.OK(c, invitations, nil)
+}
+
+func (h *PostHandler) respondToInvitation(c *gin.Context, fn func(ctx context.Context, userID string, postID string) error) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		response.Unauthorized(c, nil)
+		return
+	}
+
+	postID := c.Param("postID")
+	if postID == "" {
+		response.BadRequest(c, errors.New("postID is required"))
+		return
+	}
+
+	h.handleCoAuthorResult(c, fn(context.Background(), userCtx.ID, postID))
+}
+
+func (h *PostHandler) handleCoAuthorResult(c *gin.Context, err error) {
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			response.NotFound(c, nil)
+		case errors.Is(err, constants.ErrSelfAction), errors.Is(err, constants.ErrBlocked):
+			response.BadRequest(c, err)
+		case errors.Is(err, constants.ErrForbidden):
+			response.Forbidden(c, err)
+		default:
+			response.InternalServerError(c)
+		}
+		return
+	}
+
 	response.NoContent(c)
 }
+
+func (h *PostHandler) React(c *gin.Context) {
+	h.updateReaction(c, h.postService.React)
+}
+
+func (h *PostHandler) Unreact(c *gin.Context) {
+	h.updateReaction(c, h.postService.Unreact)
+}
+
+func (h *PostHandler) updateReaction(c *gin.Context, fn func(ctx context.Context, userID string, postID string, reactionType string) error) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		response.Unauthorized(c, nil)
+		return
+	}
+
+	postID := c.Param("postID")
+	if postID == "" {
+		response.BadRequest(c, errors.New("postID is required"))
+		return
+	}
+
+	reactionType := c.Param("type")
+
+	if err := fn(context.Background(), userCtx.ID, postID, reactionType); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			response.NotFound(c, nil)
+		case errors.Is(err, constants.ErrInvalidReaction):
+			response.BadRequest(c, err)
+		default:
+			response.InternalServerError(c)
+		}
+		return
+	}
+
+	response.NoContent(c)
+}
+
+// postFilter reads the listing query parameters shared by all post listings.
+func postFilter(c *gin.Context, viewerID string) models.PostFilter {
+	offset := utils.ParseQueryInt(c, "offset", 0)
+
+	limit := utils.ParseQueryInt(c, "limit", 10)
+	if limit > 100 {
+		limit = 100
+	}
+
+	sort := strings.ToLower(c.DefaultQuery("sort", "desc"))
+	if sort != "asc" && sort != "desc" {
+		sort = "desc"
+	}
+
+	return models.PostFilter{
+		Offset:   offset,
+		Limit:    limit,
+		Search:   c.DefaultQuery("search", ""),
+		Sort:     sort,
+		DateFrom: utils.ParseQueryTime(c, "from"),
+		DateTo:   utils.ParseQueryTime(c, "to"),
+		Tags:     c.QueryArray("tags"),
+		Content:  slices.Contains(strings.Split(c.Query("fields"), ","), "content"),
+		ViewerID: viewerID,
+	}
+}
+
+// renderHTML reports whether the client asked for rendered content with ?render=html.
+func renderHTML(c *gin.Context) bool {
+	return c.Query("render") == "html"
+}
+
+func isPostAuthor(post *models.Post, userID string) bool {
+	if userID == "" {
+		return false
+	}
+	if post.AuthorID == userID {
+		return true
+	}
+	for _, author := range post.Authors {
+		if author.ID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// viewerKey identifies a viewer for view de-duplication: the user id when
+// authenticated, otherwise a hash of the client address and user agent.
+func viewerKey(c *gin.Context, userID string) string {
+	if userID != "" {
+		return userID
+	}
+
+	sum := sha256.Sum256([]byte(c.ClientIP() + "|" + c.Request.UserAgent()))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/wanafiq/feed-api/internal/constants"
+	"github.com/wanafiq/feed-api/internal/middleware"
+	"github.com/wanafiq/feed-api/internal/response"
+	"github.com/wanafiq/feed-api/internal/services"
+	"github.com/wanafiq/feed-api/internal/types"
+	"go.uber.org/zap"
+)
+
+type SeriesHandler struct {
+	logger        *zap.SugaredLogger
+	seriesService *services.SeriesService
+}
+
+func NewSeriesHandler(logger *zap.SugaredLogger, seriesService *services.SeriesService) *SeriesHandler {
+	return &SeriesHandler{
+		logger:        logger,
+		seriesService: seriesService,
+	}
+}
+
+func (h *SeriesHandler) Create(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		response.Unauthorized(c, nil)
+		return
+	}
+
+	var req types.SeriesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+
+	series, err := h.seriesService.Create(context.Background(), userCtx.ID, &req)
+	if err != nil {
+		response.InternalServerError(c)
+		return
+	}
+
+	response.Created(c, series)
+}
+
+func (h *SeriesHandler) GetByID(c *gin.Context) {
+	userCtx, _ := middleware.GetUserContext(c)
+
+	seriesID := c.Param("seriesID")
+	if seriesID == "" {
+		response.BadRequest(c, errors.New("seriesID is required"))
+		return
+	}
+
+	series, err := h.seriesService.GetByID(context.Background(), userCtx.ID, seriesID)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			response.NotFound(c, nil)
+		default:
+			response.InternalServerError(c)
+		}
+		return
+	}
+
+	response.OK(c, series, nil)
+}
+
+func (h *SeriesHandler) AddPost(c *gin.Context) {
+	h.updateSeriesPost(c, h.seriesService.AddPost)
+}
+
+func (h *SeriesHandler) RemovePost(c *gin.Context) {
+	h.updateSeriesPost(c, h.seriesService.RemovePost)
+}
+
+func (h *SeriesHandler) Reorder(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		response.Unauthorized(c, nil)
+		return
+	}
+
+	seriesID := c.Param("seriesID")
+	if seriesID == "" {
+		response.BadRequest(c, errors.New("seriesID is required"))
+		return
+	}
+
+	var req types.SeriesOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+
+	err := h.seriesService.Reorder(context.Background(), userCtx.ID, seriesID, req.PostIDs)
+	h.handleSeriesResult(c, err)
+}
+
+func (h *SeriesHandler) updateSeriesPost(c *gin.Context, fn func(ctx context.Context, userID string, seriesID string, postID string) error) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		response.Unauthorized(c, nil)
+		return
+	}
+
+	seriesID := c.Param("seriesID")
+	if seriesID == "" {
+		response.BadRequest(c, errors.New("seriesID is required"))
+		return
+	}
+
+	postID := c.Param("postID")
+	if postID == "" {
+		response.BadRequest(c, errors.New("postID is required"))
+		return
+	}
+
+	h.handleSeriesResult(c, fn(context.Background(), userCtx.ID, seriesID, postID))
+}
+
+func (h *SeriesHandler) handleSeriesResult(c *gin.Context, err error) {
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			response.NotFound(c, nil)
+		case errors.Is(err, constants.ErrPostInSeries), errors.Is(err, constants.ErrInvalidSeriesOrder):
+			response.BadRequest(c, err)
+		case errors.Is(err, constants.ErrForbidden):
+			response.Forbidden(c, err)
+		default:
+			response.InternalServerError(c)
+		}
+		return
+	}
+
+	response.NoContent(c)
+}
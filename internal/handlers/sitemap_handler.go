@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/wanafiq/feed-api/internal/response"
+	"github.com/wanafiq/feed-api/internal/services"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type SitemapHandler struct {
+	logger         *zap.SugaredLogger
+	sitemapService *services.SitemapService
+}
+
+func NewSitemapHandler(logger *zap.SugaredLogger, sitemapService *services.SitemapService) *SitemapHandler {
+	return &SitemapHandler{
+		logger:         logger,
+		sitemapService: sitemapService,
+	}
+}
+
+func (h *SitemapHandler) GetIndex(c *gin.Context) {
+	body, err := h.sitemapService.GetIndex(context.Background())
+	if err != nil {
+		response.InternalServerError(c)
+		return
+	}
+
+	c.Data(http.StatusOK, "application/xml; charset=utf-8", body)
+}
+
+// GetSitemap serves a child sitemap named like posts-1.xml.
+func (h *SitemapHandler) GetSitemap(c *gin.Context) {
+	name, ok := strings.CutSuffix(c.Param("name"), ".xml")
+	if !ok {
+		response.NotFound(c, nil)
+		return
+	}
+
+	kind, pageParam, _ := strings.Cut(name, "-")
+	page, err := strconv.Atoi(pageParam)
+	if err != nil {
+		response.NotFound(c, nil)
+		return
+	}
+
+	body, err := h.sitemapService.GetSitemap(context.Background(), kind, page)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			response.NotFound(c, nil)
+		default:
+			response.InternalServerError(c)
+		}
+		return
+	}
+
+	c.Data(http.StatusOK, "application/xml; charset=utf-8", body)
+}
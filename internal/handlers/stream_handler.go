@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/wanafiq/feed-api/internal/constants"
+	"github.com/wanafiq/feed-api/internal/middleware"
+	"github.com/wanafiq/feed-api/internal/models"
+	"github.com/wanafiq/feed-api/internal/response"
+	"github.com/wanafiq/feed-api/internal/services"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// streamRetry is how long browsers wait before reconnecting a dropped stream.
+const streamRetry = 5 * time.Second
+
+type StreamHandler struct {
+	logger            *zap.SugaredLogger
+	streamService     *services.StreamService
+	heartbeatInterval time.Duration
+}
+
+func NewStreamHandler(logger *zap.SugaredLogger, streamService *services.StreamService, heartbeatInterval time.Duration) *StreamHandler {
+	return &StreamHandler{
+		logger:            logger,
+		streamService:     streamService,
+		heartbeatInterval: heartbeatInterval,
+	}
+}
+
+// CreateTicket issues a single-use ticket for opening a stream with ?ticket=.
+func (h *StreamHandler) CreateTicket(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		response.Unauthorized(c, nil)
+		return
+	}
+
+	ticket, err := h.streamService.IssueTicket(context.Background(), userCtx.ID)
+	if err != nil {
+		response.InternalServerError(c)
+		return
+	}
+
+	response.Created(c, ticket)
+}
+
+// Stream sends the caller's notifications and the posts of the users they follow
+// as server-sent events. A client reconnecting with Last-Event-ID, or
+// ?lastEventId= for clients that cannot set headers, first gets what it missed.
+func (h *StreamHandler) Stream(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		response.Unauthorized(c, nil)
+		return
+	}
+
+	lastEventID, _ := strconv.ParseInt(c.GetHeader("Last-Event-ID"), 10, 64)
+	if lastEventID == 0 {
+		lastEventID, _ = strconv.ParseInt(c.Query("lastEventId"), 10, 64)
+	}
+
+	// subscribe before looking up missed events so none fall in between
+	subscriber, err := h.streamService.Subscribe(userCtx.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, constants.ErrTooManyStreams):
+			response.TooManyRequests(c, err)
+		default:
+			response.InternalServerError(c)
+		}
+		return
+	}
+	defer h.streamService.Unsubscribe(subscriber)
+
+	var missed []*models.StreamEvent
+	if lastEventID > 0 {
+		missed, err = h.streamService.GetMissed(context.Background(), userCtx.ID, lastEventID)
+		if err != nil {
+			response.InternalServerError(c)
+			return
+		}
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	fmt.Fprintf(c.Writer, "retry: %d\n\n", streamRetry.Milliseconds())
+
+	replayed := make(map[int64]bool, len(missed))
+	for _, event := range missed {
+		writeStreamEvent(c, event)
+		replayed[event.ID] = true
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(h.heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case event, ok := <-subscriber.Events:
+			// dropped for falling behind, or shutting down
+			if !ok {
+				return
+			}
+			if replayed[event.ID] {
+				continue
+			}
+			writeStreamEvent(c, event)
+			c.Writer.Flush()
+		case <-heartbeat.C:
+			fmt.Fprint(c.Writer, ": heartbeat\n\n")
+			c.Writer.Flush()
+		}
+	}
+}
+
+func writeStreamEvent(c *gin.Context, event *models.StreamEvent) {
+	fmt.Fprintf(c.Writer, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, event.Data)
+}
@@ -5,9 +5,12 @@ import (
 	"database/sql"
 	"errors"
 	"github.com/gin-gonic/gin"
+	"github.com/wanafiq/feed-api/internal/constants"
 	"github.com/wanafiq/feed-api/internal/middleware"
 	"github.com/wanafiq/feed-api/internal/response"
 	"github.com/wanafiq/feed-api/internal/services"
+	"github.com/wanafiq/feed-api/internal/types"
+	"github.com/wanafiq/feed-api/internal/utils"
 	"go.uber.org/zap"
 )
 
@@ -45,13 +48,19 @@ func (h *UserHandler) GetByID(c *gin.Context) {
 }
 
 func (h *UserHandler) Deactivate(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		response.Unauthorized(c, nil)
+		return
+	}
+
 	userID := c.Param("userID")
 	if userID == "" {
 		response.BadRequest(c, errors.New("userID is required"))
 		return
 	}
 
-	user, err := h.userService.Deactivate(c, userID)
+	user, err := h.userService.Deactivate(context.Background(), userCtx, userID)
 	if err != nil {
 		switch {
 		case errors.Is(err, sql.ErrNoRows):
@@ -80,13 +89,13 @@ func (h *UserHandler) Follow(c *gin.Context) {
 		return
 	}
 
-	err := h.userService.Follow(context.Background(), followerID, followeeID)
+	status, err := h.userService.Follow(context.Background(), followerID, followeeID)
 	if err != nil {
-		response.InternalServerError(c)
+		h.handleRelationshipError(c, err)
 		return
 	}
 
-	response.NoContent(c)
+	response.OK(c, types.FollowResponse{Status: status}, nil)
 }
 
 func (h *UserHandler) Unfollow(c *gin.Context) {
@@ -112,3 +121,163 @@ func (h *UserHandler) Unfollow(c *gin.Context) {
 
 	response.NoContent(c)
 }
+
+func (h *UserHandler) Block(c *gin.Context) {
+	h.updateRelationship(c, h.userService.Block)
+}
+
+func (h *UserHandler) Unblock(c *gin.Context) {
+	h.updateRelationship(c, h.userService.Unblock)
+}
+
+func (h *UserHandler) Mute(c *gin.Context) {
+	h.updateRelationship(c, h.userService.Mute)
+}
+
+func (h *UserHandler) Unmute(c *gin.Context) {
+	h.updateRelationship(c, h.userService.Unmute)
+}
+
+func (h *UserHandler) GetBlocked(c *gin.Context) {
+	listForCurrentUser(c, h.userService.GetBlocked)
+}
+
+func (h *UserHandler) GetMuted(c *gin.Context) {
+	listForCurrentUser(c, h.userService.GetMuted)
+}
+
+func (h *UserHandler) UpdatePrivacy(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		response.Unauthorized(c, nil)
+		return
+	}
+
+	var req types.PrivacyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+
+	user, err := h.userService.UpdatePrivacy(context.Background(), userCtx.ID, *req.IsPrivate)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			response.NotFound(c, nil)
+		default:
+			response.InternalServerError(c)
+		}
+		return
+	}
+
+	response.OK(c, user, nil)
+}
+
+func (h *UserHandler) UpdateLocale(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		response.Unauthorized(c, nil)
+		return
+	}
+
+	var req types.LocaleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+
+	user, err := h.userService.UpdateLocale(context.Background(), userCtx.ID, req.Locale)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			response.NotFound(c, nil)
+		default:
+			response.InternalServerError(c)
+		}
+		return
+	}
+
+	response.OK(c, user, nil)
+}
+
+func (h *UserHandler) GetIncomingFollowRequests(c *gin.Context) {
+	listForCurrentUser(c, h.userService.GetIncomingFollowRequests)
+}
+
+func (h *UserHandler) GetOutgoingFollowRequests(c *gin.Context) {
+	listForCurrentUser(c, h.userService.GetOutgoingFollowRequests)
+}
+
+func (h *UserHandler) ApproveFollowRequest(c *gin.Context) {
+	h.updateRelationship(c, h.userService.ApproveFollowRequest)
+}
+
+func (h *UserHandler) RejectFollowRequest(c *gin.Context) {
+	h.updateRelationship(c, h.userService.RejectFollowRequest)
+}
+
+func (h *UserHandler) updateRelationship(c *gin.Context, fn func(ctx context.Context, userID string, targetID string) error) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		response.Unauthorized(c, nil)
+		return
+	}
+
+	targetID := c.Param("userID")
+	if targetID == "" {
+		response.BadRequest(c, errors.New("userID is required"))
+		return
+	}
+
+	if err := fn(context.Background(), userCtx.ID, targetID); err != nil {
+		h.handleRelationshipError(c, err)
+		return
+	}
+
+	response.NoContent(c)
+}
+
+// listForCurrentUser serves a paginated list owned by the authenticated user.
+func listForCurrentUser[T any](c *gin.Context, fn func(ctx context.Context, userID string, limit int, offset int) ([]T, int, error)) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		response.Unauthorized(c, nil)
+		return
+	}
+
+	offset := utils.ParseQueryInt(c, "offset", 0)
+
+	limit := utils.ParseQueryInt(c, "limit", 10)
+	if limit > 100 {
+		limit = 100
+	}
+
+	items, count, err := fn(context.Background(), userCtx.ID, limit, offset)
+	if err != nil {
+		response.InternalServerError(c)
+		return
+	}
+
+	pagination := response.Pagination{
+		Total:  count,
+		Limit:  limit,
+		Offset: offset,
+		Next:   utils.Min(offset+limit, count),
+		Prev:   utils.Max(offset-limit, 0),
+	}
+
+	response.OK(c, items, &pagination)
+}
+
+func (h *UserHandler) handleRelationshipError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		response.NotFound(c, nil)
+	case errors.Is(err, constants.ErrSelfAction):
+		response.BadRequest(c, err)
+	case errors.Is(err, constants.ErrBlocked):
+		response.Forbidden(c, err)
+	default:
+		response.InternalServerError(c)
+	}
+}
@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/wanafiq/feed-api/internal/constants"
+	"github.com/wanafiq/feed-api/internal/middleware"
+	"github.com/wanafiq/feed-api/internal/response"
+	"github.com/wanafiq/feed-api/internal/services"
+	"github.com/wanafiq/feed-api/internal/types"
+	"github.com/wanafiq/feed-api/internal/utils"
+	"go.uber.org/zap"
+	"strconv"
+)
+
+type WebhookHandler struct {
+	logger         *zap.SugaredLogger
+	webhookService *services.WebhookService
+}
+
+func NewWebhookHandler(logger *zap.SugaredLogger, webhookService *services.WebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		logger:         logger,
+		webhookService: webhookService,
+	}
+}
+
+// Create adds a webhook. Its signing secret is only returned here.
+func (h *WebhookHandler) Create(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		response.Unauthorized(c, nil)
+		return
+	}
+
+	var req types.WebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+
+	webhook, err := h.webhookService.Create(context.Background(), userCtx, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, constants.ErrInvalidWebhookURL):
+			response.BadRequest(c, err)
+		default:
+			response.InternalServerError(c)
+		}
+		return
+	}
+
+	response.Created(c, webhook)
+}
+
+func (h *WebhookHandler) GetAll(c *gin.Context) {
+	offset := utils.ParseQueryInt(c, "offset", 0)
+
+	limit := utils.ParseQueryInt(c, "limit", 10)
+	if limit > 100 {
+		limit = 100
+	}
+
+	webhooks, count, err := h.webhookService.GetAll(context.Background(), offset, limit)
+	if err != nil {
+		response.InternalServerError(c)
+		return
+	}
+
+	pagination := response.Pagination{
+		Total:  count,
+		Limit:  limit,
+		Offset: offset,
+		Next:   utils.Min(offset+limit, count),
+		Prev:   utils.Max(offset-limit, 0),
+	}
+
+	response.OK(c, webhooks, &pagination)
+}
+
+func (h *WebhookHandler) GetByID(c *gin.Context) {
+	webhook, err := h.webhookService.GetByID(context.Background(), c.Param("webhookID"))
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			response.NotFound(c, nil)
+		default:
+			response.InternalServerError(c)
+		}
+		return
+	}
+
+	response.OK(c, webhook, nil)
+}
+
+// Update replaces the settings of a webhook. Turning it back on after it was
+// disabled for failing resumes its pending deliveries.
+func (h *WebhookHandler) Update(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		response.Unauthorized(c, nil)
+		return
+	}
+
+	var req types.WebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+
+	webhook, err := h.webhookService.Update(context.Background(), userCtx, c.Param("webhookID"), &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, constants.ErrInvalidWebhookURL):
+			response.BadRequest(c, err)
+		case errors.Is(err, sql.ErrNoRows):
+			response.NotFound(c, nil)
+		default:
+			response.InternalServerError(c)
+		}
+		return
+	}
+
+	response.OK(c, webhook, nil)
+}
+
+func (h *WebhookHandler) Delete(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		response.Unauthorized(c, nil)
+		return
+	}
+
+	if err := h.webhookService.Delete(context.Background(), userCtx, c.Param("webhookID")); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			response.NotFound(c, nil)
+		default:
+			response.InternalServerError(c)
+		}
+		return
+	}
+
+	response.NoContent(c)
+}
+
+// GetDeliveries lists the delivery log of a webhook, e.g. ?status=failed.
+func (h *WebhookHandler) GetDeliveries(c *gin.Context) {
+	status := c.Query("status")
+	switch status {
+	case "", constants.WebhookDeliveryPending, constants.WebhookDeliverySucceeded, constants.WebhookDeliveryFailed:
+	default:
+		response.BadRequest(c, errors.New("invalid status"))
+		return
+	}
+
+	offset := utils.ParseQueryInt(c, "offset", 0)
+
+	limit := utils.ParseQueryInt(c, "limit", 10)
+	if limit > 100 {
+		limit = 100
+	}
+
+	deliveries, count, err := h.webhookService.GetDeliveries(context.Background(), c.Param("webhookID"), status, offset, limit)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			response.NotFound(c, nil)
+		default:
+			response.InternalServerError(c)
+		}
+		return
+	}
+
+	pagination := response.Pagination{
+		Total:  count,
+		Limit:  limit,
+		Offset: offset,
+		Next:   utils.Min(offset+limit, count),
+		Prev:   utils.Max(offset-limit, 0),
+	}
+
+	response.OK(c, deliveries, &pagination)
+}
+
+// Redeliver sends the event of a delivery again, as a new delivery.
+func (h *WebhookHandler) Redeliver(c *gin.Context) {
+	deliveryID, err := strconv.ParseInt(c.Param("deliveryID"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, errors.New("invalid deliveryID"))
+		return
+	}
+
+	delivery, err := h.webhookService.Redeliver(context.Background(), c.Param("webhookID"), deliveryID)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			response.NotFound(c, nil)
+		case errors.Is(err, constants.ErrDeliveryPending):
+			response.Conflict(c, err)
+		default:
+			response.InternalServerError(c)
+		}
+		return
+	}
+
+	response.Created(c, delivery)
+}
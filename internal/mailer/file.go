@@ -0,0 +1,47 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// FileMailer writes every message as an .eml file into the new/ folder of a
+// maildir, where mail clients and `cat` alike can read it.
+type FileMailer struct {
+	dir string
+}
+
+func NewFileMailer(dir string) (*FileMailer, error) {
+	for _, sub := range []string{"tmp", "new", "cur"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o755); err != nil {
+			return nil, err
+		}
+	}
+
+	return &FileMailer{dir: dir}, nil
+}
+
+func (m *FileMailer) Send(ctx context.Context, msg *Message) error {
+	name := fmt.Sprintf("%d.%s.eml", msg.SentAt.UnixNano(), msg.ID)
+
+	// maildir delivery: write to tmp/, then move into new/ in one step
+	tmpPath := filepath.Join(m.dir, "tmp", name)
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+
+	if _, err := toMIME(msg).WriteTo(file); err != nil {
+		file.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	return os.Rename(tmpPath, filepath.Join(m.dir, "new", name))
+}
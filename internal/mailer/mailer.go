@@ -0,0 +1,56 @@
+package mailer
+
+import (
+	"context"
+	gomail "gopkg.in/mail.v2"
+	"time"
+)
+
+// Transports selectable with MAIL_TRANSPORT.
+const (
+	TransportSMTP   = "smtp"
+	TransportFile   = "file"
+	TransportMemory = "memory"
+)
+
+// Message is an email ready to be delivered. Text is the plain text alternative
+// of HTML and may be empty.
+type Message struct {
+	ID      string    `json:"id"`
+	From    string    `json:"from"`
+	To      string    `json:"to"`
+	Subject string    `json:"subject"`
+	Text    string    `json:"text,omitempty"`
+	HTML    string    `json:"html"`
+	SentAt  time.Time `json:"sentAt"`
+
+	Headers map[string]string `json:"headers,omitempty"` // extra headers, e.g. List-Unsubscribe
+}
+
+// Mailer delivers messages.
+type Mailer interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// toMIME builds the wire format of msg, with the plain text part first so
+// clients prefer the HTML one.
+func toMIME(msg *Message) *gomail.Message {
+	m := gomail.NewMessage()
+	m.SetHeader("From", msg.From)
+	m.SetHeader("To", msg.To)
+	m.SetHeader("Subject", msg.Subject)
+	m.SetHeader("Message-ID", "<"+msg.ID+"@feed>")
+	m.SetDateHeader("Date", msg.SentAt)
+	for name, value := range msg.Headers {
+		m.SetHeader(name, value)
+	}
+
+	if msg.Text != "" {
+		m.SetBody("text/plain", msg.Text)
+		m.AddAlternative("text/html", msg.HTML)
+	} else {
+		m.SetBody("text/html", msg.HTML)
+	}
+
+	return m
+}
@@ -0,0 +1,63 @@
+package mailer
+
+import (
+	"context"
+	"slices"
+	"sync"
+)
+
+// MemoryMailer keeps the most recent messages in memory instead of delivering them.
+type MemoryMailer struct {
+	mu       sync.Mutex
+	capacity int
+	messages []*Message // oldest first
+}
+
+func NewMemoryMailer(capacity int) *MemoryMailer {
+	return &MemoryMailer{capacity: capacity}
+}
+
+func (m *MemoryMailer) Send(ctx context.Context, msg *Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.messages = append(m.messages, msg)
+	if len(m.messages) > m.capacity {
+		m.messages = slices.Delete(m.messages, 0, len(m.messages)-m.capacity)
+	}
+
+	return nil
+}
+
+// Messages returns the captured messages, newest first.
+func (m *MemoryMailer) Messages() []*Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	messages := slices.Clone(m.messages)
+	slices.Reverse(messages)
+
+	return messages
+}
+
+// Message returns a captured message by id.
+func (m *MemoryMailer) Message(id string) (*Message, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, msg := range m.messages {
+		if msg.ID == id {
+			return msg, true
+		}
+	}
+
+	return nil, false
+}
+
+// Reset drops every captured message.
+func (m *MemoryMailer) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.messages = nil
+}
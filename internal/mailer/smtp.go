@@ -0,0 +1,18 @@
+package mailer
+
+import (
+	"context"
+	gomail "gopkg.in/mail.v2"
+)
+
+type SMTPMailer struct {
+	dialer *gomail.Dialer
+}
+
+func NewSMTPMailer(host string, port int, username string, password string) *SMTPMailer {
+	return &SMTPMailer{dialer: gomail.NewDialer(host, port, username, password)}
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, msg *Message) error {
+	return m.dialer.DialAndSend(toMIME(msg))
+}
@@ -0,0 +1,72 @@
+package markdown
+
+import (
+	"bytes"
+	"errors"
+	"gopkg.in/yaml.v3"
+	"time"
+)
+
+var ErrNoFrontMatter = errors.New("document has no front matter")
+
+const frontMatterDelimiter = "---"
+
+// FrontMatter is the YAML header of an exported post.
+type FrontMatter struct {
+	Title       string     `yaml:"title"`
+	Slug        string     `yaml:"slug,omitempty"`
+	Tags        []string   `yaml:"tags,omitempty"`
+	Visibility  string     `yaml:"visibility,omitempty"`
+	Published   bool       `yaml:"published"`
+	CreatedAt   *time.Time `yaml:"created_at,omitempty"`
+	PublishedAt *time.Time `yaml:"published_at,omitempty"`
+	UpdatedAt   *time.Time `yaml:"updated_at,omitempty"`
+}
+
+// WriteDocument returns body preceded by its front matter between --- lines.
+func WriteDocument(fm FrontMatter, body string) ([]byte, error) {
+	header, err := yaml.Marshal(fm)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(frontMatterDelimiter + "\n")
+	buf.Write(header)
+	buf.WriteString(frontMatterDelimiter + "\n\n")
+	buf.WriteString(body)
+	if body != "" && body[len(body)-1] != '\n' {
+		buf.WriteByte('\n')
+	}
+
+	return buf.Bytes(), nil
+}
+
+// ParseDocument splits a document written by WriteDocument, or by any tool using
+// the same convention, into its front matter and markdown body.
+func ParseDocument(data []byte) (FrontMatter, string, error) {
+	var fm FrontMatter
+
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	data = bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+
+	rest, ok := bytes.CutPrefix(data, []byte(frontMatterDelimiter+"\n"))
+	if !ok {
+		return fm, "", ErrNoFrontMatter
+	}
+
+	header, body, ok := bytes.Cut(rest, []byte("\n"+frontMatterDelimiter+"\n"))
+	if !ok {
+		// the closing delimiter may end the file
+		header, ok = bytes.CutSuffix(rest, []byte("\n"+frontMatterDelimiter))
+		if !ok {
+			return fm, "", ErrNoFrontMatter
+		}
+	}
+
+	if err := yaml.Unmarshal(header, &fm); err != nil {
+		return fm, "", err
+	}
+
+	return fm, string(bytes.TrimLeft(body, "\n")), nil
+}
@@ -0,0 +1,137 @@
+package markdown
+
+import (
+	"bytes"
+	"container/list"
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"regexp"
+	"sync"
+)
+
+// Renderer converts markdown to sanitized HTML and keeps the most recently
+// rendered documents in memory.
+type Renderer struct {
+	md     goldmark.Markdown
+	policy *bluemonday.Policy
+
+	mu       sync.Mutex
+	capacity int
+	entries  map[string]*list.Element
+	order    *list.List // most recently used first
+}
+
+type cacheEntry struct {
+	key  string
+	html string
+}
+
+func NewRenderer(cacheSize int) *Renderer {
+	md := goldmark.New(
+		goldmark.WithExtensions(
+			// GFM, with table alignment rendered as attributes so no inline style is needed
+			extension.NewTable(extension.WithTableCellAlignMethod(extension.TableCellAlignAttribute)),
+			extension.Strikethrough,
+			extension.Linkify,
+			extension.TaskList,
+		),
+		goldmark.WithParserOptions(parser.WithAutoHeadingID()),
+	)
+
+	return &Renderer{
+		md:       md,
+		policy:   newPolicy(),
+		capacity: cacheSize,
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// Render returns the sanitized HTML of source. key must change whenever source
+// changes, e.g. the post id and its version.
+func (r *Renderer) Render(key string, source string) (string, error) {
+	if html, ok := r.get(key); ok {
+		return html, nil
+	}
+
+	var buf bytes.Buffer
+	if err := r.md.Convert([]byte(source), &buf); err != nil {
+		return "", err
+	}
+
+	html := r.policy.Sanitize(buf.String())
+	r.put(key, html)
+
+	return html, nil
+}
+
+func (r *Renderer) get(key string) (string, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	elem, ok := r.entries[key]
+	if !ok {
+		return "", false
+	}
+	r.order.MoveToFront(elem)
+
+	return elem.Value.(*cacheEntry).html, true
+}
+
+func (r *Renderer) put(key string, html string) {
+	if r.capacity <= 0 {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if elem, ok := r.entries[key]; ok {
+		r.order.MoveToFront(elem)
+		return
+	}
+
+	r.entries[key] = r.order.PushFront(&cacheEntry{key: key, html: html})
+
+	for r.order.Len() > r.capacity {
+		oldest := r.order.Back()
+		r.order.Remove(oldest)
+		delete(r.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// newPolicy allows only the elements and attributes the markdown renderer produces.
+func newPolicy() *bluemonday.Policy {
+	p := bluemonday.NewPolicy()
+
+	p.AllowElements(
+		"p", "br", "hr", "blockquote", "pre",
+		"em", "strong", "del", "code",
+		"ul", "ol", "li",
+		"table", "thead", "tbody", "tr",
+	)
+
+	p.AllowAttrs("id").Matching(regexp.MustCompile(`^[\w-]+$`)).OnElements("h1", "h2", "h3", "h4", "h5", "h6")
+	p.AllowElements("h1", "h2", "h3", "h4", "h5", "h6")
+
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^language-[\w+#-]+$`)).OnElements("code")
+	p.AllowAttrs("start").Matching(bluemonday.Integer).OnElements("ol")
+	p.AllowAttrs("align").Matching(regexp.MustCompile(`^(left|center|right)$`)).OnElements("th", "td")
+	p.AllowElements("th", "td")
+
+	// task list items
+	p.AllowAttrs("type").Matching(regexp.MustCompile(`^checkbox$`)).OnElements("input")
+	p.AllowAttrs("checked", "disabled").Matching(regexp.MustCompile(`^$`)).OnElements("input")
+
+	p.AllowStandardURLs()
+	p.AllowURLSchemes("http", "https", "mailto")
+	p.AllowAttrs("href").OnElements("a")
+	p.AllowAttrs("title").OnElements("a", "img")
+	p.AllowAttrs("src", "alt").OnElements("img")
+	p.RequireNoFollowOnLinks(true)
+	p.AddTargetBlankToFullyQualifiedLinks(true)
+
+	return p
+}
@@ -0,0 +1,114 @@
+package markdown
+
+import (
+	"bytes"
+	"github.com/wanafiq/feed-api/internal/models"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	excerptLength  = 200
+	wordsPerMinute = 200
+)
+
+// Outline summarizes a markdown document.
+type Outline struct {
+	Excerpt     string
+	WordCount   int
+	ReadingTime int // minutes
+	TOC         models.TOC
+}
+
+// Outline extracts the excerpt, word count, reading time and headings of source.
+// Code blocks are left out of the excerpt and the word count. Heading ids
+// match the anchors of the rendered HTML.
+func (r *Renderer) Outline(source string) Outline {
+	src := []byte(source)
+	doc := r.md.Parser().Parse(text.NewReader(src))
+
+	var outline Outline
+	var paragraphs []string
+
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+
+		switch node := n.(type) {
+		case *ast.FencedCodeBlock, *ast.CodeBlock, *ast.HTMLBlock:
+			return ast.WalkSkipChildren, nil
+		case *ast.Heading:
+			headingText := plainText(node, src)
+			outline.WordCount += len(strings.Fields(headingText))
+			id, _ := node.AttributeString("id")
+			idBytes, _ := id.([]byte)
+			outline.TOC = append(outline.TOC, models.TOCEntry{
+				Level: node.Level,
+				Text:  headingText,
+				ID:    string(idBytes),
+			})
+			return ast.WalkSkipChildren, nil
+		case *ast.Paragraph, *ast.TextBlock:
+			paragraphText := plainText(node, src)
+			outline.WordCount += len(strings.Fields(paragraphText))
+			paragraphs = append(paragraphs, paragraphText)
+			return ast.WalkSkipChildren, nil
+		}
+
+		return ast.WalkContinue, nil
+	})
+
+	outline.Excerpt = truncate(strings.Join(paragraphs, " "), excerptLength)
+	if outline.WordCount > 0 {
+		outline.ReadingTime = (outline.WordCount + wordsPerMinute - 1) / wordsPerMinute
+	}
+
+	return outline
+}
+
+// plainText concatenates the text of the inline children of n, without markup.
+func plainText(n ast.Node, source []byte) string {
+	var buf bytes.Buffer
+
+	_ = ast.Walk(n, func(child ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+
+		switch node := child.(type) {
+		case *ast.Image, *ast.RawHTML:
+			return ast.WalkSkipChildren, nil
+		case *ast.Text:
+			buf.Write(node.Value(source))
+			if node.SoftLineBreak() || node.HardLineBreak() {
+				buf.WriteByte(' ')
+			}
+		case *ast.String:
+			buf.Write(node.Value)
+		case *ast.AutoLink:
+			buf.Write(node.Label(source))
+		}
+
+		return ast.WalkContinue, nil
+	})
+
+	return strings.Join(strings.Fields(buf.String()), " ")
+}
+
+// truncate shortens s to at most limit characters, cutting at a word boundary.
+func truncate(s string, limit int) string {
+	if utf8.RuneCountInString(s) <= limit {
+		return s
+	}
+
+	runes := []rune(s)[:limit]
+	cut := string(runes)
+	if i := strings.LastIndex(cut, " "); i > 0 {
+		cut = cut[:i]
+	}
+
+	return strings.TrimRight(cut, " ,.;:") + "…"
+}
@@ -1,8 +1,13 @@
 package middleware
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"github.com/gin-gonic/gin"
 	"github.com/wanafiq/feed-api/internal/config"
+	"github.com/wanafiq/feed-api/internal/constants"
+	"github.com/wanafiq/feed-api/internal/repository"
 	"github.com/wanafiq/feed-api/internal/utils"
 	"go.uber.org/zap"
 	"net/http"
@@ -15,23 +20,35 @@ const (
 )
 
 type Middleware struct {
-	config *config.Config
-	logger *zap.SugaredLogger
+	config    *config.Config
+	logger    *zap.SugaredLogger
+	tokenRepo repository.TokenRepository
 }
 
-func NewMiddleware(config *config.Config, logger *zap.SugaredLogger) *Middleware {
+func NewMiddleware(config *config.Config, logger *zap.SugaredLogger, tokenRepo repository.TokenRepository) *Middleware {
 	return &Middleware{
-		config: config,
-		logger: logger,
+		config:    config,
+		logger:    logger,
+		tokenRepo: tokenRepo,
 	}
 }
 
 type UserContext struct {
-	ID       string
-	Username string
-	Email    string
-	IsActive bool
-	Role     string
+	ID        string
+	Username  string
+	Email     string
+	IsActive  bool
+	Role      string
+	IP        string
+	UserAgent string
+}
+
+// ClientContext is the user context of an anonymous request, e.g. a login.
+func ClientContext(c *gin.Context) UserContext {
+	return UserContext{
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
 }
 
 func (m *Middleware) RequireAuth() gin.HandlerFunc {
@@ -45,11 +62,74 @@ func (m *Middleware) RequireAuth() gin.HandlerFunc {
 			return
 		}
 
-		userCtx := UserContext{
-			ID:   claims.Subject,
-			Role: claims.Role,
+		userCtx := ClientContext(c)
+		userCtx.ID = claims.Subject
+		userCtx.Username = claims.Username
+		userCtx.Email = claims.Email
+		userCtx.Role = claims.Role
+
+		c.Set(UserContextKey, userCtx)
+
+		c.Next()
+	}
+}
+
+// OptionalAuth sets the user context when a bearer token is present, and lets
+// anonymous requests through untouched.
+func (m *Middleware) OptionalAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authHeader := c.GetHeader(authHeaderKey)
+		if authHeader == "" {
+			c.Next()
+			return
 		}
 
+		claims, err := utils.ParseAndValidateJWT(authHeader, m.config.Jwt.Secret)
+		if err != nil {
+			m.logger.Errorw("failed to parse JWT", "error", err, "authHeader", authHeader)
+			m.abortWithJSON(c, http.StatusUnauthorized, err.Error())
+			return
+		}
+
+		userCtx := ClientContext(c)
+		userCtx.ID = claims.Subject
+		userCtx.Username = claims.Username
+		userCtx.Email = claims.Email
+		userCtx.Role = claims.Role
+
+		c.Set(UserContextKey, userCtx)
+
+		c.Next()
+	}
+}
+
+// StreamAuth is RequireAuth that also accepts a stream ticket in the ticket
+// query parameter, as browsers cannot set headers on an EventSource. Tickets are
+// single-use and short-lived, unlike the JWT they stand in for.
+func (m *Middleware) StreamAuth() gin.HandlerFunc {
+	requireAuth := m.RequireAuth()
+
+	return func(c *gin.Context) {
+		ticket := c.Query("ticket")
+		if c.GetHeader(authHeaderKey) != "" || ticket == "" {
+			requireAuth(c)
+			return
+		}
+
+		userID, err := m.tokenRepo.Consume(context.Background(), constants.StreamTicket, utils.HashToken(ticket))
+		if err != nil {
+			if !errors.Is(err, sql.ErrNoRows) {
+				m.logger.Errorw("failed to consume stream ticket", "error", err.Error())
+				m.abortWithJSON(c, http.StatusInternalServerError, "internal server error")
+				return
+			}
+			m.abortWithJSON(c, http.StatusUnauthorized, "invalid or expired ticket")
+			return
+		}
+
+		userCtx := ClientContext(c)
+		userCtx.ID = userID
+
 		c.Set(UserContextKey, userCtx)
 
 		c.Next()
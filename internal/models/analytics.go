@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// PostView is a single de-duplicated view waiting to be flushed.
+type PostView struct {
+	PostID    string
+	ViewerKey string
+	ViewedAt  time.Time
+}
+
+type DailyViews struct {
+	Day           time.Time `json:"day"`
+	Views         int       `json:"views"`
+	UniqueViewers int       `json:"uniqueViewers"`
+}
+
+type PostStats struct {
+	PostID        string         `json:"postId"`
+	From          time.Time      `json:"from"`
+	To            time.Time      `json:"to"`
+	Views         int            `json:"views"`
+	UniqueViewers int            `json:"uniqueViewers"`
+	Reactions     map[string]int `json:"reactions"`
+	Daily         []DailyViews   `json:"daily"`
+}
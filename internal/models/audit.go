@@ -0,0 +1,32 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AuditLog records who did what to which resource, with the resource as it was
+// before and after when it changed.
+type AuditLog struct {
+	ID         int64           `json:"id"`
+	ActorID    *string         `json:"actorId,omitempty"`
+	Action     string          `json:"action"`
+	TargetType string          `json:"targetType"`
+	TargetID   string          `json:"targetId"`
+	Before     json.RawMessage `json:"before,omitempty"`
+	After      json.RawMessage `json:"after,omitempty"`
+	IP         string          `json:"ip"`
+	UserAgent  string          `json:"userAgent"`
+	CreatedAt  time.Time       `json:"createdAt"`
+}
+
+type AuditLogFilter struct {
+	ActorID    string
+	Action     string
+	TargetType string
+	TargetID   string
+	Since      *time.Time
+	Until      *time.Time
+	Offset     int
+	Limit      int
+}
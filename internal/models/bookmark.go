@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+type Bookmark struct {
+	ID           string    `db:"id" json:"id,omitempty"`
+	UserID       string    `db:"user_id" json:"userId,omitempty"`
+	PostID       string    `db:"post_id" json:"postId,omitempty"`
+	CollectionID *string   `db:"collection_id" json:"collectionId,omitempty"`
+	CreatedAt    time.Time `db:"created_at" json:"createdAt,omitempty"`
+
+	Post *Post `json:"post,omitempty"`
+}
+
+type BookmarkCollection struct {
+	ID        string    `db:"id" json:"id,omitempty"`
+	UserID    string    `db:"user_id" json:"userId,omitempty"`
+	Name      string    `db:"name" json:"name,omitempty"`
+	CreatedAt time.Time `db:"created_at" json:"createdAt,omitempty"`
+}
+
+type BookmarkFilter struct {
+	UserID       string
+	CollectionID string
+	Limit        int
+	CursorTime   *time.Time
+	CursorID     string
+}
@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+type PostCounts struct {
+	Drafts    int `json:"drafts"`
+	Published int `json:"published"`
+	Scheduled int `json:"scheduled"`
+}
+
+type DailyCount struct {
+	Day   time.Time `json:"day"`
+	Count int       `json:"count"`
+}
+
+type TopPost struct {
+	ID        string `json:"id"`
+	Title     string `json:"title"`
+	Slug      string `json:"slug"`
+	Views     int    `json:"views"`
+	Reactions int    `json:"reactions"`
+}
+
+type Activity struct {
+	Type      string    `json:"type"` // "follow" or "reaction"
+	Reaction  string    `json:"reaction,omitempty"`
+	ActorID   string    `json:"actorId"`
+	ActorName string    `json:"actorUsername"`
+	PostID    *string   `json:"postId,omitempty"`
+	PostTitle *string   `json:"postTitle,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type Dashboard struct {
+	From             time.Time    `json:"from"`
+	To               time.Time    `json:"to"`
+	Posts            PostCounts   `json:"posts"`
+	Followers        int          `json:"followers"`
+	FollowerGrowth   []DailyCount `json:"followerGrowth"`
+	TopByViews       []TopPost    `json:"topByViews"`
+	TopByReactions   []TopPost    `json:"topByReactions"`
+	RecentActivities []Activity   `json:"recentActivities"`
+}
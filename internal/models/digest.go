@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+type Digest struct {
+	ID                int64      `json:"id"`
+	UserID            string     `json:"userId"`
+	Frequency         string     `json:"frequency"`
+	PeriodStart       time.Time  `json:"periodStart"`
+	PeriodEnd         time.Time  `json:"periodEnd"`
+	PostCount         int        `json:"postCount"`
+	NotificationCount int        `json:"notificationCount"`
+	SentAt            *time.Time `json:"sentAt,omitempty"` // nil when there was nothing to send
+	CreatedAt         time.Time  `json:"createdAt"`
+}
+
+// DigestRecipient is a user whose next digest is due.
+type DigestRecipient struct {
+	User          User
+	Frequency     string
+	LastPeriodEnd *time.Time // end of the previous digest, nil before the first one
+}
+
+type DigestPost struct {
+	ID             string
+	Title          string
+	Excerpt        string
+	AuthorUsername string
+	PublishedAt    time.Time
+}
+
+type DigestSettings struct {
+	Frequency string `json:"frequency"`
+}
@@ -4,19 +4,24 @@
 package models
 
 import (
+	"encoding/json"
 	"time" // For auditing and event timestamps
-	// TODO: Add other necessary imports if needed
 )
 
-// Event represents an asynchronous event logged by the system.
+// Event represents an asynchronous event logged by the system. It is written in
+// the same transaction as the change it describes, once per handler, and
+// dispatched in the background until the handler succeeds or it runs out of attempts.
 type Event struct {
-	ID        int       `json:"id"`
-	EventType string    `json:"event_type"` // E.g., "email_confirmation", "password_reset"
-	Payload   string    `json:"payload"`    // JSON or string representation of the event data
-	Status    string    `json:"status"`     // E.g., "pending", "completed", "failed"
-	CreatedAt time.Time `json:"created_at"`
-	CreatedBy string    `json:"created_by"`
-	UpdatedAt time.Time `json:"updated_at"`
-	UpdatedBy string    `json:"updated_by"`
-	// TODO: Add additional fields if necessary
+	ID            int64           `json:"id"`
+	EventType     string          `json:"event_type"` // E.g., "user.registered"
+	Handler       string          `json:"handler"`    // E.g., "confirmation_email"
+	Payload       json.RawMessage `json:"payload"`
+	Status        string          `json:"status"` // "pending", "completed" or "dead"
+	Attempts      int             `json:"attempts"`
+	NextAttemptAt time.Time       `json:"next_attempt_at"`
+	LastError     *string         `json:"last_error,omitempty"`
+	CreatedAt     time.Time       `json:"created_at"`
+	CreatedBy     string          `json:"created_by"`
+	UpdatedAt     *time.Time      `json:"updated_at,omitempty"`
+	UpdatedBy     *string         `json:"updated_by,omitempty"`
 }
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+type FollowRequest struct {
+	RequesterID string    `db:"requester_id" json:"requesterId,omitempty"`
+	TargetID    string    `db:"target_id" json:"targetId,omitempty"`
+	CreatedAt   time.Time `db:"created_at" json:"createdAt,omitempty"`
+
+	Requester *User `json:"requester,omitempty"`
+	Target    *User `json:"target,omitempty"`
+}
@@ -0,0 +1,36 @@
+package models
+
+// ImportResult reports what an import did, or in dry-run mode would do, with each file.
+type ImportResult struct {
+	DryRun   bool                `json:"dryRun"`
+	Imported int                 `json:"imported"`
+	Failed   int                 `json:"failed"`
+	Files    []*ImportFileResult `json:"files"`
+}
+
+type ImportFileResult struct {
+	Name   string `json:"name"`
+	PostID string `json:"postId,omitempty"`
+	Title  string `json:"title,omitempty"`
+	Slug   string `json:"slug,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// WordPressImportResult reports, item by item, what an import of a WordPress export did.
+type WordPressImportResult struct {
+	Source          string                 `json:"source"`
+	Imported        int                    `json:"imported"`
+	Skipped         int                    `json:"skipped"`
+	Failed          int                    `json:"failed"`
+	UnmappedAuthors []string               `json:"unmappedAuthors,omitempty"`
+	Items           []*WordPressImportItem `json:"items"`
+}
+
+type WordPressImportItem struct {
+	SourceID string `json:"sourceId"`
+	Title    string `json:"title"`
+	Slug     string `json:"slug,omitempty"`
+	Status   string `json:"status"` // "imported", "skipped" (already imported) or "failed"
+	PostID   string `json:"postId,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
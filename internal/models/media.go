@@ -0,0 +1,68 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Media is an uploaded image. Posts whose content references it are linked to
+// it in post_media, and it is garbage-collected when no post has referenced it
+// for a while.
+type Media struct {
+	ID          string     `db:"id" json:"id,omitempty"`
+	UserID      string     `db:"user_id" json:"userId,omitempty"`
+	Key         string     `db:"storage_key" json:"key,omitempty"`
+	URL         string     `json:"url,omitempty"`
+	ContentType string     `db:"content_type" json:"contentType,omitempty"`
+	Size        int64      `db:"size" json:"size,omitempty"`
+	Width       int        `db:"width" json:"width,omitempty"`
+	Height      int        `db:"height" json:"height,omitempty"`
+	Thumbnails  Thumbnails `db:"thumbnails" json:"thumbnails,omitempty"`
+	OrphanedAt  *time.Time `db:"orphaned_at" json:"-"`
+	CreatedAt   time.Time  `db:"created_at" json:"createdAt,omitempty"`
+}
+
+type Thumbnail struct {
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	Size   int64  `json:"size,omitempty"`
+	Key    string `json:"key"`
+	URL    string `json:"url,omitempty"`
+}
+
+// TotalSize is the stored size of the upload and its thumbnails.
+func (m *Media) TotalSize() int64 {
+	total := m.Size
+	for _, thumbnail := range m.Thumbnails {
+		total += thumbnail.Size
+	}
+	return total
+}
+
+// Thumbnails are stored as JSONB.
+type Thumbnails []Thumbnail
+
+func (t Thumbnails) Value() (driver.Value, error) {
+	if t == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(t)
+}
+
+func (t *Thumbnails) Scan(src any) error {
+	var data []byte
+	switch v := src.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	case nil:
+		*t = nil
+		return nil
+	default:
+		return errors.New("unsupported type for Thumbnails")
+	}
+	return json.Unmarshal(data, t)
+}
@@ -0,0 +1,18 @@
+package models
+
+// MetaTag is a <meta> or <link> element of a page head.
+type MetaTag struct {
+	Tag      string `json:"tag"` // "meta" or "link"
+	Name     string `json:"name,omitempty"`
+	Property string `json:"property,omitempty"`
+	Content  string `json:"content,omitempty"`
+	Rel      string `json:"rel,omitempty"`
+	Href     string `json:"href,omitempty"`
+}
+
+// PostMeta holds the head metadata of a post page, also rendered as HTML.
+type PostMeta struct {
+	Title string    `json:"title"`
+	Tags  []MetaTag `json:"tags"`
+	HTML  string    `json:"html"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// Notification is a group of similar notifications, e.g. every unread reaction
+// of one kind to one post. ID is the newest notification of the group.
+type Notification struct {
+	ID         int64     `json:"id"`
+	Type       string    `json:"type"`
+	PostID     *string   `json:"postId,omitempty"`
+	PostTitle  *string   `json:"postTitle,omitempty"`
+	Reaction   string    `json:"reaction,omitempty"`
+	Actors     []User    `json:"actors"` // most recent first, at most three
+	ActorCount int       `json:"actorCount"`
+	Message    string    `json:"message"`
+	IsRead     bool      `json:"isRead"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+type NotificationList struct {
+	UnreadCount   int             `json:"unreadCount"`
+	Notifications []*Notification `json:"notifications"`
+}
+
+type NotificationFilter struct {
+	UnreadOnly bool
+	Since      *time.Time // created after
+	Until      *time.Time // created at or before
+	Offset     int
+	Limit      int
+}
@@ -9,6 +9,7 @@ type Post struct {
 	Title       string     `db:"title" json:"title,omitempty"`
 	Slug        string     `db:"slug" json:"slug,omitempty"`
 	Content     string     `db:"content" json:"content,omitempty"`
+	ContentHTML string     `json:"contentHtml,omitempty"`
 	IsPublished bool       `db:"is_published" json:"isPublished,omitempty"`
 	PublishedAt *time.Time `db:"published_at" json:"publishedAt,omitempty"`
 	CreatedAt   time.Time  `db:"created_at" json:"createdAt,omitempty"`
@@ -16,17 +17,39 @@ type Post struct {
 	UpdatedAt   *time.Time `db:"updated_at" json:"updatedAt,omitempty"`
 	UpdatedBy   *string    `db:"updated_by" json:"updatedBy,omitempty"`
 	AuthorID    string     `db:"author_id" json:"authorId,omitempty"`
+	Visibility  string     `db:"visibility" json:"visibility,omitempty"`
+	Excerpt     string     `db:"excerpt" json:"excerpt,omitempty"`
+	WordCount   int        `db:"word_count" json:"wordCount"`
+	ReadingTime int        `db:"reading_time" json:"readingTime"` // minutes
+	TOC         TOC        `db:"toc" json:"toc,omitempty"`
 
-	Tags   []Tag `json:"tags,omitempty"`
-	Author User  `json:"author,omitempty"`
+	CoverImage      string `db:"cover_image" json:"coverImage,omitempty"`
+	MetaDescription string `db:"meta_description" json:"metaDescription,omitempty"`
+	CanonicalURL    string `db:"canonical_url" json:"canonicalUrl,omitempty"`
+	OGTitle         string `db:"og_title" json:"ogTitle,omitempty"`
+	OGDescription   string `db:"og_description" json:"ogDescription,omitempty"`
+	OGImage         string `db:"og_image" json:"ogImage,omitempty"`
+	TwitterCard     string `db:"twitter_card" json:"twitterCard,omitempty"`
+
+	Tags        []Tag             `json:"tags,omitempty"`
+	Author      User              `json:"author,omitempty"`
+	Authors     []User            `json:"authors,omitempty"`
+	Reactions   map[string]int    `json:"reactions,omitempty"`
+	MyReactions []string          `json:"myReactions,omitempty"`
+	Bookmarked  bool              `json:"bookmarked,omitempty"`
+	Series      *SeriesNavigation `json:"series,omitempty"`
 }
 
 type PostFilter struct {
-	Offset   int        `json:"offset,omitempty"`
-	Limit    int        `json:"limit,omitempty"`
-	Search   string     `json:"search,omitempty"`
-	Sort     string     `json:"sort,omitempty"` // "asc" or "desc"
-	DateFrom *time.Time `json:"date_from,omitempty"`
-	DateTo   *time.Time `json:"date_to,omitempty"`
-	Tags     []string   `json:"tags,omitempty"`
+	Offset    int        `json:"offset,omitempty"`
+	Limit     int        `json:"limit,omitempty"`
+	Search    string     `json:"search,omitempty"`
+	Sort      string     `json:"sort,omitempty"` // "asc" or "desc"
+	DateFrom  *time.Time `json:"date_from,omitempty"`
+	DateTo    *time.Time `json:"date_to,omitempty"`
+	Tags      []string   `json:"tags,omitempty"`
+	AuthorID  string     `json:"author_id,omitempty"` // author or accepted co-author
+	Content   bool       `json:"content,omitempty"`   // include the full content instead of only the excerpt
+	Published bool       `json:"published,omitempty"` // only published posts, newest publication first
+	ViewerID  string     `json:"-"`                   // authenticated caller, empty for anonymous requests
 }
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// PostUser links a user to a post as its author or as a co-author.
+type PostUser struct {
+	PostID    string    `db:"post_id" json:"postId,omitempty"`
+	UserID    string    `db:"user_id" json:"userId,omitempty"`
+	Role      string    `db:"role" json:"role,omitempty"`
+	Status    string    `db:"status" json:"status,omitempty"`
+	CreatedAt time.Time `db:"created_at" json:"createdAt,omitempty"`
+
+	Post *Post `json:"post,omitempty"`
+}
@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// Series groups posts of the same author in an explicit reading order.
+type Series struct {
+	ID          string    `db:"id" json:"id,omitempty"`
+	AuthorID    string    `db:"author_id" json:"authorId,omitempty"`
+	Title       string    `db:"title" json:"title,omitempty"`
+	Slug        string    `db:"slug" json:"slug,omitempty"`
+	Description *string   `db:"description" json:"description,omitempty"`
+	CreatedAt   time.Time `db:"created_at" json:"createdAt,omitempty"`
+
+	Author User    `json:"author,omitempty"`
+	Posts  []*Post `json:"posts,omitempty"`
+}
+
+// SeriesNavigation places a post within its series.
+type SeriesNavigation struct {
+	ID       string    `json:"id"`
+	Title    string    `json:"title"`
+	Slug     string    `json:"slug"`
+	Position int       `json:"position"`
+	Total    int       `json:"total"`
+	Previous *PostLink `json:"previous,omitempty"`
+	Next     *PostLink `json:"next,omitempty"`
+}
+
+type PostLink struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+	Slug  string `json:"slug"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// SitemapEntry is a page to list in a sitemap, identified by the post id, tag
+// name or user id it is built from.
+type SitemapEntry struct {
+	Key     string
+	LastMod time.Time
+}
+
+type SitemapCounts struct {
+	Posts int
+	Tags  int
+	Users int
+}
@@ -0,0 +1,16 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// StreamEvent is pushed to connected clients over server-sent events.
+type StreamEvent struct {
+	ID        int64           `json:"id"`
+	Type      string          `json:"type"`
+	UserID    *string         `json:"userId,omitempty"`   // recipient of a notification
+	AuthorID  *string         `json:"authorId,omitempty"` // author of a post, sent to their followers
+	Data      json.RawMessage `json:"data"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+)
+
+// TOCEntry is a heading of a post, linked through the anchor id of the rendered heading.
+type TOCEntry struct {
+	Level int    `json:"level"`
+	Text  string `json:"text"`
+	ID    string `json:"id"`
+}
+
+// TOC is the table of contents of a post, stored as JSONB.
+type TOC []TOCEntry
+
+func (t TOC) Value() (driver.Value, error) {
+	if t == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(t)
+}
+
+func (t *TOC) Scan(src any) error {
+	var data []byte
+	switch v := src.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	case nil:
+		*t = nil
+		return nil
+	default:
+		return errors.New("unsupported type for TOC")
+	}
+	return json.Unmarshal(data, t)
+}
@@ -10,6 +10,8 @@ type User struct {
 	Email     string     `db:"email" json:"email,omitempty"`
 	Password  string     `db:"password" json:"-"`
 	IsActive  bool       `db:"is_active" json:"isActive,omitempty"`
+	IsPrivate bool       `db:"is_private" json:"isPrivate"`
+	Locale    string     `db:"locale" json:"locale,omitempty"`
 	CreatedAt time.Time  `db:"created_at" json:"createdAt,omitempty"`
 	CreatedBy string     `db:"created_by" json:"createdBy,omitempty"`
 	UpdatedAt *time.Time `db:"updated_at" json:"updatedAt,omitempty"`
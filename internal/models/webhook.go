@@ -0,0 +1,47 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type Webhook struct {
+	ID           string     `json:"id"`
+	URL          string     `json:"url"`
+	Description  string     `json:"description"`
+	Events       []string   `json:"events"`
+	Secret       string     `json:"secret,omitempty"` // only shown when the webhook is created
+	IsActive     bool       `json:"isActive"`
+	FailureCount int        `json:"failureCount"` // deliveries failed in a row
+	DisabledAt   *time.Time `json:"disabledAt,omitempty"`
+	CreatedAt    time.Time  `json:"createdAt"`
+	CreatedBy    string     `json:"createdBy"`
+	UpdatedAt    *time.Time `json:"updatedAt,omitempty"`
+	UpdatedBy    *string    `json:"updatedBy,omitempty"`
+}
+
+type WebhookDelivery struct {
+	ID             int64           `json:"id"`
+	WebhookID      string          `json:"webhookId"`
+	EventID        int64           `json:"eventId"`
+	EventType      string          `json:"eventType"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"` // "pending", "succeeded" or "failed"
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  time.Time       `json:"nextAttemptAt"`
+	ResponseStatus *int            `json:"responseStatus,omitempty"`
+	ResponseBody   *string         `json:"responseBody,omitempty"`
+	LastError      *string         `json:"lastError,omitempty"`
+	DurationMs     *int            `json:"durationMs,omitempty"`
+	RedeliveryOf   *int64          `json:"redeliveryOf,omitempty"`
+	CreatedAt      time.Time       `json:"createdAt"`
+	DeliveredAt    *time.Time      `json:"deliveredAt,omitempty"`
+}
+
+// WebhookAttempt is the outcome of one attempt to deliver to a webhook.
+type WebhookAttempt struct {
+	ResponseStatus *int
+	ResponseBody   *string
+	Error          *string
+	DurationMs     int
+}
@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/lib/pq"
+	"github.com/wanafiq/feed-api/internal/constants"
+	"github.com/wanafiq/feed-api/internal/models"
+	"time"
+)
+
+type AnalyticsRepository interface {
+	SaveViews(ctx context.Context, tx *sql.Tx, views []models.PostView) error
+	FindDailyViews(ctx context.Context, postID string, from time.Time, to time.Time) ([]models.DailyViews, error)
+	CountUniqueViewers(ctx context.Context, postID string, from time.Time, to time.Time) (int, error)
+	DeleteByPostID(ctx context.Context, tx *sql.Tx, postID string) error
+}
+
+type analyticsRepository struct {
+	db *sql.DB
+}
+
+func NewAnalyticsRepository(db *sql.DB) AnalyticsRepository {
+	return &analyticsRepository{db: db}
+}
+
+// SaveViews adds a batch of views to the daily aggregates and records the daily
+// unique viewers, using one statement per table.
+func (r *analyticsRepository) SaveViews(ctx context.Context, tx *sql.Tx, views []models.PostView) error {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	if len(views) == 0 {
+		return nil
+	}
+
+	type dayKey struct {
+		postID string
+		day    string
+	}
+
+	counts := make(map[dayKey]int)
+	postIDs := make([]string, 0, len(views))
+	days := make([]string, 0, len(views))
+	viewerKeys := make([]string, 0, len(views))
+	for _, view := range views {
+		day := view.ViewedAt.UTC().Format(time.DateOnly)
+		counts[dayKey{postID: view.PostID, day: day}]++
+		postIDs = append(postIDs, view.PostID)
+		days = append(days, day)
+		viewerKeys = append(viewerKeys, view.ViewerKey)
+	}
+
+	aggPostIDs := make([]string, 0, len(counts))
+	aggDays := make([]string, 0, len(counts))
+	aggViews := make([]int64, 0, len(counts))
+	for key, count := range counts {
+		aggPostIDs = append(aggPostIDs, key.postID)
+		aggDays = append(aggDays, key.day)
+		aggViews = append(aggViews, int64(count))
+	}
+
+	// views of posts deleted since they were buffered are dropped
+	viewsQuery := `
+        INSERT INTO post_views_daily (post_id, day, views)
+        SELECT v.post_id, v.day, v.views
+        FROM unnest($1::uuid[], $2::date[], $3::int[]) AS v(post_id, day, views)
+        WHERE EXISTS (SELECT 1 FROM posts p WHERE p.id = v.post_id)
+        ON CONFLICT (post_id, day) DO UPDATE SET views = post_views_daily.views + EXCLUDED.views;
+    `
+
+	viewersQuery := `
+        INSERT INTO post_daily_viewers (post_id, day, viewer_key)
+        SELECT DISTINCT v.post_id, v.day, v.viewer_key
+        FROM unnest($1::uuid[], $2::date[], $3::varchar[]) AS v(post_id, day, viewer_key)
+        WHERE EXISTS (SELECT 1 FROM posts p WHERE p.id = v.post_id)
+        ON CONFLICT (post_id, day, viewer_key) DO NOTHING;
+    `
+
+	var err error
+	if tx != nil {
+		_, err = tx.ExecContext(ctx, viewsQuery, pq.Array(aggPostIDs), pq.Array(aggDays), pq.Array(aggViews))
+	} else {
+		_, err = r.db.ExecContext(ctx, viewsQuery, pq.Array(aggPostIDs), pq.Array(aggDays), pq.Array(aggViews))
+	}
+	if err != nil {
+		return err
+	}
+
+	if tx != nil {
+		_, err = tx.ExecContext(ctx, viewersQuery, pq.Array(postIDs), pq.Array(days), pq.Array(viewerKeys))
+	} else {
+		_, err = r.db.ExecContext(ctx, viewersQuery, pq.Array(postIDs), pq.Array(days), pq.Array(viewerKeys))
+	}
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *analyticsRepository) FindDailyViews(ctx context.Context, postID string, from time.Time, to time.Time) ([]models.DailyViews, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+        SELECT v.day, v.views, COALESCE(u.unique_viewers, 0)
+        FROM post_views_daily v
+        LEFT JOIN (
+            SELECT day, COUNT(*) AS unique_viewers
+            FROM post_daily_viewers
+            WHERE post_id = $1 AND day BETWEEN $2::date AND $3::date
+            GROUP BY day
+        ) u ON u.day = v.day
+        WHERE v.post_id = $1 AND v.day BETWEEN $2::date AND $3::date
+        ORDER BY v.day;
+    `
+
+	rows, err := r.db.QueryContext(ctx, query, postID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var daily []models.DailyViews
+	for rows.Next() {
+		var d models.DailyViews
+		if err := rows.Scan(&d.Day, &d.Views, &d.UniqueViewers); err != nil {
+			return nil, err
+		}
+		daily = append(daily, d)
+	}
+
+	return daily, rows.Err()
+}
+
+func (r *analyticsRepository) CountUniqueViewers(ctx context.Context, postID string, from time.Time, to time.Time) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+        SELECT COUNT(DISTINCT viewer_key)
+        FROM post_daily_viewers
+        WHERE post_id = $1 AND day BETWEEN $2::date AND $3::date;
+    `
+
+	var count int
+	if err := r.db.QueryRowContext(ctx, query, postID, from, to).Scan(&count); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (r *analyticsRepository) DeleteByPostID(ctx context.Context, tx *sql.Tx, postID string) error {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	queries := []string{
+		`DELETE FROM post_views_daily WHERE post_id = $1;`,
+		`DELETE FROM post_daily_viewers WHERE post_id = $1;`,
+	}
+
+	for _, query := range queries {
+		var err error
+		if tx != nil {
+			_, err = tx.ExecContext(ctx, query, postID)
+		} else {
+			_, err = r.db.ExecContext(ctx, query, postID)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/wanafiq/feed-api/internal/constants"
+	"github.com/wanafiq/feed-api/internal/models"
+)
+
+type AuditRepository interface {
+	Save(ctx context.Context, tx *sql.Tx, log *models.AuditLog) error
+	FindAll(ctx context.Context, filter models.AuditLogFilter) ([]*models.AuditLog, int, error)
+}
+
+type auditRepository struct {
+	db *sql.DB
+}
+
+func NewAuditRepository(db *sql.DB) AuditRepository {
+	return &auditRepository{db: db}
+}
+
+func (r *auditRepository) Save(ctx context.Context, tx *sql.Tx, log *models.AuditLog) error {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+        INSERT INTO audit_logs (actor_id, action, target_type, target_id, before, after, ip, user_agent)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+        RETURNING id, created_at;
+    `
+
+	args := []any{
+		log.ActorID,
+		log.Action,
+		log.TargetType,
+		log.TargetID,
+		nullJSON(log.Before),
+		nullJSON(log.After),
+		log.IP,
+		log.UserAgent,
+	}
+
+	var row *sql.Row
+	if tx != nil {
+		row = tx.QueryRowContext(ctx, query, args...)
+	} else {
+		row = r.db.QueryRowContext(ctx, query, args...)
+	}
+
+	return row.Scan(&log.ID, &log.CreatedAt)
+}
+
+// FindAll lists audit logs matching the filter, newest first, along with their
+// total number. Empty filter fields match everything.
+func (r *auditRepository) FindAll(ctx context.Context, filter models.AuditLogFilter) ([]*models.AuditLog, int, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	where := `
+        WHERE ($1 = '' OR actor_id::text = $1)
+          AND ($2 = '' OR action = $2)
+          AND ($3 = '' OR target_type = $3)
+          AND ($4 = '' OR target_id = $4)
+          AND ($5::timestamptz IS NULL OR created_at >= $5)
+          AND ($6::timestamptz IS NULL OR created_at < $6)
+    `
+	args := []any{filter.ActorID, filter.Action, filter.TargetType, filter.TargetID, filter.Since, filter.Until}
+
+	countQuery := `SELECT COUNT(*) FROM audit_logs` + where
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+        SELECT id, actor_id, action, target_type, target_id, before, after, ip, user_agent, created_at
+        FROM audit_logs` + where + `
+        ORDER BY created_at DESC, id DESC
+        OFFSET $7 LIMIT $8;
+    `
+
+	rows, err := r.db.QueryContext(ctx, query, append(args, filter.Offset, filter.Limit)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var logs []*models.AuditLog
+	for rows.Next() {
+		var log models.AuditLog
+		var before, after []byte
+		err := rows.Scan(
+			&log.ID,
+			&log.ActorID,
+			&log.Action,
+			&log.TargetType,
+			&log.TargetID,
+			&before,
+			&after,
+			&log.IP,
+			&log.UserAgent,
+			&log.CreatedAt,
+		)
+		if err != nil {
+			return nil, 0, err
+		}
+		log.Before = before
+		log.After = after
+		logs = append(logs, &log)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return logs, total, nil
+}
+
+// nullJSON stores an empty snapshot as NULL rather than invalid JSON.
+func nullJSON(data []byte) any {
+	if len(data) == 0 {
+		return nil
+	}
+
+	return data
+}
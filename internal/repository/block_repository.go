@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/wanafiq/feed-api/internal/constants"
+	"github.com/wanafiq/feed-api/internal/models"
+)
+
+type BlockRepository interface {
+	Save(ctx context.Context, tx *sql.Tx, blockerID string, blockedID string) error
+	Delete(ctx context.Context, tx *sql.Tx, blockerID string, blockedID string) error
+	ExistsBetween(ctx context.Context, userID string, otherUserID string) (bool, error)
+	FindBlockedUsers(ctx context.Context, blockerID string, limit int, offset int) ([]*models.User, int, error)
+}
+
+type blockRepository struct {
+	db *sql.DB
+}
+
+func NewBlockRepository(db *sql.DB) BlockRepository {
+	return &blockRepository{db: db}
+}
+
+func (r *blockRepository) Save(ctx context.Context, tx *sql.Tx, blockerID string, blockedID string) error {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+        INSERT INTO blocks (blocker_id, blocked_id)
+        VALUES ($1, $2)
+        ON CONFLICT (blocker_id, blocked_id) DO NOTHING;
+    `
+
+	var err error
+	if tx != nil {
+		_, err = tx.ExecContext(ctx, query, blockerID, blockedID)
+	} else {
+		_, err = r.db.ExecContext(ctx, query, blockerID, blockedID)
+	}
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *blockRepository) Delete(ctx context.Context, tx *sql.Tx, blockerID string, blockedID string) error {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+        DELETE FROM blocks
+        WHERE blocker_id = $1 AND blocked_id = $2;
+    `
+
+	var err error
+	if tx != nil {
+		_, err = tx.ExecContext(ctx, query, blockerID, blockedID)
+	} else {
+		_, err = r.db.ExecContext(ctx, query, blockerID, blockedID)
+	}
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ExistsBetween reports whether either user has blocked the other.
+func (r *blockRepository) ExistsBetween(ctx context.Context, userID string, otherUserID string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+        SELECT EXISTS (
+            SELECT 1
+            FROM blocks
+            WHERE (blocker_id = $1 AND blocked_id = $2)
+               OR (blocker_id = $2 AND blocked_id = $1)
+        );
+    `
+
+	var exists bool
+	if err := r.db.QueryRowContext(ctx, query, userID, otherUserID).Scan(&exists); err != nil {
+		return false, err
+	}
+
+	return exists, nil
+}
+
+func (r *blockRepository) FindBlockedUsers(ctx context.Context, blockerID string, limit int, offset int) ([]*models.User, int, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	countQuery := `SELECT COUNT(*) FROM blocks WHERE blocker_id = $1`
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, countQuery, blockerID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+        SELECT u.id, u.username, u.email, u.is_active, u.created_at
+        FROM blocks b
+        JOIN users u ON u.id = b.blocked_id
+        WHERE b.blocker_id = $1
+        ORDER BY b.created_at DESC
+        LIMIT $2 OFFSET $3;
+    `
+
+	rows, err := r.db.QueryContext(ctx, query, blockerID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var users []*models.User
+	for rows.Next() {
+		var user models.User
+		err := rows.Scan(&user.ID, &user.Username, &user.Email, &user.IsActive, &user.CreatedAt)
+		if err != nil {
+			return nil, 0, err
+		}
+		users = append(users, &user)
+	}
+
+	return users, total, rows.Err()
+}
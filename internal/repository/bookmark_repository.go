@@ -0,0 +1,320 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/lib/pq"
+	"github.com/wanafiq/feed-api/internal/constants"
+	"github.com/wanafiq/feed-api/internal/models"
+	"strings"
+)
+
+type BookmarkRepository interface {
+	Save(ctx context.Context, tx *sql.Tx, bookmark *models.Bookmark) error
+	Delete(ctx context.Context, tx *sql.Tx, userID string, postID string) error
+	DeleteByPostID(ctx context.Context, tx *sql.Tx, postID string) error
+	FindAll(ctx context.Context, filter models.BookmarkFilter) ([]*models.Bookmark, error)
+	FindBookmarkedPostIDs(ctx context.Context, userID string, postIDs []string) (map[string]bool, error)
+
+	SaveCollection(ctx context.Context, tx *sql.Tx, collection *models.BookmarkCollection) error
+	FindCollections(ctx context.Context, userID string) ([]*models.BookmarkCollection, error)
+	FindCollectionByID(ctx context.Context, userID string, collectionID string) (*models.BookmarkCollection, error)
+	DeleteCollection(ctx context.Context, tx *sql.Tx, userID string, collectionID string) error
+}
+
+type bookmarkRepository struct {
+	db *sql.DB
+}
+
+func NewBookmarkRepository(db *sql.DB) BookmarkRepository {
+	return &bookmarkRepository{db: db}
+}
+
+// Save bookmarks a post, or moves an existing bookmark to the given collection.
+// Bookmarking a post again without a collection keeps it where it is.
+func (r *bookmarkRepository) Save(ctx context.Context, tx *sql.Tx, bookmark *models.Bookmark) error {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+        INSERT INTO bookmarks (user_id, post_id, collection_id)
+        VALUES ($1, $2, $3)
+        ON CONFLICT (user_id, post_id) DO UPDATE
+            SET collection_id = COALESCE(EXCLUDED.collection_id, bookmarks.collection_id)
+        RETURNING id, collection_id, created_at;
+    `
+
+	var row *sql.Row
+	if tx != nil {
+		row = tx.QueryRowContext(ctx, query, bookmark.UserID, bookmark.PostID, bookmark.CollectionID)
+	} else {
+		row = r.db.QueryRowContext(ctx, query, bookmark.UserID, bookmark.PostID, bookmark.CollectionID)
+	}
+
+	return row.Scan(&bookmark.ID, &bookmark.CollectionID, &bookmark.CreatedAt)
+}
+
+func (r *bookmarkRepository) Delete(ctx context.Context, tx *sql.Tx, userID string, postID string) error {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+        DELETE FROM bookmarks
+        WHERE user_id = $1 AND post_id = $2;
+    `
+
+	var err error
+	if tx != nil {
+		_, err = tx.ExecContext(ctx, query, userID, postID)
+	} else {
+		_, err = r.db.ExecContext(ctx, query, userID, postID)
+	}
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *bookmarkRepository) DeleteByPostID(ctx context.Context, tx *sql.Tx, postID string) error {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+        DELETE FROM bookmarks
+        WHERE post_id = $1;
+    `
+
+	var err error
+	if tx != nil {
+		_, err = tx.ExecContext(ctx, query, postID)
+	} else {
+		_, err = r.db.ExecContext(ctx, query, postID)
+	}
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// FindAll returns the user's bookmarks newest first, starting after the cursor when set.
+// Bookmarked posts the user can no longer see are skipped.
+func (r *bookmarkRepository) FindAll(ctx context.Context, filter models.BookmarkFilter) ([]*models.Bookmark, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	args := []any{filter.UserID}
+	argID := 2
+	where := []string{"b.user_id = $1"}
+
+	if filter.CollectionID != "" {
+		where = append(where, fmt.Sprintf("b.collection_id = $%d", argID))
+		args = append(args, filter.CollectionID)
+		argID++
+	}
+
+	if filter.CursorTime != nil {
+		where = append(where, fmt.Sprintf("(b.created_at, b.id) < ($%d, $%d)", argID, argID+1))
+		args = append(args, *filter.CursorTime, filter.CursorID)
+		argID += 2
+	}
+
+	// $1 is the bookmark owner, who is also the viewer. Unlisted posts can be
+	// bookmarked, so they are listed here unlike in the feeds.
+	visibilityClause, _ := postViewableClause(filter.UserID, 1)
+	where = append(where, postBlockClause(1), visibilityClause)
+
+	query := fmt.Sprintf(`
+		SELECT
+			b.id, b.user_id, b.post_id, b.collection_id, b.created_at,
+			p.id, p.title, p.slug, p.content, p.is_published, p.published_at,
+			p.created_at, p.created_by, p.updated_at, p.updated_by, p.author_id, p.visibility,
+			p.excerpt, p.word_count, p.reading_time,
+			u.id, u.username, u.email
+		FROM bookmarks b
+		JOIN posts p ON p.id = b.post_id
+		JOIN users u ON u.id = p.author_id
+		WHERE %s
+		ORDER BY b.created_at DESC, b.id DESC
+		LIMIT $%d
+	`, strings.Join(where, " AND "), argID)
+	args = append(args, filter.Limit)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var bookmarks []*models.Bookmark
+	for rows.Next() {
+		var bookmark models.Bookmark
+		var post models.Post
+		err := rows.Scan(
+			&bookmark.ID,
+			&bookmark.UserID,
+			&bookmark.PostID,
+			&bookmark.CollectionID,
+			&bookmark.CreatedAt,
+			&post.ID,
+			&post.Title,
+			&post.Slug,
+			&post.Content,
+			&post.IsPublished,
+			&post.PublishedAt,
+			&post.CreatedAt,
+			&post.CreatedBy,
+			&post.UpdatedAt,
+			&post.UpdatedBy,
+			&post.AuthorID,
+			&post.Visibility,
+			&post.Excerpt,
+			&post.WordCount,
+			&post.ReadingTime,
+			&post.Author.ID,
+			&post.Author.Username,
+			&post.Author.Email,
+		)
+		if err != nil {
+			return nil, err
+		}
+		post.Bookmarked = true
+		bookmark.Post = &post
+		bookmarks = append(bookmarks, &bookmark)
+	}
+
+	return bookmarks, rows.Err()
+}
+
+func (r *bookmarkRepository) FindBookmarkedPostIDs(ctx context.Context, userID string, postIDs []string) (map[string]bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	bookmarked := make(map[string]bool)
+	if len(postIDs) == 0 {
+		return bookmarked, nil
+	}
+
+	query := `
+        SELECT post_id
+        FROM bookmarks
+        WHERE user_id = $1 AND post_id = ANY($2);
+    `
+
+	rows, err := r.db.QueryContext(ctx, query, userID, pq.Array(postIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var postID string
+		if err := rows.Scan(&postID); err != nil {
+			return nil, err
+		}
+		bookmarked[postID] = true
+	}
+
+	return bookmarked, rows.Err()
+}
+
+// SaveCollection creates a collection. It returns sql.ErrNoRows when the user
+// already has a collection with that name.
+func (r *bookmarkRepository) SaveCollection(ctx context.Context, tx *sql.Tx, collection *models.BookmarkCollection) error {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+        INSERT INTO bookmark_collections (user_id, name)
+        VALUES ($1, $2)
+        ON CONFLICT (user_id, name) DO NOTHING
+        RETURNING id, created_at;
+    `
+
+	var row *sql.Row
+	if tx != nil {
+		row = tx.QueryRowContext(ctx, query, collection.UserID, collection.Name)
+	} else {
+		row = r.db.QueryRowContext(ctx, query, collection.UserID, collection.Name)
+	}
+
+	return row.Scan(&collection.ID, &collection.CreatedAt)
+}
+
+func (r *bookmarkRepository) FindCollections(ctx context.Context, userID string) ([]*models.BookmarkCollection, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+        SELECT id, user_id, name, created_at
+        FROM bookmark_collections
+        WHERE user_id = $1
+        ORDER BY name;
+    `
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var collections []*models.BookmarkCollection
+	for rows.Next() {
+		var collection models.BookmarkCollection
+		err := rows.Scan(&collection.ID, &collection.UserID, &collection.Name, &collection.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		collections = append(collections, &collection)
+	}
+
+	return collections, rows.Err()
+}
+
+func (r *bookmarkRepository) FindCollectionByID(ctx context.Context, userID string, collectionID string) (*models.BookmarkCollection, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+        SELECT id, user_id, name, created_at
+        FROM bookmark_collections
+        WHERE id = $1 AND user_id = $2;
+    `
+
+	collection := &models.BookmarkCollection{}
+	err := r.db.QueryRowContext(ctx, query, collectionID, userID).Scan(
+		&collection.ID,
+		&collection.UserID,
+		&collection.Name,
+		&collection.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return collection, nil
+}
+
+func (r *bookmarkRepository) DeleteCollection(ctx context.Context, tx *sql.Tx, userID string, collectionID string) error {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+        DELETE FROM bookmark_collections
+        WHERE id = $1 AND user_id = $2;
+    `
+
+	var err error
+	if tx != nil {
+		_, err = tx.ExecContext(ctx, query, collectionID, userID)
+	} else {
+		_, err = r.db.ExecContext(ctx, query, collectionID, userID)
+	}
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
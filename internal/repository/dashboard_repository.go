@@ -0,0 +1,187 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/wanafiq/feed-api/internal/constants"
+	"github.com/wanafiq/feed-api/internal/models"
+	"time"
+)
+
+type DashboardRepository interface {
+	CountPosts(ctx context.Context, authorID string) (models.PostCounts, error)
+	CountFollowers(ctx context.Context, userID string) (int, error)
+	FindFollowerGrowth(ctx context.Context, userID string, from time.Time, to time.Time) ([]models.DailyCount, error)
+	FindTopPosts(ctx context.Context, authorID string, from time.Time, to time.Time, orderBy string, limit int) ([]models.TopPost, error)
+	FindRecentActivities(ctx context.Context, userID string, limit int) ([]models.Activity, error)
+}
+
+type dashboardRepository struct {
+	db *sql.DB
+}
+
+func NewDashboardRepository(db *sql.DB) DashboardRepository {
+	return &dashboardRepository{db: db}
+}
+
+// CountPosts counts drafts, published and scheduled posts (published with a future publish date).
+func (r *dashboardRepository) CountPosts(ctx context.Context, authorID string) (models.PostCounts, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+        SELECT
+            COUNT(*) FILTER (WHERE NOT COALESCE(is_published, FALSE)),
+            COUNT(*) FILTER (WHERE is_published AND (published_at IS NULL OR published_at <= NOW())),
+            COUNT(*) FILTER (WHERE is_published AND published_at > NOW())
+        FROM posts
+        WHERE author_id = $1;
+    `
+
+	var counts models.PostCounts
+	err := r.db.QueryRowContext(ctx, query, authorID).Scan(&counts.Drafts, &counts.Published, &counts.Scheduled)
+	if err != nil {
+		return models.PostCounts{}, err
+	}
+
+	return counts, nil
+}
+
+func (r *dashboardRepository) CountFollowers(ctx context.Context, userID string) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `SELECT COUNT(*) FROM followers WHERE followee_id = $1`
+
+	var count int
+	if err := r.db.QueryRowContext(ctx, query, userID).Scan(&count); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// FindFollowerGrowth returns new followers per day, including days without any.
+func (r *dashboardRepository) FindFollowerGrowth(ctx context.Context, userID string, from time.Time, to time.Time) ([]models.DailyCount, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+        SELECT d.day::date, COUNT(f.follower_id)
+        FROM generate_series($2::date, $3::date, INTERVAL '1 day') AS d(day)
+        LEFT JOIN followers f ON f.followee_id = $1 AND f.created_at::date = d.day::date
+        GROUP BY d.day
+        ORDER BY d.day;
+    `
+
+	rows, err := r.db.QueryContext(ctx, query, userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var growth []models.DailyCount
+	for rows.Next() {
+		var d models.DailyCount
+		if err := rows.Scan(&d.Day, &d.Count); err != nil {
+			return nil, err
+		}
+		growth = append(growth, d)
+	}
+
+	return growth, rows.Err()
+}
+
+// FindTopPosts ranks the author's posts by views or reactions received within the range.
+func (r *dashboardRepository) FindTopPosts(ctx context.Context, authorID string, from time.Time, to time.Time, orderBy string, limit int) ([]models.TopPost, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	order := "views DESC, reactions DESC"
+	if orderBy == constants.TopPostsByReactions {
+		order = "reactions DESC, views DESC"
+	}
+
+	query := fmt.Sprintf(`
+        SELECT p.id, p.title, p.slug, COALESCE(v.views, 0) AS views, COALESCE(re.reactions, 0) AS reactions
+        FROM posts p
+        LEFT JOIN (
+            SELECT post_id, SUM(views) AS views
+            FROM post_views_daily
+            WHERE day BETWEEN $2::timestamptz::date AND $3::timestamptz::date
+            GROUP BY post_id
+        ) v ON v.post_id = p.id
+        LEFT JOIN (
+            SELECT post_id, COUNT(*) AS reactions
+            FROM reactions
+            WHERE created_at BETWEEN $2::timestamptz AND $3::timestamptz
+            GROUP BY post_id
+        ) re ON re.post_id = p.id
+        WHERE p.author_id = $1
+        ORDER BY %s, p.created_at DESC
+        LIMIT $4;
+    `, order)
+
+	rows, err := r.db.QueryContext(ctx, query, authorID, from, to, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var posts []models.TopPost
+	for rows.Next() {
+		var post models.TopPost
+		if err := rows.Scan(&post.ID, &post.Title, &post.Slug, &post.Views, &post.Reactions); err != nil {
+			return nil, err
+		}
+		posts = append(posts, post)
+	}
+
+	return posts, rows.Err()
+}
+
+// FindRecentActivities returns the latest follows of the user and reactions on the user's posts.
+func (r *dashboardRepository) FindRecentActivities(ctx context.Context, userID string, limit int) ([]models.Activity, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+        SELECT type, reaction, actor_id, actor_username, post_id, post_title, created_at
+        FROM (
+            SELECT 'follow' AS type, '' AS reaction, u.id AS actor_id, u.username AS actor_username,
+                   NULL::uuid AS post_id, NULL::text AS post_title, f.created_at
+            FROM followers f
+            JOIN users u ON u.id = f.follower_id
+            WHERE f.followee_id = $1
+
+            UNION ALL
+
+            SELECT 'reaction', re.type, u.id, u.username, p.id, p.title, re.created_at
+            FROM reactions re
+            JOIN posts p ON p.id = re.post_id
+            JOIN users u ON u.id = re.user_id
+            WHERE p.author_id = $1 AND re.user_id <> $1
+        ) activities
+        ORDER BY created_at DESC
+        LIMIT $2;
+    `
+
+	rows, err := r.db.QueryContext(ctx, query, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var activities []models.Activity
+	for rows.Next() {
+		var a models.Activity
+		err := rows.Scan(&a.Type, &a.Reaction, &a.ActorID, &a.ActorName, &a.PostID, &a.PostTitle, &a.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		activities = append(activities, a)
+	}
+
+	return activities, rows.Err()
+}
@@ -0,0 +1,225 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/wanafiq/feed-api/internal/constants"
+	"github.com/wanafiq/feed-api/internal/models"
+	"time"
+)
+
+type DigestRepository interface {
+	FindDue(ctx context.Context, defaultFrequency string, slack time.Duration, limit int) ([]*models.DigestRecipient, error)
+	FindPosts(ctx context.Context, userID string, since time.Time, until time.Time, limit int) ([]*models.DigestPost, int, error)
+	Save(ctx context.Context, digest *models.Digest) error
+	MarkSent(ctx context.Context, digestID int64) error
+	Delete(ctx context.Context, digestID int64) error
+	FindFrequency(ctx context.Context, userID string) (string, error)
+	SaveFrequency(ctx context.Context, userID string, frequency string) error
+}
+
+type digestRepository struct {
+	db *sql.DB
+}
+
+func NewDigestRepository(db *sql.DB) DigestRepository {
+	return &digestRepository{db: db}
+}
+
+// FindDue returns active users whose last digest ended at least a day, or a week
+// for weekly digests, minus slack ago. Users who never got one are due right
+// away. slack keeps digests from running later every period when they are
+// looked for at an interval.
+func (r *digestRepository) FindDue(ctx context.Context, defaultFrequency string, slack time.Duration, limit int) ([]*models.DigestRecipient, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+        SELECT u.id, u.username, u.email, u.locale, f.frequency, last.period_end
+        FROM users u
+        LEFT JOIN digest_settings ds ON ds.user_id = u.id
+        CROSS JOIN LATERAL (SELECT COALESCE(ds.frequency, $1) AS frequency) f
+        LEFT JOIN LATERAL (
+            SELECT d.period_end
+            FROM digests d
+            WHERE d.user_id = u.id
+            ORDER BY d.period_end DESC
+            LIMIT 1
+        ) last ON TRUE
+        WHERE u.is_active = TRUE
+          AND f.frequency IN ($2, $3)
+          AND (
+              last.period_end IS NULL
+              OR last.period_end <= NOW() - CASE f.frequency WHEN $2 THEN INTERVAL '1 day' ELSE INTERVAL '7 days' END
+                                    + make_interval(secs => $5)
+          )
+        ORDER BY last.period_end NULLS FIRST
+        LIMIT $4;
+    `
+
+	rows, err := r.db.QueryContext(ctx, query, defaultFrequency, constants.DigestFrequencyDaily, constants.DigestFrequencyWeekly, limit, slack.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var recipients []*models.DigestRecipient
+	for rows.Next() {
+		var recipient models.DigestRecipient
+		err := rows.Scan(
+			&recipient.User.ID,
+			&recipient.User.Username,
+			&recipient.User.Email,
+			&recipient.User.Locale,
+			&recipient.Frequency,
+			&recipient.LastPeriodEnd,
+		)
+		if err != nil {
+			return nil, err
+		}
+		recipients = append(recipients, &recipient)
+	}
+
+	return recipients, rows.Err()
+}
+
+// FindPosts returns the posts published by users userID follows between since
+// and until, newest first, along with their total number.
+func (r *digestRepository) FindPosts(ctx context.Context, userID string, since time.Time, until time.Time, limit int) ([]*models.DigestPost, int, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+        SELECT p.id, p.title, p.excerpt, u.username, p.published_at, COUNT(*) OVER ()
+        FROM posts p
+        JOIN users u ON u.id = p.author_id
+        WHERE p.is_published = TRUE
+          AND p.published_at > $2 AND p.published_at <= $3
+          AND p.visibility IN ($5, $6)
+          AND p.author_id IN (SELECT f.followee_id FROM followers f WHERE f.follower_id = $1)
+          AND NOT EXISTS (
+              SELECT 1 FROM blocks b
+              WHERE (b.blocker_id = $1 AND b.blocked_id = p.author_id) OR (b.blocker_id = p.author_id AND b.blocked_id = $1)
+          )
+          AND NOT EXISTS (SELECT 1 FROM mutes m WHERE m.muter_id = $1 AND m.muted_id = p.author_id)
+        ORDER BY p.published_at DESC
+        LIMIT $4;
+    `
+
+	rows, err := r.db.QueryContext(ctx, query, userID, since, until, limit,
+		constants.PostVisibilityPublic, constants.PostVisibilityFollowers)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var posts []*models.DigestPost
+	var total int
+	for rows.Next() {
+		var post models.DigestPost
+		if err := rows.Scan(&post.ID, &post.Title, &post.Excerpt, &post.AuthorUsername, &post.PublishedAt, &total); err != nil {
+			return nil, 0, err
+		}
+		posts = append(posts, &post)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return posts, total, nil
+}
+
+// Save records a digest. It returns sql.ErrNoRows when the user already has one
+// for the same period start, i.e. another instance took it.
+func (r *digestRepository) Save(ctx context.Context, digest *models.Digest) error {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+        INSERT INTO digests (user_id, frequency, period_start, period_end, post_count, notification_count)
+        VALUES ($1, $2, $3, $4, $5, $6)
+        ON CONFLICT (user_id, period_start) DO NOTHING
+        RETURNING id, created_at;
+    `
+
+	return r.db.QueryRowContext(ctx, query,
+		digest.UserID,
+		digest.Frequency,
+		digest.PeriodStart,
+		digest.PeriodEnd,
+		digest.PostCount,
+		digest.NotificationCount,
+	).Scan(&digest.ID, &digest.CreatedAt)
+}
+
+func (r *digestRepository) MarkSent(ctx context.Context, digestID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+        UPDATE digests
+        SET sent_at = NOW()
+        WHERE id = $1;
+    `
+
+	_, err := r.db.ExecContext(ctx, query, digestID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *digestRepository) Delete(ctx context.Context, digestID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+        DELETE FROM digests
+        WHERE id = $1;
+    `
+
+	_, err := r.db.ExecContext(ctx, query, digestID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// FindFrequency returns sql.ErrNoRows when the user never chose a frequency.
+func (r *digestRepository) FindFrequency(ctx context.Context, userID string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+        SELECT frequency
+        FROM digest_settings
+        WHERE user_id = $1;
+    `
+
+	var frequency string
+	if err := r.db.QueryRowContext(ctx, query, userID).Scan(&frequency); err != nil {
+		return "", err
+	}
+
+	return frequency, nil
+}
+
+func (r *digestRepository) SaveFrequency(ctx context.Context, userID string, frequency string) error {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+        INSERT INTO digest_settings (user_id, frequency)
+        VALUES ($1, $2)
+        ON CONFLICT (user_id) DO UPDATE SET frequency = EXCLUDED.frequency, updated_at = NOW();
+    `
+
+	_, err := r.db.ExecContext(ctx, query, userID, frequency)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,241 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/wanafiq/feed-api/internal/constants"
+	"github.com/wanafiq/feed-api/internal/models"
+	"time"
+)
+
+type EventRepository interface {
+	Save(ctx context.Context, tx *sql.Tx, event *models.Event) error
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*models.Event, error)
+	MarkCompleted(ctx context.Context, eventID int64) error
+	MarkFailed(ctx context.Context, eventID int64, lastError string, nextAttemptAt time.Time) error
+	MarkDead(ctx context.Context, eventID int64, lastError string) error
+	FindAll(ctx context.Context, status string, offset int, limit int) ([]*models.Event, int, error)
+	Retry(ctx context.Context, tx *sql.Tx, eventID int64, updatedBy string) error
+}
+
+type eventRepository struct {
+	db *sql.DB
+}
+
+func NewEventRepository(db *sql.DB) EventRepository {
+	return &eventRepository{db: db}
+}
+
+func (r *eventRepository) Save(ctx context.Context, tx *sql.Tx, event *models.Event) error {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+        INSERT INTO events (event_type, handler, payload, created_by)
+        VALUES ($1, $2, $3, $4)
+        RETURNING id, status, next_attempt_at, created_at;
+    `
+
+	args := []any{event.EventType, event.Handler, []byte(event.Payload), event.CreatedBy}
+
+	var row *sql.Row
+	if tx != nil {
+		row = tx.QueryRowContext(ctx, query, args...)
+	} else {
+		row = r.db.QueryRowContext(ctx, query, args...)
+	}
+
+	return row.Scan(&event.ID, &event.Status, &event.NextAttemptAt, &event.CreatedAt)
+}
+
+// ClaimDue picks pending events whose next attempt is due and pushes that attempt
+// back by lease, so concurrent dispatchers skip them. An event whose dispatcher
+// dies before marking it is picked up again once the lease ends.
+func (r *eventRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*models.Event, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+        UPDATE events e
+        SET attempts = e.attempts + 1,
+            next_attempt_at = NOW() + make_interval(secs => $2)
+        WHERE e.id IN (
+            SELECT id
+            FROM events
+            WHERE status = $3 AND next_attempt_at <= NOW()
+            ORDER BY next_attempt_at, id
+            LIMIT $1
+            FOR UPDATE SKIP LOCKED
+        )
+        RETURNING e.id, e.event_type, e.handler, e.payload, e.status, e.attempts, e.next_attempt_at,
+                  e.last_error, e.created_at, e.created_by, e.updated_at, e.updated_by;
+    `
+
+	rows, err := r.db.QueryContext(ctx, query, limit, lease.Seconds(), constants.EventStatusPending)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanEvents(rows)
+}
+
+func (r *eventRepository) MarkCompleted(ctx context.Context, eventID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+        UPDATE events
+        SET status = $2, last_error = NULL, updated_at = NOW(), updated_by = 'system'
+        WHERE id = $1;
+    `
+
+	_, err := r.db.ExecContext(ctx, query, eventID, constants.EventStatusCompleted)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// MarkFailed records a failed attempt and when to try again.
+func (r *eventRepository) MarkFailed(ctx context.Context, eventID int64, lastError string, nextAttemptAt time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+        UPDATE events
+        SET last_error = $2, next_attempt_at = $3, updated_at = NOW(), updated_by = 'system'
+        WHERE id = $1;
+    `
+
+	_, err := r.db.ExecContext(ctx, query, eventID, lastError, nextAttemptAt)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// MarkDead parks an event that will not be retried unless asked to.
+func (r *eventRepository) MarkDead(ctx context.Context, eventID int64, lastError string) error {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+        UPDATE events
+        SET status = $2, last_error = $3, updated_at = NOW(), updated_by = 'system'
+        WHERE id = $1;
+    `
+
+	_, err := r.db.ExecContext(ctx, query, eventID, constants.EventStatusDead, lastError)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// FindAll lists events, newest first, optionally filtered by status.
+func (r *eventRepository) FindAll(ctx context.Context, status string, offset int, limit int) ([]*models.Event, int, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	countQuery := `
+        SELECT COUNT(*)
+        FROM events
+        WHERE $1 = '' OR status = $1;
+    `
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, countQuery, status).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+        SELECT id, event_type, handler, payload, status, attempts, next_attempt_at,
+               last_error, created_at, created_by, updated_at, updated_by
+        FROM events
+        WHERE $1 = '' OR status = $1
+        ORDER BY created_at DESC, id DESC
+        OFFSET $2 LIMIT $3;
+    `
+
+	rows, err := r.db.QueryContext(ctx, query, status, offset, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	events, err := scanEvents(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return events, total, nil
+}
+
+// Retry puts a dead event back in the queue with a fresh set of attempts. It
+// returns sql.ErrNoRows when there is no dead event with that id.
+func (r *eventRepository) Retry(ctx context.Context, tx *sql.Tx, eventID int64, updatedBy string) error {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+        UPDATE events
+        SET status = $2, attempts = 0, next_attempt_at = NOW(), updated_at = NOW(), updated_by = $4
+        WHERE id = $1 AND status = $3;
+    `
+
+	args := []any{eventID, constants.EventStatusPending, constants.EventStatusDead, updatedBy}
+
+	var result sql.Result
+	var err error
+	if tx != nil {
+		result, err = tx.ExecContext(ctx, query, args...)
+	} else {
+		result, err = r.db.ExecContext(ctx, query, args...)
+	}
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func scanEvents(rows *sql.Rows) ([]*models.Event, error) {
+	var events []*models.Event
+	for rows.Next() {
+		var event models.Event
+		var payload []byte
+		err := rows.Scan(
+			&event.ID,
+			&event.EventType,
+			&event.Handler,
+			&payload,
+			&event.Status,
+			&event.Attempts,
+			&event.NextAttemptAt,
+			&event.LastError,
+			&event.CreatedAt,
+			&event.CreatedBy,
+			&event.UpdatedAt,
+			&event.UpdatedBy,
+		)
+		if err != nil {
+			return nil, err
+		}
+		event.Payload = payload
+		events = append(events, &event)
+	}
+
+	return events, rows.Err()
+}
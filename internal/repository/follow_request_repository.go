@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/wanafiq/feed-api/internal/constants"
+	"github.com/wanafiq/feed-api/internal/models"
+)
+
+type FollowRequestRepository interface {
+	Save(ctx context.Context, tx *sql.Tx, requesterID string, targetID string) (bool, error)
+	Delete(ctx context.Context, tx *sql.Tx, requesterID string, targetID string) error
+	Exists(ctx context.Context, requesterID string, targetID string) (bool, error)
+	FindIncoming(ctx context.Context, targetID string, limit int, offset int) ([]*models.FollowRequest, int, error)
+	FindOutgoing(ctx context.Context, requesterID string, limit int, offset int) ([]*models.FollowRequest, int, error)
+}
+
+type followRequestRepository struct {
+	db *sql.DB
+}
+
+func NewFollowRequestRepository(db *sql.DB) FollowRequestRepository {
+	return &followRequestRepository{db: db}
+}
+
+// Save creates a follow request and reports whether none was pending yet.
+func (r *followRequestRepository) Save(ctx context.Context, tx *sql.Tx, requesterID string, targetID string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+        INSERT INTO follow_requests (requester_id, target_id)
+        VALUES ($1, $2)
+        ON CONFLICT (requester_id, target_id) DO NOTHING;
+    `
+
+	var result sql.Result
+	var err error
+	if tx != nil {
+		result, err = tx.ExecContext(ctx, query, requesterID, targetID)
+	} else {
+		result, err = r.db.ExecContext(ctx, query, requesterID, targetID)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	inserted, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return inserted > 0, nil
+}
+
+func (r *followRequestRepository) Delete(ctx context.Context, tx *sql.Tx, requesterID string, targetID string) error {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+        DELETE FROM follow_requests
+        WHERE requester_id = $1 AND target_id = $2;
+    `
+
+	var err error
+	if tx != nil {
+		_, err = tx.ExecContext(ctx, query, requesterID, targetID)
+	} else {
+		_, err = r.db.ExecContext(ctx, query, requesterID, targetID)
+	}
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *followRequestRepository) Exists(ctx context.Context, requesterID string, targetID string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+        SELECT EXISTS (
+            SELECT 1
+            FROM follow_requests
+            WHERE requester_id = $1 AND target_id = $2
+        );
+    `
+
+	var exists bool
+	if err := r.db.QueryRowContext(ctx, query, requesterID, targetID).Scan(&exists); err != nil {
+		return false, err
+	}
+
+	return exists, nil
+}
+
+// FindIncoming returns pending requests sent to targetID, with the requester populated.
+func (r *followRequestRepository) FindIncoming(ctx context.Context, targetID string, limit int, offset int) ([]*models.FollowRequest, int, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	countQuery := `SELECT COUNT(*) FROM follow_requests WHERE target_id = $1`
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, countQuery, targetID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+        SELECT fr.requester_id, fr.target_id, fr.created_at,
+               u.id, u.username, u.email
+        FROM follow_requests fr
+        JOIN users u ON u.id = fr.requester_id
+        WHERE fr.target_id = $1
+        ORDER BY fr.created_at DESC
+        LIMIT $2 OFFSET $3;
+    `
+
+	rows, err := r.db.QueryContext(ctx, query, targetID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var requests []*models.FollowRequest
+	for rows.Next() {
+		var request models.FollowRequest
+		var requester models.User
+		err := rows.Scan(
+			&request.RequesterID,
+			&request.TargetID,
+			&request.CreatedAt,
+			&requester.ID,
+			&requester.Username,
+			&requester.Email,
+		)
+		if err != nil {
+			return nil, 0, err
+		}
+		request.Requester = &requester
+		requests = append(requests, &request)
+	}
+
+	return requests, total, rows.Err()
+}
+
+// FindOutgoing returns pending requests sent by requesterID, with the target populated.
+func (r *followRequestRepository) FindOutgoing(ctx context.Context, requesterID string, limit int, offset int) ([]*models.FollowRequest, int, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	countQuery := `SELECT COUNT(*) FROM follow_requests WHERE requester_id = $1`
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, countQuery, requesterID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+        SELECT fr.requester_id, fr.target_id, fr.created_at,
+               u.id, u.username, u.email
+        FROM follow_requests fr
+        JOIN users u ON u.id = fr.target_id
+        WHERE fr.requester_id = $1
+        ORDER BY fr.created_at DESC
+        LIMIT $2 OFFSET $3;
+    `
+
+	rows, err := r.db.QueryContext(ctx, query, requesterID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var requests []*models.FollowRequest
+	for rows.Next() {
+		var request models.FollowRequest
+		var target models.User
+		err := rows.Scan(
+			&request.RequesterID,
+			&request.TargetID,
+			&request.CreatedAt,
+			&target.ID,
+			&target.Username,
+			&target.Email,
+		)
+		if err != nil {
+			return nil, 0, err
+		}
+		request.Target = &target
+		requests = append(requests, &request)
+	}
+
+	return requests, total, rows.Err()
+}
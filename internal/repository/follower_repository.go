@@ -3,12 +3,15 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"github.com/lib/pq"
 	"github.com/wanafiq/feed-api/internal/constants"
 )
 
 type FollowerRepository interface {
-	Save(ctx context.Context, tx *sql.Tx, followerID string, followeeID string) error
+	Save(ctx context.Context, tx *sql.Tx, followerID string, followeeID string) (bool, error)
 	Delete(ctx context.Context, tx *sql.Tx, followerID string, followeeID string) error
+	Exists(ctx context.Context, followerID string, followeeID string) (bool, error)
+	FilterFollowers(ctx context.Context, followeeID string, userIDs []string) ([]string, error)
 }
 
 type followerRepository struct {
@@ -19,26 +22,34 @@ func NewFollowerRepository(db *sql.DB) FollowerRepository {
 	return &followerRepository{db: db}
 }
 
-func (r *followerRepository) Save(ctx context.Context, tx *sql.Tx, followerID string, followeeID string) error {
+// Save follows followeeID and reports whether followerID was not following yet.
+func (r *followerRepository) Save(ctx context.Context, tx *sql.Tx, followerID string, followeeID string) (bool, error) {
 	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
 	defer cancel()
 
 	query := `
         INSERT INTO followers (follower_id, followee_id)
-        VALUES ($1, $2);
+        VALUES ($1, $2)
+        ON CONFLICT (follower_id, followee_id) DO NOTHING;
     `
 
+	var result sql.Result
 	var err error
 	if tx != nil {
-		_, err = tx.ExecContext(ctx, query, followerID, followeeID)
+		result, err = tx.ExecContext(ctx, query, followerID, followeeID)
 	} else {
-		_, err = r.db.ExecContext(ctx, query, followerID, followeeID)
+		result, err = r.db.ExecContext(ctx, query, followerID, followeeID)
 	}
 	if err != nil {
-		return err
+		return false, err
 	}
 
-	return nil
+	inserted, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return inserted > 0, nil
 }
 
 func (r *followerRepository) Delete(ctx context.Context, tx *sql.Tx, followerID string, followeeID string) error {
@@ -62,3 +73,58 @@ func (r *followerRepository) Delete(ctx context.Context, tx *sql.Tx, followerID
 
 	return nil
 }
+
+func (r *followerRepository) Exists(ctx context.Context, followerID string, followeeID string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+        SELECT EXISTS (
+            SELECT 1
+            FROM followers
+            WHERE follower_id = $1 AND followee_id = $2
+        );
+    `
+
+	var exists bool
+	if err := r.db.QueryRowContext(ctx, query, followerID, followeeID).Scan(&exists); err != nil {
+		return false, err
+	}
+
+	return exists, nil
+}
+
+// FilterFollowers returns those of userIDs who follow followeeID and would see
+// their posts in the feed, leaving out followers who muted or blocked
+// followeeID, or were blocked by them.
+func (r *followerRepository) FilterFollowers(ctx context.Context, followeeID string, userIDs []string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+        SELECT f.follower_id
+        FROM followers f
+        WHERE f.followee_id = $1
+          AND f.follower_id = ANY($2)
+          AND f.follower_id NOT IN (SELECT m.muter_id FROM mutes m WHERE m.muted_id = $1)
+          AND f.follower_id NOT IN (SELECT b.blocker_id FROM blocks b WHERE b.blocked_id = $1)
+          AND f.follower_id NOT IN (SELECT b.blocked_id FROM blocks b WHERE b.blocker_id = $1);
+    `
+
+	rows, err := r.db.QueryContext(ctx, query, followeeID, pq.Array(userIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var followerIDs []string
+	for rows.Next() {
+		var followerID string
+		if err := rows.Scan(&followerID); err != nil {
+			return nil, err
+		}
+		followerIDs = append(followerIDs, followerID)
+	}
+
+	return followerIDs, rows.Err()
+}
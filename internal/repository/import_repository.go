@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/wanafiq/feed-api/internal/constants"
+)
+
+type ImportRepository interface {
+	Save(ctx context.Context, tx *sql.Tx, source string, sourceID string, postID string) error
+	FindPostID(ctx context.Context, source string, sourceID string) (string, error)
+}
+
+type importRepository struct {
+	db *sql.DB
+}
+
+func NewImportRepository(db *sql.DB) ImportRepository {
+	return &importRepository{db: db}
+}
+
+func (r *importRepository) Save(ctx context.Context, tx *sql.Tx, source string, sourceID string, postID string) error {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+        INSERT INTO imported_posts (source, source_id, post_id)
+        VALUES ($1, $2, $3);
+    `
+
+	var err error
+	if tx != nil {
+		_, err = tx.ExecContext(ctx, query, source, sourceID, postID)
+	} else {
+		_, err = r.db.ExecContext(ctx, query, source, sourceID, postID)
+	}
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// FindPostID returns the post created from an imported item, or sql.ErrNoRows
+// when it has not been imported yet.
+func (r *importRepository) FindPostID(ctx context.Context, source string, sourceID string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+        SELECT post_id
+        FROM imported_posts
+        WHERE source = $1 AND source_id = $2;
+    `
+
+	var postID string
+	if err := r.db.QueryRowContext(ctx, query, source, sourceID).Scan(&postID); err != nil {
+		return "", err
+	}
+
+	return postID, nil
+}
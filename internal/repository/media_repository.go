@@ -0,0 +1,239 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/wanafiq/feed-api/internal/constants"
+	"github.com/wanafiq/feed-api/internal/models"
+	"time"
+)
+
+type MediaRepository interface {
+	Save(ctx context.Context, tx *sql.Tx, media *models.Media) error
+	SumSizeByUserID(ctx context.Context, tx *sql.Tx, userID string) (int64, error)
+	SyncPostMedia(ctx context.Context, tx *sql.Tx, postID string, userID string, refs string) error
+	UnlinkPost(ctx context.Context, tx *sql.Tx, postID string) error
+	FindOrphans(ctx context.Context, before time.Time, limit int) ([]*models.Media, error)
+	Delete(ctx context.Context, tx *sql.Tx, mediaID string) error
+}
+
+type mediaRepository struct {
+	db *sql.DB
+}
+
+func NewMediaRepository(db *sql.DB) MediaRepository {
+	return &mediaRepository{db: db}
+}
+
+// Save stores a new upload, unlinked until a post references it.
+func (r *mediaRepository) Save(ctx context.Context, tx *sql.Tx, media *models.Media) error {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+        INSERT INTO media (user_id, storage_key, content_type, size, width, height, thumbnails, orphaned_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
+        RETURNING id, created_at;
+    `
+
+	args := []any{media.UserID, media.Key, media.ContentType, media.Size, media.Width, media.Height, media.Thumbnails}
+
+	var row *sql.Row
+	if tx != nil {
+		row = tx.QueryRowContext(ctx, query, args...)
+	} else {
+		row = r.db.QueryRowContext(ctx, query, args...)
+	}
+
+	return row.Scan(&media.ID, &media.CreatedAt)
+}
+
+// SumSizeByUserID returns the stored size of the user's uploads, thumbnails
+// included. It locks the user row first, so within tx concurrent uploads of the
+// same user are counted one after the other.
+func (r *mediaRepository) SumSizeByUserID(ctx context.Context, tx *sql.Tx, userID string) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	lockQuery := `SELECT id FROM users WHERE id = $1 FOR UPDATE`
+
+	query := `
+        SELECT COALESCE(SUM(
+            m.size + COALESCE((SELECT SUM((t ->> 'size')::bigint) FROM jsonb_array_elements(m.thumbnails) t), 0)
+        ), 0)
+        FROM media m
+        WHERE m.user_id = $1;
+    `
+
+	var id string
+	var size int64
+	if tx != nil {
+		if err := tx.QueryRowContext(ctx, lockQuery, userID).Scan(&id); err != nil {
+			return 0, err
+		}
+		if err := tx.QueryRowContext(ctx, query, userID).Scan(&size); err != nil {
+			return 0, err
+		}
+	} else {
+		if err := r.db.QueryRowContext(ctx, query, userID).Scan(&size); err != nil {
+			return 0, err
+		}
+	}
+
+	return size, nil
+}
+
+// SyncPostMedia links the user's uploads referenced in refs to the post, and
+// unlinks uploads of the post refs no longer references. Uploads are orphaned
+// when the last post referencing them lets go of them.
+func (r *mediaRepository) SyncPostMedia(ctx context.Context, tx *sql.Tx, postID string, userID string, refs string) error {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	unlinkQuery := `
+        WITH unlinked AS (
+            DELETE FROM post_media pm
+            USING media m
+            WHERE pm.media_id = m.id AND pm.post_id = $1 AND strpos($2, m.storage_key) = 0
+            RETURNING pm.media_id
+        )
+        UPDATE media
+        SET orphaned_at = NOW()
+        WHERE id IN (SELECT media_id FROM unlinked)
+          AND NOT EXISTS (SELECT 1 FROM post_media pm WHERE pm.media_id = media.id AND pm.post_id <> $1);
+    `
+
+	linkQuery := `
+        WITH linked AS (
+            INSERT INTO post_media (post_id, media_id)
+            SELECT $1, m.id
+            FROM media m
+            WHERE m.user_id = $2 AND strpos($3, m.storage_key) > 0
+            ON CONFLICT DO NOTHING
+            RETURNING media_id
+        )
+        UPDATE media
+        SET orphaned_at = NULL
+        WHERE id IN (SELECT media_id FROM linked);
+    `
+
+	var err error
+	if tx != nil {
+		_, err = tx.ExecContext(ctx, unlinkQuery, postID, refs)
+	} else {
+		_, err = r.db.ExecContext(ctx, unlinkQuery, postID, refs)
+	}
+	if err != nil {
+		return err
+	}
+
+	if tx != nil {
+		_, err = tx.ExecContext(ctx, linkQuery, postID, userID, refs)
+	} else {
+		_, err = r.db.ExecContext(ctx, linkQuery, postID, userID, refs)
+	}
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UnlinkPost unlinks the uploads of a post that is being deleted, orphaning
+// those no other post references.
+func (r *mediaRepository) UnlinkPost(ctx context.Context, tx *sql.Tx, postID string) error {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+        WITH unlinked AS (
+            DELETE FROM post_media
+            WHERE post_id = $1
+            RETURNING media_id
+        )
+        UPDATE media
+        SET orphaned_at = NOW()
+        WHERE id IN (SELECT media_id FROM unlinked)
+          AND NOT EXISTS (SELECT 1 FROM post_media pm WHERE pm.media_id = media.id AND pm.post_id <> $1);
+    `
+
+	var err error
+	if tx != nil {
+		_, err = tx.ExecContext(ctx, query, postID)
+	} else {
+		_, err = r.db.ExecContext(ctx, query, postID)
+	}
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// FindOrphans returns uploads that have not been referenced by any post since before.
+func (r *mediaRepository) FindOrphans(ctx context.Context, before time.Time, limit int) ([]*models.Media, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+        SELECT id, user_id, storage_key, content_type, size, width, height, thumbnails, orphaned_at, created_at
+        FROM media
+        WHERE orphaned_at < $1
+        ORDER BY orphaned_at
+        LIMIT $2;
+    `
+
+	rows, err := r.db.QueryContext(ctx, query, before, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var orphans []*models.Media
+	for rows.Next() {
+		var media models.Media
+		err := rows.Scan(
+			&media.ID,
+			&media.UserID,
+			&media.Key,
+			&media.ContentType,
+			&media.Size,
+			&media.Width,
+			&media.Height,
+			&media.Thumbnails,
+			&media.OrphanedAt,
+			&media.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		orphans = append(orphans, &media)
+	}
+
+	return orphans, rows.Err()
+}
+
+// Delete removes an upload if it is still orphaned, and returns sql.ErrNoRows
+// when a post has linked it since it was found. Only then may its files go.
+func (r *mediaRepository) Delete(ctx context.Context, tx *sql.Tx, mediaID string) error {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+        DELETE FROM media
+        WHERE id = $1
+          AND orphaned_at IS NOT NULL
+          AND NOT EXISTS (SELECT 1 FROM post_media pm WHERE pm.media_id = $1)
+        RETURNING id;
+    `
+
+	var row *sql.Row
+	if tx != nil {
+		row = tx.QueryRowContext(ctx, query, mediaID)
+	} else {
+		row = r.db.QueryRowContext(ctx, query, mediaID)
+	}
+
+	var id string
+	return row.Scan(&id)
+}
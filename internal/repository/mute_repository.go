@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/wanafiq/feed-api/internal/constants"
+	"github.com/wanafiq/feed-api/internal/models"
+)
+
+type MuteRepository interface {
+	Save(ctx context.Context, tx *sql.Tx, muterID string, mutedID string) error
+	Delete(ctx context.Context, tx *sql.Tx, muterID string, mutedID string) error
+	FindMutedUsers(ctx context.Context, muterID string, limit int, offset int) ([]*models.User, int, error)
+}
+
+type muteRepository struct {
+	db *sql.DB
+}
+
+func NewMuteRepository(db *sql.DB) MuteRepository {
+	return &muteRepository{db: db}
+}
+
+func (r *muteRepository) Save(ctx context.Context, tx *sql.Tx, muterID string, mutedID string) error {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+        INSERT INTO mutes (muter_id, muted_id)
+        VALUES ($1, $2)
+        ON CONFLICT (muter_id, muted_id) DO NOTHING;
+    `
+
+	var err error
+	if tx != nil {
+		_, err = tx.ExecContext(ctx, query, muterID, mutedID)
+	} else {
+		_, err = r.db.ExecContext(ctx, query, muterID, mutedID)
+	}
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *muteRepository) Delete(ctx context.Context, tx *sql.Tx, muterID string, mutedID string) error {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+        DELETE FROM mutes
+        WHERE muter_id = $1 AND muted_id = $2;
+    `
+
+	var err error
+	if tx != nil {
+		_, err = tx.ExecContext(ctx, query, muterID, mutedID)
+	} else {
+		_, err = r.db.ExecContext(ctx, query, muterID, mutedID)
+	}
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *muteRepository) FindMutedUsers(ctx context.Context, muterID string, limit int, offset int) ([]*models.User, int, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	countQuery := `SELECT COUNT(*) FROM mutes WHERE muter_id = $1`
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, countQuery, muterID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+        SELECT u.id, u.username, u.email, u.is_active, u.created_at
+        FROM mutes m
+        JOIN users u ON u.id = m.muted_id
+        WHERE m.muter_id = $1
+        ORDER BY m.created_at DESC
+        LIMIT $2 OFFSET $3;
+    `
+
+	rows, err := r.db.QueryContext(ctx, query, muterID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var users []*models.User
+	for rows.Next() {
+		var user models.User
+		err := rows.Scan(&user.ID, &user.Username, &user.Email, &user.IsActive, &user.CreatedAt)
+		if err != nil {
+			return nil, 0, err
+		}
+		users = append(users, &user)
+	}
+
+	return users, total, rows.Err()
+}
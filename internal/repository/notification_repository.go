@@ -0,0 +1,256 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/lib/pq"
+	"github.com/wanafiq/feed-api/internal/constants"
+	"github.com/wanafiq/feed-api/internal/models"
+)
+
+type NotificationRepository interface {
+	Save(ctx context.Context, userID string, notificationType string, actorID string, postID string, reaction string) (int64, error)
+	FindAll(ctx context.Context, userID string, filter models.NotificationFilter) ([]*models.Notification, int, int, error)
+	MarkRead(ctx context.Context, userID string, notificationID int64) error
+	MarkAllRead(ctx context.Context, userID string) error
+	FindPreferences(ctx context.Context, userID string) (map[string]bool, error)
+	SavePreference(ctx context.Context, tx *sql.Tx, userID string, notificationType string, enabled bool) error
+}
+
+type notificationRepository struct {
+	db *sql.DB
+}
+
+func NewNotificationRepository(db *sql.DB) NotificationRepository {
+	return &notificationRepository{db: db}
+}
+
+// Save notifies userID, unless they opted out of notificationType, blocked or
+// muted the actor, or were already notified of the same thing, in which case it
+// returns sql.ErrNoRows. postID and reaction may be empty. Every comment is
+// notified, there is no such thing as the same comment twice.
+func (r *notificationRepository) Save(ctx context.Context, userID string, notificationType string, actorID string, postID string, reaction string) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+        INSERT INTO notifications (user_id, type, actor_id, post_id, reaction)
+        SELECT $1::uuid, $2, $3::uuid, NULLIF($4, '')::uuid, $5
+        WHERE NOT EXISTS (
+            SELECT 1 FROM notification_preferences
+            WHERE user_id = $1 AND type = $2 AND NOT enabled
+        )
+        AND NOT EXISTS (
+            SELECT 1 FROM blocks
+            WHERE (blocker_id = $1 AND blocked_id = $3) OR (blocker_id = $3 AND blocked_id = $1)
+        )
+        AND NOT EXISTS (
+            SELECT 1 FROM mutes
+            WHERE muter_id = $1 AND muted_id = $3
+        )
+        ON CONFLICT (user_id, type, actor_id, post_id, reaction) DO NOTHING
+        RETURNING id;
+    `
+
+	var id int64
+	if err := r.db.QueryRowContext(ctx, query, userID, notificationType, actorID, postID, reaction).Scan(&id); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// FindAll returns the notifications of userID grouped by type, post and reaction,
+// read and unread ones apart, newest first. It also returns the number of groups
+// matching the filter and the number of unread groups.
+func (r *notificationRepository) FindAll(ctx context.Context, userID string, filter models.NotificationFilter) ([]*models.Notification, int, int, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	countQuery := `
+        SELECT COUNT(*) FILTER (WHERE NOT $2 OR unread),
+               COUNT(*) FILTER (WHERE unread)
+        FROM (
+            SELECT read_at IS NULL AS unread
+            FROM notifications
+            WHERE user_id = $1
+              AND ($3::timestamptz IS NULL OR created_at > $3)
+              AND ($4::timestamptz IS NULL OR created_at <= $4)
+            GROUP BY type, post_id, reaction, read_at IS NULL
+        ) g;
+    `
+
+	var total, unread int
+	err := r.db.QueryRowContext(ctx, countQuery, userID, filter.UnreadOnly, filter.Since, filter.Until).Scan(&total, &unread)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	query := `
+        SELECT g.id, g.type, g.post_id, p.title, g.reaction, g.actor_count, g.unread, g.created_at,
+               g.actor_ids[1:3], g.actor_usernames[1:3]
+        FROM (
+            SELECT MAX(n.id) AS id,
+                   n.type,
+                   n.post_id,
+                   n.reaction,
+                   COUNT(*) AS actor_count,
+                   n.read_at IS NULL AS unread,
+                   MAX(n.created_at) AS created_at,
+                   array_agg(u.id ORDER BY n.created_at DESC, n.id DESC) AS actor_ids,
+                   array_agg(u.username ORDER BY n.created_at DESC, n.id DESC) AS actor_usernames
+            FROM notifications n
+            JOIN users u ON u.id = n.actor_id
+            WHERE n.user_id = $1
+              AND ($5::timestamptz IS NULL OR n.created_at > $5)
+              AND ($6::timestamptz IS NULL OR n.created_at <= $6)
+            GROUP BY n.type, n.post_id, n.reaction, n.read_at IS NULL
+        ) g
+        LEFT JOIN posts p ON p.id = g.post_id
+        WHERE NOT $2 OR g.unread
+        ORDER BY g.created_at DESC, g.id DESC
+        OFFSET $3 LIMIT $4;
+    `
+
+	rows, err := r.db.QueryContext(ctx, query, userID, filter.UnreadOnly, filter.Offset, filter.Limit, filter.Since, filter.Until)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	defer rows.Close()
+
+	var notifications []*models.Notification
+	for rows.Next() {
+		var notification models.Notification
+		var isUnread bool
+		var actorIDs, actorUsernames []string
+		err := rows.Scan(
+			&notification.ID,
+			&notification.Type,
+			&notification.PostID,
+			&notification.PostTitle,
+			&notification.Reaction,
+			&notification.ActorCount,
+			&isUnread,
+			&notification.CreatedAt,
+			pq.Array(&actorIDs),
+			pq.Array(&actorUsernames),
+		)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+
+		notification.IsRead = !isUnread
+		for i := range actorIDs {
+			notification.Actors = append(notification.Actors, models.User{ID: actorIDs[i], Username: actorUsernames[i]})
+		}
+		notifications = append(notifications, &notification)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, 0, err
+	}
+
+	return notifications, total, unread, nil
+}
+
+// MarkRead marks the unread group of notificationID as read. It returns
+// sql.ErrNoRows when userID has no such notification.
+func (r *notificationRepository) MarkRead(ctx context.Context, userID string, notificationID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+        WITH target AS (
+            SELECT type, post_id, reaction, read_at
+            FROM notifications
+            WHERE id = $2 AND user_id = $1
+        ), updated AS (
+            UPDATE notifications n
+            SET read_at = NOW()
+            FROM target t
+            WHERE n.user_id = $1 AND n.read_at IS NULL AND t.read_at IS NULL
+              AND n.type = t.type AND n.post_id IS NOT DISTINCT FROM t.post_id AND n.reaction = t.reaction
+        )
+        SELECT COUNT(*) FROM target;
+    `
+
+	var found int
+	if err := r.db.QueryRowContext(ctx, query, userID, notificationID).Scan(&found); err != nil {
+		return err
+	}
+	if found == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (r *notificationRepository) MarkAllRead(ctx context.Context, userID string) error {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+        UPDATE notifications
+        SET read_at = NOW()
+        WHERE user_id = $1 AND read_at IS NULL;
+    `
+
+	_, err := r.db.ExecContext(ctx, query, userID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// FindPreferences returns the notification types userID set a preference for.
+func (r *notificationRepository) FindPreferences(ctx context.Context, userID string) (map[string]bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+        SELECT type, enabled
+        FROM notification_preferences
+        WHERE user_id = $1;
+    `
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	preferences := make(map[string]bool)
+	for rows.Next() {
+		var notificationType string
+		var enabled bool
+		if err := rows.Scan(&notificationType, &enabled); err != nil {
+			return nil, err
+		}
+		preferences[notificationType] = enabled
+	}
+
+	return preferences, rows.Err()
+}
+
+func (r *notificationRepository) SavePreference(ctx context.Context, tx *sql.Tx, userID string, notificationType string, enabled bool) error {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+        INSERT INTO notification_preferences (user_id, type, enabled)
+        VALUES ($1, $2, $3)
+        ON CONFLICT (user_id, type) DO UPDATE SET enabled = EXCLUDED.enabled, updated_at = NOW();
+    `
+
+	var err error
+	if tx != nil {
+		_, err = tx.ExecContext(ctx, query, userID, notificationType, enabled)
+	} else {
+		_, err = r.db.ExecContext(ctx, query, userID, notificationType, enabled)
+	}
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
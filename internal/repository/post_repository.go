@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"github.com/lib/pq"
 	"github.com/wanafiq/feed-api/internal/constants"
 	"github.com/wanafiq/feed-api/internal/models"
 	"strings"
@@ -13,7 +14,9 @@ import (
 type PostRepository interface {
 	Save(ctx context.Context, tx *sql.Tx, post *models.Post) error
 	FindAll(ctx context.Context, filter models.PostFilter) ([]*models.Post, int, error)
+	FindLastModified(ctx context.Context, filter models.PostFilter) (int, *time.Time, error)
 	FindByID(ctx context.Context, postID string) (*models.Post, error)
+	FindByAuthorID(ctx context.Context, authorID string) ([]*models.Post, error)
 	Update(ctx context.Context, tx *sql.Tx, post *models.Post) (*models.Post, error)
 	Delete(ctx context.Context, tx *sql.Tx, postID string) error
 
@@ -22,6 +25,13 @@ type PostRepository interface {
 
 	SavePostUser(ctx context.Context, tx *sql.Tx, postID string, userID string) error
 	DeletePostUser(ctx context.Context, tx *sql.Tx, postID string) error
+
+	SaveCoAuthor(ctx context.Context, tx *sql.Tx, postID string, userID string) error
+	UpdatePostUserStatus(ctx context.Context, tx *sql.Tx, postID string, userID string, status string) error
+	DeleteCoAuthor(ctx context.Context, tx *sql.Tx, postID string, userID string) error
+	FindPostUser(ctx context.Context, postID string, userID string) (*models.PostUser, error)
+	FindAuthorsByPostIDs(ctx context.Context, postIDs []string) (map[string][]models.User, error)
+	FindPendingCoAuthorInvitations(ctx context.Context, userID string) ([]*models.PostUser, error)
 }
 
 type postRepository struct {
@@ -39,8 +49,10 @@ func (r *postRepository) Save(ctx context.Context, tx *sql.Tx, post *models.Post
 	defer cancel()
 
 	query := `
-        INSERT INTO posts (title, slug, content, is_published, published_at, created_at, created_by, author_id)
-        VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+        INSERT INTO posts (title, slug, content, is_published, published_at, created_at, created_by, author_id, visibility,
+                           excerpt, word_count, reading_time, toc,
+                           cover_image, meta_description, canonical_url, og_title, og_description, og_image, twitter_card)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20)
         RETURNING id;
     `
 
@@ -56,6 +68,18 @@ func (r *postRepository) Save(ctx context.Context, tx *sql.Tx, post *models.Post
 			post.CreatedAt,
 			post.CreatedBy,
 			post.AuthorID,
+			post.Visibility,
+			post.Excerpt,
+			post.WordCount,
+			post.ReadingTime,
+			post.TOC,
+			post.CoverImage,
+			post.MetaDescription,
+			post.CanonicalURL,
+			post.OGTitle,
+			post.OGDescription,
+			post.OGImage,
+			post.TwitterCard,
 		)
 	} else {
 		row = r.db.QueryRowContext(ctx, query,
@@ -67,6 +91,18 @@ func (r *postRepository) Save(ctx context.Context, tx *sql.Tx, post *models.Post
 			post.CreatedAt,
 			post.CreatedBy,
 			post.AuthorID,
+			post.Visibility,
+			post.Excerpt,
+			post.WordCount,
+			post.ReadingTime,
+			post.TOC,
+			post.CoverImage,
+			post.MetaDescription,
+			post.CanonicalURL,
+			post.OGTitle,
+			post.OGDescription,
+			post.OGImage,
+			post.TwitterCard,
 		)
 	}
 
@@ -160,6 +196,11 @@ func (r *postRepository) FindAll(ctx context.Context, filter models.PostFilter)
 			&post.UpdatedAt,
 			&post.UpdatedBy,
 			&post.AuthorID,
+			&post.Visibility,
+			&post.Excerpt,
+			&post.WordCount,
+			&post.ReadingTime,
+			&post.CoverImage,
 			&post.Author.ID,
 			&post.Author.Username,
 			&post.Author.Email,
@@ -173,6 +214,87 @@ func (r *postRepository) FindAll(ctx context.Context, filter models.PostFilter)
 	return posts, total, rows.Err()
 }
 
+// FindLastModified counts the posts matching filter and returns when the latest
+// of them was published or edited, without loading them.
+func (r *postRepository) FindLastModified(ctx context.Context, filter models.PostFilter) (int, *time.Time, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	whereClause, args, _ := buildPostWhere(filter)
+
+	query := `
+		SELECT COUNT(*), MAX(COALESCE(p.updated_at, p.published_at, p.created_at))
+		FROM posts p
+		JOIN users u ON u.id = p.author_id
+		WHERE ` + whereClause
+
+	var count int
+	var lastModified *time.Time
+	if err := r.db.QueryRowContext(ctx, query, args...).Scan(&count, &lastModified); err != nil {
+		return 0, nil, err
+	}
+
+	return count, lastModified, nil
+}
+
+// FindByAuthorID returns every post of its primary author, drafts included, with
+// content and tags, oldest first.
+func (r *postRepository) FindByAuthorID(ctx context.Context, authorID string) ([]*models.Post, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+		SELECT
+			p.id, p.title, p.slug, p.content, p.is_published, p.published_at,
+			p.created_at, p.created_by, p.updated_at, p.updated_by, p.author_id, p.visibility,
+			COALESCE((
+				SELECT array_agg(t.name ORDER BY t.name)
+				FROM post_tag pt
+				JOIN tags t ON t.id = pt.tag_id
+				WHERE pt.post_id = p.id
+			), '{}')
+		FROM posts p
+		WHERE p.author_id = $1
+		ORDER BY p.created_at, p.id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, authorID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var posts []*models.Post
+	for rows.Next() {
+		var post models.Post
+		var tags []string
+		err := rows.Scan(
+			&post.ID,
+			&post.Title,
+			&post.Slug,
+			&post.Content,
+			&post.IsPublished,
+			&post.PublishedAt,
+			&post.CreatedAt,
+			&post.CreatedBy,
+			&post.UpdatedAt,
+			&post.UpdatedBy,
+			&post.AuthorID,
+			&post.Visibility,
+			pq.Array(&tags),
+		)
+		if err != nil {
+			return nil, err
+		}
+		for _, tag := range tags {
+			post.Tags = append(post.Tags, models.Tag{Name: tag})
+		}
+		posts = append(posts, &post)
+	}
+
+	return posts, rows.Err()
+}
+
 func (r *postRepository) FindByID(ctx context.Context, postID string) (*models.Post, error) {
 	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
 	defer cancel()
@@ -180,8 +302,10 @@ func (r *postRepository) FindByID(ctx context.Context, postID string) (*models.P
 	query := `
 		SELECT
 			p.id, p.title, p.slug, p.content, p.is_published, p.published_at,
-			p.created_at, p.created_by, p.updated_at, p.updated_by, p.author_id,
-			u.id as author_id, u.username, u.email as author_email,
+			p.created_at, p.created_by, p.updated_at, p.updated_by, p.author_id, p.visibility,
+			p.excerpt, p.word_count, p.reading_time, p.toc,
+			p.cover_image, p.meta_description, p.canonical_url, p.og_title, p.og_description, p.og_image, p.twitter_card,
+			u.id as author_id, u.username, u.email as author_email, u.is_private as author_is_private,
 			r.id as role_id, r.name as role_name, r.level as role_level, r.description as role_description, 
 			r.is_active as role_is_active, r.created_at as role_created_at, r.created_by as role_created_by, 
 			r.updated_at as role_updated_at, r.updated_by as role_updated_by
@@ -198,8 +322,10 @@ func (r *postRepository) FindByID(ctx context.Context, postID string) (*models.P
 	var role models.Role
 	err := row.Scan(
 		&post.ID, &post.Title, &post.Slug, &post.Content, &post.IsPublished,
-		&post.PublishedAt, &post.CreatedAt, &post.CreatedBy, &post.UpdatedAt, &post.UpdatedBy, &post.AuthorID,
-		&author.ID, &author.Username, &author.Email,
+		&post.PublishedAt, &post.CreatedAt, &post.CreatedBy, &post.UpdatedAt, &post.UpdatedBy, &post.AuthorID, &post.Visibility,
+		&post.Excerpt, &post.WordCount, &post.ReadingTime, &post.TOC,
+		&post.CoverImage, &post.MetaDescription, &post.CanonicalURL, &post.OGTitle, &post.OGDescription, &post.OGImage, &post.TwitterCard,
+		&author.ID, &author.Username, &author.Email, &author.IsPrivate,
 		&role.ID, &role.Name, &role.Level, &role.Description, &role.IsActive, &role.CreatedAt, &role.CreatedBy, &role.UpdatedAt, &role.UpdatedBy,
 	)
 
@@ -226,9 +352,23 @@ func (r *postRepository) Update(ctx context.Context, tx *sql.Tx, post *models.Po
 			is_published = $5,
 			published_at = $6,
 			updated_at = $7,
-			updated_by = $8
-		WHERE id = $9
-		RETURNING id, author_id, title, slug, content, is_published, published_at, created_at, created_by, updated_at, updated_by;
+			updated_by = $8,
+			visibility = $9,
+			excerpt = $10,
+			word_count = $11,
+			reading_time = $12,
+			toc = $13,
+			cover_image = $14,
+			meta_description = $15,
+			canonical_url = $16,
+			og_title = $17,
+			og_description = $18,
+			og_image = $19,
+			twitter_card = $20
+		WHERE id = $21
+		RETURNING id, author_id, title, slug, content, is_published, published_at, created_at, created_by, updated_at, updated_by, visibility,
+			excerpt, word_count, reading_time, toc,
+			cover_image, meta_description, canonical_url, og_title, og_description, og_image, twitter_card;
 	`
 
 	updatedPost := &models.Post{}
@@ -246,6 +386,18 @@ func (r *postRepository) Update(ctx context.Context, tx *sql.Tx, post *models.Po
 			post.PublishedAt,
 			time.Now().UTC(),
 			post.UpdatedBy,
+			post.Visibility,
+			post.Excerpt,
+			post.WordCount,
+			post.ReadingTime,
+			post.TOC,
+			post.CoverImage,
+			post.MetaDescription,
+			post.CanonicalURL,
+			post.OGTitle,
+			post.OGDescription,
+			post.OGImage,
+			post.TwitterCard,
 			post.ID,
 		)
 	} else {
@@ -260,6 +412,18 @@ func (r *postRepository) Update(ctx context.Context, tx *sql.Tx, post *models.Po
 			post.PublishedAt,
 			time.Now().UTC(),
 			post.UpdatedBy,
+			post.Visibility,
+			post.Excerpt,
+			post.WordCount,
+			post.ReadingTime,
+			post.TOC,
+			post.CoverImage,
+			post.MetaDescription,
+			post.CanonicalURL,
+			post.OGTitle,
+			post.OGDescription,
+			post.OGImage,
+			post.TwitterCard,
 			post.ID,
 		)
 	}
@@ -267,7 +431,10 @@ func (r *postRepository) Update(ctx context.Context, tx *sql.Tx, post *models.Po
 	err := row.Scan(
 		&updatedPost.ID, &updatedPost.AuthorID, &updatedPost.Title, &updatedPost.Slug, &updatedPost.Content,
 		&updatedPost.IsPublished, &updatedPost.PublishedAt, &updatedPost.CreatedAt, &updatedPost.CreatedBy,
-		&updatedPost.UpdatedAt, &updatedPost.UpdatedBy,
+		&updatedPost.UpdatedAt, &updatedPost.UpdatedBy, &updatedPost.Visibility,
+		&updatedPost.Excerpt, &updatedPost.WordCount, &updatedPost.ReadingTime, &updatedPost.TOC,
+		&updatedPost.CoverImage, &updatedPost.MetaDescription, &updatedPost.CanonicalURL, &updatedPost.OGTitle,
+		&updatedPost.OGDescription, &updatedPost.OGImage, &updatedPost.TwitterCard,
 	)
 
 	if err != nil {
@@ -347,7 +514,233 @@ func (r *postRepository) DeletePostUser(ctx context.Context, tx *sql.Tx, postID
 	return nil
 }
 
+func (r *postRepository) SaveCoAuthor(ctx context.Context, tx *sql.Tx, postID string, userID string) error {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+        INSERT INTO post_user (post_id, user_id, role, status)
+        VALUES ($1, $2, $3, $4)
+        ON CONFLICT (post_id, user_id) DO NOTHING;
+    `
+
+	var err error
+	if tx != nil {
+		_, err = tx.ExecContext(ctx, query, postID, userID, constants.PostUserRoleCoAuthor, constants.PostUserStatusPending)
+	} else {
+		_, err = r.db.ExecContext(ctx, query, postID, userID, constants.PostUserRoleCoAuthor, constants.PostUserStatusPending)
+	}
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *postRepository) UpdatePostUserStatus(ctx context.Context, tx *sql.Tx, postID string, userID string, status string) error {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+		UPDATE post_user
+		SET status = $1
+		WHERE post_id = $2 AND user_id = $3
+	`
+
+	var err error
+	if tx != nil {
+		_, err = tx.ExecContext(ctx, query, status, postID, userID)
+	} else {
+		_, err = r.db.ExecContext(ctx, query, status, postID, userID)
+	}
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteCoAuthor removes a co-author or a pending invitation. The author row is never removed.
+func (r *postRepository) DeleteCoAuthor(ctx context.Context, tx *sql.Tx, postID string, userID string) error {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+		DELETE FROM post_user
+		WHERE post_id = $1 AND user_id = $2 AND role = $3
+	`
+
+	var err error
+	if tx != nil {
+		_, err = tx.ExecContext(ctx, query, postID, userID, constants.PostUserRoleCoAuthor)
+	} else {
+		_, err = r.db.ExecContext(ctx, query, postID, userID, constants.PostUserRoleCoAuthor)
+	}
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *postRepository) FindPostUser(ctx context.Context, postID string, userID string) (*models.PostUser, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+		SELECT post_id, user_id, role, status, created_at
+		FROM post_user
+		WHERE post_id = $1 AND user_id = $2
+	`
+
+	postUser := &models.PostUser{}
+	err := r.db.QueryRowContext(ctx, query, postID, userID).Scan(
+		&postUser.PostID,
+		&postUser.UserID,
+		&postUser.Role,
+		&postUser.Status,
+		&postUser.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return postUser, nil
+}
+
+// FindAuthorsByPostIDs returns the author and accepted co-authors of each post, author first.
+func (r *postRepository) FindAuthorsByPostIDs(ctx context.Context, postIDs []string) (map[string][]models.User, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	authors := make(map[string][]models.User)
+	if len(postIDs) == 0 {
+		return authors, nil
+	}
+
+	query := `
+		SELECT pu.post_id, u.id, u.username
+		FROM post_user pu
+		JOIN users u ON u.id = pu.user_id
+		WHERE pu.post_id = ANY($1) AND pu.status = $2
+		ORDER BY pu.post_id, pu.role = $3 DESC, pu.created_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(postIDs), constants.PostUserStatusAccepted, constants.PostUserRoleAuthor)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var postID string
+		var user models.User
+		if err := rows.Scan(&postID, &user.ID, &user.Username); err != nil {
+			return nil, err
+		}
+		authors[postID] = append(authors[postID], user)
+	}
+
+	return authors, rows.Err()
+}
+
+func (r *postRepository) FindPendingCoAuthorInvitations(ctx context.Context, userID string) ([]*models.PostUser, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+		SELECT pu.post_id, pu.user_id, pu.role, pu.status, pu.created_at,
+		       p.id, p.title, p.slug, p.author_id, u.id, u.username
+		FROM post_user pu
+		JOIN posts p ON p.id = pu.post_id
+		JOIN users u ON u.id = p.author_id
+		WHERE pu.user_id = $1 AND pu.status = $2
+		ORDER BY pu.created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, constants.PostUserStatusPending)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var invitations []*models.PostUser
+	for rows.Next() {
+		var invitation models.PostUser
+		var post models.Post
+		err := rows.Scan(
+			&invitation.PostID,
+			&invitation.UserID,
+			&invitation.Role,
+			&invitation.Status,
+			&invitation.CreatedAt,
+			&post.ID,
+			&post.Title,
+			&post.Slug,
+			&post.AuthorID,
+			&post.Author.ID,
+			&post.Author.Username,
+		)
+		if err != nil {
+			return nil, err
+		}
+		invitation.Post = &post
+		invitations = append(invitations, &invitation)
+	}
+
+	return invitations, rows.Err()
+}
+
 func buildPostQuery(filter models.PostFilter) (query string, countQuery string, queryArgs []any, countArgs []any) {
+	whereClause, baseArgs, argID := buildPostWhere(filter)
+
+	// Listings return the excerpt only, unless the full content is asked for
+	contentField := "''"
+	if filter.Content {
+		contentField = "p.content"
+	}
+
+	// Select with JOIN on users table
+	selectFields := `
+		SELECT 
+			p.id, p.title, p.slug, ` + contentField + `, p.is_published, p.published_at,
+			p.created_at, p.created_by, p.updated_at, p.updated_by, p.author_id, p.visibility,
+			p.excerpt, p.word_count, p.reading_time, p.cover_image,
+			u.id, u.username, u.email
+		FROM posts p
+		JOIN users u ON u.id = p.author_id
+		WHERE ` + whereClause
+
+	// Count query doesn't need user fields, but filters on the author's privacy
+	countQuery = `SELECT COUNT(*) FROM posts p JOIN users u ON u.id = p.author_id WHERE ` + whereClause
+	countArgs = append(countArgs, baseArgs...)
+
+	// Sorting
+	sortField := "p.created_at"
+	if filter.Published {
+		sortField = "COALESCE(p.published_at, p.created_at)"
+	}
+	order := sortField + " DESC"
+	if strings.ToLower(filter.Sort) == "asc" {
+		order = sortField + " ASC"
+	}
+
+	// Pagination placeholders
+	limitPlaceholder := fmt.Sprintf("$%d", argID)
+	offsetPlaceholder := fmt.Sprintf("$%d", argID+1)
+	queryArgs = append(queryArgs, baseArgs...)
+	queryArgs = append(queryArgs, filter.Limit, filter.Offset)
+
+	// Final query
+	query = fmt.Sprintf(`%s ORDER BY %s LIMIT %s OFFSET %s`, selectFields, order, limitPlaceholder, offsetPlaceholder)
+
+	return query, countQuery, queryArgs, countArgs
+}
+
+// buildPostWhere builds the WHERE clause of the post listings matching filter,
+// with posts aliased as p and authors as u. It returns the clause, its args and
+// the next placeholder number.
+func buildPostWhere(filter models.PostFilter) (string, []any, int) {
 	var baseArgs []any
 	argID := 1
 	where := []string{"1=1"}
@@ -393,37 +786,84 @@ func buildPostQuery(filter models.PostFilter) (query string, countQuery string,
 		`, strings.Join(tagPlaceholders, ", "), len(filter.Tags)))
 	}
 
-	// WHERE clause
-	whereClause := strings.Join(where, " AND ")
-
-	// Select with JOIN on users table
-	selectFields := `
-		SELECT 
-			p.id, p.title, p.slug, p.content, p.is_published, p.published_at,
-			p.created_at, p.created_by, p.updated_at, p.updated_by, p.author_id,
-			u.id, u.username, u.email
-		FROM posts p
-		JOIN users u ON u.id = p.author_id
-		WHERE ` + whereClause
+	// Posts written or co-written by a user
+	if filter.AuthorID != "" {
+		where = append(where, fmt.Sprintf(
+			"p.id IN (SELECT pu.post_id FROM post_user pu WHERE pu.user_id = $%d AND pu.status = '%s')",
+			argID, constants.PostUserStatusAccepted,
+		))
+		baseArgs = append(baseArgs, filter.AuthorID)
+		argID++
+	}
 
-	// Count query doesn't need user fields
-	countQuery = `SELECT COUNT(*) FROM posts p WHERE ` + whereClause
-	countArgs = append(countArgs, baseArgs...)
+	// Published posts, leaving out those scheduled in the future
+	if filter.Published {
+		where = append(where, "p.is_published AND (p.published_at IS NULL OR p.published_at <= NOW())")
+	}
 
-	// Sorting
-	order := "p.created_at DESC"
-	if strings.ToLower(filter.Sort) == "asc" {
-		order = "p.created_at ASC"
+	// Hide posts from users the viewer has blocked or muted, and from users who blocked the viewer
+	if filter.ViewerID != "" {
+		where = append(where, postBlockClause(argID), fmt.Sprintf(
+			"p.author_id NOT IN (SELECT m.muted_id FROM mutes m WHERE m.muter_id = $%d)", argID,
+		))
+		baseArgs = append(baseArgs, filter.ViewerID)
+		argID++
 	}
 
-	// Pagination placeholders
-	limitPlaceholder := fmt.Sprintf("$%d", argID)
-	offsetPlaceholder := fmt.Sprintf("$%d", argID+1)
-	queryArgs = append(queryArgs, baseArgs...)
-	queryArgs = append(queryArgs, filter.Limit, filter.Offset)
+	// Visibility
+	visibilityClause, visibilityArgs := postVisibilityClause(filter.ViewerID, argID)
+	where = append(where, visibilityClause)
+	baseArgs = append(baseArgs, visibilityArgs...)
+	argID += len(visibilityArgs)
 
-	// Final query
-	query = fmt.Sprintf(`%s ORDER BY %s LIMIT %s OFFSET %s`, selectFields, order, limitPlaceholder, offsetPlaceholder)
+	return strings.Join(where, " AND "), baseArgs, argID
+}
 
-	return query, countQuery, queryArgs, countArgs
+// postBlockClause excludes posts by authors who blocked, or were blocked by, the
+// viewer bound to $argID.
+func postBlockClause(argID int) string {
+	return fmt.Sprintf(`
+		p.author_id NOT IN (SELECT b.blocked_id FROM blocks b WHERE b.blocker_id = $%d)
+		AND p.author_id NOT IN (SELECT b.blocker_id FROM blocks b WHERE b.blocked_id = $%d)
+	`, argID, argID)
+}
+
+// postViewableClause is postVisibilityClause that also lets through unlisted
+// posts, for listings of posts the viewer already holds a link to.
+func postViewableClause(viewerID string, argID int) (string, []any) {
+	clause := fmt.Sprintf(`
+		(
+			p.id IN (SELECT pu.post_id FROM post_user pu WHERE pu.user_id = $%d AND pu.status = '%s')
+			OR (p.visibility IN ('%s', '%s') AND u.is_private = FALSE)
+			OR (
+				p.visibility IN ('%s', '%s', '%s')
+				AND p.author_id IN (SELECT f.followee_id FROM followers f WHERE f.follower_id = $%d)
+			)
+		)
+	`, argID, constants.PostUserStatusAccepted, constants.PostVisibilityPublic, constants.PostVisibilityUnlisted,
+		constants.PostVisibilityPublic, constants.PostVisibilityUnlisted, constants.PostVisibilityFollowers, argID)
+
+	return clause, []any{viewerID}
+}
+
+// postVisibilityClause restricts listings to posts the viewer may see, with posts
+// aliased as p and authors as u. Unlisted and private posts are only listed to their authors.
+func postVisibilityClause(viewerID string, argID int) (string, []any) {
+	if viewerID == "" {
+		return fmt.Sprintf("(p.visibility = '%s' AND u.is_private = FALSE)", constants.PostVisibilityPublic), nil
+	}
+
+	clause := fmt.Sprintf(`
+		(
+			p.id IN (SELECT pu.post_id FROM post_user pu WHERE pu.user_id = $%d AND pu.status = '%s')
+			OR (p.visibility = '%s' AND u.is_private = FALSE)
+			OR (
+				p.visibility IN ('%s', '%s')
+				AND p.author_id IN (SELECT f.followee_id FROM followers f WHERE f.follower_id = $%d)
+			)
+		)
+	`, argID, constants.PostUserStatusAccepted, constants.PostVisibilityPublic,
+		constants.PostVisibilityPublic, constants.PostVisibilityFollowers, argID)
+
+	return clause, []any{viewerID}
 }
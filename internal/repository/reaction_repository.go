@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/lib/pq"
+	"github.com/wanafiq/feed-api/internal/constants"
+)
+
+type ReactionRepository interface {
+	Save(ctx context.Context, tx *sql.Tx, userID string, postID string, reactionType string) error
+	Delete(ctx context.Context, tx *sql.Tx, userID string, postID string, reactionType string) error
+	DeleteByPostID(ctx context.Context, tx *sql.Tx, postID string) error
+	CountByPostIDs(ctx context.Context, postIDs []string) (map[string]map[string]int, error)
+	FindTypesByUser(ctx context.Context, userID string, postIDs []string) (map[string][]string, error)
+}
+
+type reactionRepository struct {
+	db *sql.DB
+}
+
+func NewReactionRepository(db *sql.DB) ReactionRepository {
+	return &reactionRepository{db: db}
+}
+
+func (r *reactionRepository) Save(ctx context.Context, tx *sql.Tx, userID string, postID string, reactionType string) error {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+        INSERT INTO reactions (user_id, post_id, type)
+        VALUES ($1, $2, $3)
+        ON CONFLICT (user_id, post_id, type) DO NOTHING;
+    `
+
+	var err error
+	if tx != nil {
+		_, err = tx.ExecContext(ctx, query, userID, postID, reactionType)
+	} else {
+		_, err = r.db.ExecContext(ctx, query, userID, postID, reactionType)
+	}
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *reactionRepository) Delete(ctx context.Context, tx *sql.Tx, userID string, postID string, reactionType string) error {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+        DELETE FROM reactions
+        WHERE user_id = $1 AND post_id = $2 AND type = $3;
+    `
+
+	var err error
+	if tx != nil {
+		_, err = tx.ExecContext(ctx, query, userID, postID, reactionType)
+	} else {
+		_, err = r.db.ExecContext(ctx, query, userID, postID, reactionType)
+	}
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *reactionRepository) DeleteByPostID(ctx context.Context, tx *sql.Tx, postID string) error {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+        DELETE FROM reactions
+        WHERE post_id = $1;
+    `
+
+	var err error
+	if tx != nil {
+		_, err = tx.ExecContext(ctx, query, postID)
+	} else {
+		_, err = r.db.ExecContext(ctx, query, postID)
+	}
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// CountByPostIDs returns reaction counts per type, keyed by post ID, in a single query.
+func (r *reactionRepository) CountByPostIDs(ctx context.Context, postIDs []string) (map[string]map[string]int, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	counts := make(map[string]map[string]int)
+	if len(postIDs) == 0 {
+		return counts, nil
+	}
+
+	query := `
+        SELECT post_id, type, COUNT(*)
+        FROM reactions
+        WHERE post_id = ANY($1)
+        GROUP BY post_id, type;
+    `
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(postIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var postID, reactionType string
+		var count int
+		if err := rows.Scan(&postID, &reactionType, &count); err != nil {
+			return nil, err
+		}
+		if counts[postID] == nil {
+			counts[postID] = make(map[string]int)
+		}
+		counts[postID][reactionType] = count
+	}
+
+	return counts, rows.Err()
+}
+
+// FindTypesByUser returns the reaction types userID left on each of the given posts, keyed by post ID.
+func (r *reactionRepository) FindTypesByUser(ctx context.Context, userID string, postIDs []string) (map[string][]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	types := make(map[string][]string)
+	if len(postIDs) == 0 {
+		return types, nil
+	}
+
+	query := `
+        SELECT post_id, type
+        FROM reactions
+        WHERE user_id = $1 AND post_id = ANY($2)
+        ORDER BY created_at;
+    `
+
+	rows, err := r.db.QueryContext(ctx, query, userID, pq.Array(postIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var postID, reactionType string
+		if err := rows.Scan(&postID, &reactionType); err != nil {
+			return nil, err
+		}
+		types[postID] = append(types[postID], reactionType)
+	}
+
+	return types, rows.Err()
+}
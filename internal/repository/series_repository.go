@@ -0,0 +1,322 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/lib/pq"
+	"github.com/wanafiq/feed-api/internal/constants"
+	"github.com/wanafiq/feed-api/internal/models"
+	"strings"
+)
+
+type SeriesRepository interface {
+	Save(ctx context.Context, tx *sql.Tx, series *models.Series) error
+	FindByID(ctx context.Context, seriesID string) (*models.Series, error)
+	FindByPostID(ctx context.Context, postID string) (*models.Series, error)
+	FindPosts(ctx context.Context, seriesID string, viewerID string) ([]*models.Post, error)
+	FindPostIDs(ctx context.Context, seriesID string) ([]string, error)
+	AddPost(ctx context.Context, tx *sql.Tx, seriesID string, postID string) error
+	RemovePost(ctx context.Context, tx *sql.Tx, seriesID string, postID string) error
+	UpdatePositions(ctx context.Context, tx *sql.Tx, seriesID string, postIDs []string) error
+	DeleteByPostID(ctx context.Context, tx *sql.Tx, postID string) error
+}
+
+type seriesRepository struct {
+	db *sql.DB
+}
+
+func NewSeriesRepository(db *sql.DB) SeriesRepository {
+	return &seriesRepository{db: db}
+}
+
+func (r *seriesRepository) Save(ctx context.Context, tx *sql.Tx, series *models.Series) error {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+        INSERT INTO series (author_id, title, slug, description)
+        VALUES ($1, $2, $3, $4)
+        RETURNING id, created_at;
+    `
+
+	var row *sql.Row
+	if tx != nil {
+		row = tx.QueryRowContext(ctx, query, series.AuthorID, series.Title, series.Slug, series.Description)
+	} else {
+		row = r.db.QueryRowContext(ctx, query, series.AuthorID, series.Title, series.Slug, series.Description)
+	}
+
+	return row.Scan(&series.ID, &series.CreatedAt)
+}
+
+func (r *seriesRepository) FindByID(ctx context.Context, seriesID string) (*models.Series, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+		SELECT s.id, s.author_id, s.title, s.slug, s.description, s.created_at,
+		       u.id, u.username
+		FROM series s
+		JOIN users u ON u.id = s.author_id
+		WHERE s.id = $1
+	`
+
+	return scanSeries(r.db.QueryRowContext(ctx, query, seriesID))
+}
+
+func (r *seriesRepository) FindByPostID(ctx context.Context, postID string) (*models.Series, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+		SELECT s.id, s.author_id, s.title, s.slug, s.description, s.created_at,
+		       u.id, u.username
+		FROM series s
+		JOIN series_posts sp ON sp.series_id = s.id
+		JOIN users u ON u.id = s.author_id
+		WHERE sp.post_id = $1
+	`
+
+	return scanSeries(r.db.QueryRowContext(ctx, query, postID))
+}
+
+// FindPosts returns the posts of a series in reading order, skipping posts the
+// viewer cannot see. Drafts and scheduled posts are only listed for their authors.
+func (r *seriesRepository) FindPosts(ctx context.Context, seriesID string, viewerID string) ([]*models.Post, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	args := []any{seriesID}
+	where := []string{"sp.series_id = $1"}
+
+	published := "p.is_published AND (p.published_at IS NULL OR p.published_at <= NOW())"
+	if viewerID != "" {
+		where = append(where, postBlockClause(2), fmt.Sprintf(
+			"((%s) OR p.id IN (SELECT pu.post_id FROM post_user pu WHERE pu.user_id = $2 AND pu.status = '%s'))",
+			published, constants.PostUserStatusAccepted,
+		))
+	} else {
+		where = append(where, published)
+	}
+	visibilityClause, visibilityArgs := postVisibilityClause(viewerID, 2)
+	where = append(where, visibilityClause)
+	args = append(args, visibilityArgs...)
+
+	query := fmt.Sprintf(`
+		SELECT
+			p.id, p.title, p.slug, p.content, p.is_published, p.published_at,
+			p.created_at, p.created_by, p.updated_at, p.updated_by, p.author_id, p.visibility,
+			p.excerpt, p.word_count, p.reading_time,
+			u.id, u.username, u.email
+		FROM series_posts sp
+		JOIN posts p ON p.id = sp.post_id
+		JOIN users u ON u.id = p.author_id
+		WHERE %s
+		ORDER BY sp.position
+	`, strings.Join(where, " AND "))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var posts []*models.Post
+	for rows.Next() {
+		var post models.Post
+		err := rows.Scan(
+			&post.ID,
+			&post.Title,
+			&post.Slug,
+			&post.Content,
+			&post.IsPublished,
+			&post.PublishedAt,
+			&post.CreatedAt,
+			&post.CreatedBy,
+			&post.UpdatedAt,
+			&post.UpdatedBy,
+			&post.AuthorID,
+			&post.Visibility,
+			&post.Excerpt,
+			&post.WordCount,
+			&post.ReadingTime,
+			&post.Author.ID,
+			&post.Author.Username,
+			&post.Author.Email,
+		)
+		if err != nil {
+			return nil, err
+		}
+		posts = append(posts, &post)
+	}
+
+	return posts, rows.Err()
+}
+
+// FindPostIDs returns the ids of all posts of a series in reading order, regardless of visibility.
+func (r *seriesRepository) FindPostIDs(ctx context.Context, seriesID string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+        SELECT post_id
+        FROM series_posts
+        WHERE series_id = $1
+        ORDER BY position;
+    `
+
+	rows, err := r.db.QueryContext(ctx, query, seriesID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var postIDs []string
+	for rows.Next() {
+		var postID string
+		if err := rows.Scan(&postID); err != nil {
+			return nil, err
+		}
+		postIDs = append(postIDs, postID)
+	}
+
+	return postIDs, rows.Err()
+}
+
+// AddPost appends a post to the end of the series.
+func (r *seriesRepository) AddPost(ctx context.Context, tx *sql.Tx, seriesID string, postID string) error {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+        INSERT INTO series_posts (series_id, post_id, position)
+        SELECT $1, $2, COALESCE(MAX(position), 0) + 1
+        FROM series_posts
+        WHERE series_id = $1;
+    `
+
+	var err error
+	if tx != nil {
+		_, err = tx.ExecContext(ctx, query, seriesID, postID)
+	} else {
+		_, err = r.db.ExecContext(ctx, query, seriesID, postID)
+	}
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RemovePost removes a post from the series and closes the gap it leaves. It
+// returns sql.ErrNoRows when the post is not part of the series.
+func (r *seriesRepository) RemovePost(ctx context.Context, tx *sql.Tx, seriesID string, postID string) error {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+        WITH removed AS (
+            DELETE FROM series_posts
+            WHERE series_id = $1 AND post_id = $2
+            RETURNING series_id, position
+        ), shifted AS (
+            UPDATE series_posts sp
+            SET position = sp.position - 1
+            FROM removed
+            WHERE sp.series_id = removed.series_id AND sp.position > removed.position
+        )
+        SELECT COUNT(*) FROM removed;
+    `
+
+	var row *sql.Row
+	if tx != nil {
+		row = tx.QueryRowContext(ctx, query, seriesID, postID)
+	} else {
+		row = r.db.QueryRowContext(ctx, query, seriesID, postID)
+	}
+
+	var removed int
+	if err := row.Scan(&removed); err != nil {
+		return err
+	}
+	if removed == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// UpdatePositions renumbers the posts of a series following the order of postIDs.
+func (r *seriesRepository) UpdatePositions(ctx context.Context, tx *sql.Tx, seriesID string, postIDs []string) error {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+        UPDATE series_posts sp
+        SET position = o.position
+        FROM unnest($2::uuid[]) WITH ORDINALITY AS o(post_id, position)
+        WHERE sp.series_id = $1 AND sp.post_id = o.post_id;
+    `
+
+	var err error
+	if tx != nil {
+		_, err = tx.ExecContext(ctx, query, seriesID, pq.Array(postIDs))
+	} else {
+		_, err = r.db.ExecContext(ctx, query, seriesID, pq.Array(postIDs))
+	}
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteByPostID takes a deleted post out of its series, if any, and closes the gap it leaves.
+func (r *seriesRepository) DeleteByPostID(ctx context.Context, tx *sql.Tx, postID string) error {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+        WITH removed AS (
+            DELETE FROM series_posts
+            WHERE post_id = $1
+            RETURNING series_id, position
+        )
+        UPDATE series_posts sp
+        SET position = sp.position - 1
+        FROM removed
+        WHERE sp.series_id = removed.series_id AND sp.position > removed.position;
+    `
+
+	var err error
+	if tx != nil {
+		_, err = tx.ExecContext(ctx, query, postID)
+	} else {
+		_, err = r.db.ExecContext(ctx, query, postID)
+	}
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func scanSeries(row *sql.Row) (*models.Series, error) {
+	var series models.Series
+	err := row.Scan(
+		&series.ID,
+		&series.AuthorID,
+		&series.Title,
+		&series.Slug,
+		&series.Description,
+		&series.CreatedAt,
+		&series.Author.ID,
+		&series.Author.Username,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &series, nil
+}
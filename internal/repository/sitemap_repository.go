@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/wanafiq/feed-api/internal/constants"
+	"github.com/wanafiq/feed-api/internal/models"
+)
+
+type SitemapRepository interface {
+	Count(ctx context.Context) (models.SitemapCounts, error)
+	FindPosts(ctx context.Context, offset int, limit int) ([]models.SitemapEntry, error)
+	FindTags(ctx context.Context, offset int, limit int) ([]models.SitemapEntry, error)
+	FindUsers(ctx context.Context, offset int, limit int) ([]models.SitemapEntry, error)
+}
+
+type sitemapRepository struct {
+	db *sql.DB
+}
+
+func NewSitemapRepository(db *sql.DB) SitemapRepository {
+	return &sitemapRepository{db: db}
+}
+
+// publicPostsClause matches the posts an anonymous visitor can read.
+func publicPostsClause() string {
+	visibilityClause, _ := postVisibilityClause("", 0)
+	return "p.is_published AND (p.published_at IS NULL OR p.published_at <= NOW()) AND " + visibilityClause
+}
+
+const publicUsersClause = "u.is_active AND u.is_private = FALSE"
+
+func (r *sitemapRepository) Count(ctx context.Context) (models.SitemapCounts, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := fmt.Sprintf(`
+		WITH public_posts AS (
+			SELECT p.id
+			FROM posts p
+			JOIN users u ON u.id = p.author_id
+			WHERE %s
+		)
+		SELECT
+			(SELECT COUNT(*) FROM public_posts),
+			(SELECT COUNT(DISTINCT pt.tag_id) FROM post_tag pt JOIN public_posts pp ON pp.id = pt.post_id),
+			(SELECT COUNT(*) FROM users u WHERE %s)
+	`, publicPostsClause(), publicUsersClause)
+
+	var counts models.SitemapCounts
+	err := r.db.QueryRowContext(ctx, query).Scan(&counts.Posts, &counts.Tags, &counts.Users)
+	if err != nil {
+		return counts, err
+	}
+
+	return counts, nil
+}
+
+func (r *sitemapRepository) FindPosts(ctx context.Context, offset int, limit int) ([]models.SitemapEntry, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := fmt.Sprintf(`
+		SELECT p.id, COALESCE(p.updated_at, p.published_at, p.created_at)
+		FROM posts p
+		JOIN users u ON u.id = p.author_id
+		WHERE %s
+		ORDER BY p.created_at, p.id
+		OFFSET $1 LIMIT $2
+	`, publicPostsClause())
+
+	return r.findEntries(ctx, query, offset, limit)
+}
+
+// FindTags lists the tags of public posts, last modified when their latest post was.
+func (r *sitemapRepository) FindTags(ctx context.Context, offset int, limit int) ([]models.SitemapEntry, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := fmt.Sprintf(`
+		SELECT t.name, MAX(COALESCE(p.updated_at, p.published_at, p.created_at))
+		FROM tags t
+		JOIN post_tag pt ON pt.tag_id = t.id
+		JOIN posts p ON p.id = pt.post_id
+		JOIN users u ON u.id = p.author_id
+		WHERE %s
+		GROUP BY t.id, t.name
+		ORDER BY t.name, t.id
+		OFFSET $1 LIMIT $2
+	`, publicPostsClause())
+
+	return r.findEntries(ctx, query, offset, limit)
+}
+
+func (r *sitemapRepository) FindUsers(ctx context.Context, offset int, limit int) ([]models.SitemapEntry, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := fmt.Sprintf(`
+		SELECT u.id, COALESCE(u.updated_at, u.created_at)
+		FROM users u
+		WHERE %s
+		ORDER BY u.created_at, u.id
+		OFFSET $1 LIMIT $2
+	`, publicUsersClause)
+
+	return r.findEntries(ctx, query, offset, limit)
+}
+
+func (r *sitemapRepository) findEntries(ctx context.Context, query string, offset int, limit int) ([]models.SitemapEntry, error) {
+	rows, err := r.db.QueryContext(ctx, query, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []models.SitemapEntry
+	for rows.Next() {
+		var entry models.SitemapEntry
+		if err := rows.Scan(&entry.Key, &entry.LastMod); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/wanafiq/feed-api/internal/constants"
+	"github.com/wanafiq/feed-api/internal/models"
+	"time"
+)
+
+// StreamChannel is the Postgres NOTIFY channel stream events are announced on.
+const StreamChannel = "stream_events"
+
+type StreamRepository interface {
+	Save(ctx context.Context, event *models.StreamEvent) error
+	FindAfter(ctx context.Context, afterID int64, limit int) ([]*models.StreamEvent, error)
+	FindForUserAfter(ctx context.Context, userID string, afterID int64, limit int) ([]*models.StreamEvent, error)
+	DeleteBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+type streamRepository struct {
+	db *sql.DB
+}
+
+func NewStreamRepository(db *sql.DB) StreamRepository {
+	return &streamRepository{db: db}
+}
+
+// Save stores an event and announces it, as JSON, on StreamChannel.
+func (r *streamRepository) Save(ctx context.Context, event *models.StreamEvent) error {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+        WITH inserted AS (
+            INSERT INTO stream_events (type, user_id, author_id, data)
+            VALUES ($1, $2, $3, $4)
+            RETURNING id, type, user_id, author_id, data, created_at
+        )
+        SELECT i.id, i.created_at
+        FROM inserted i
+        CROSS JOIN LATERAL (
+            SELECT pg_notify($5, json_build_object(
+                'id', i.id,
+                'type', i.type,
+                'userId', i.user_id,
+                'authorId', i.author_id,
+                'data', i.data,
+                'createdAt', i.created_at
+            )::text)
+        ) n;
+    `
+
+	return r.db.QueryRowContext(ctx, query, event.Type, event.UserID, event.AuthorID, []byte(event.Data), StreamChannel).
+		Scan(&event.ID, &event.CreatedAt)
+}
+
+// FindAfter returns every event after afterID, oldest first.
+func (r *streamRepository) FindAfter(ctx context.Context, afterID int64, limit int) ([]*models.StreamEvent, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+        SELECT id, type, user_id, author_id, data, created_at
+        FROM stream_events
+        WHERE id > $1
+        ORDER BY id
+        LIMIT $2;
+    `
+
+	rows, err := r.db.QueryContext(ctx, query, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanStreamEvents(rows)
+}
+
+// FindForUserAfter returns the events after afterID meant for userID: their
+// notifications and the posts of the users they follow, oldest first. As in the
+// feed, posts by users they muted or blocked, or who blocked them, are left out.
+func (r *streamRepository) FindForUserAfter(ctx context.Context, userID string, afterID int64, limit int) ([]*models.StreamEvent, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+        SELECT id, type, user_id, author_id, data, created_at
+        FROM stream_events
+        WHERE id > $2
+          AND (
+              user_id = $1
+              OR (
+                  author_id IN (SELECT f.followee_id FROM followers f WHERE f.follower_id = $1)
+                  AND author_id NOT IN (SELECT m.muted_id FROM mutes m WHERE m.muter_id = $1)
+                  AND author_id NOT IN (SELECT b.blocked_id FROM blocks b WHERE b.blocker_id = $1)
+                  AND author_id NOT IN (SELECT b.blocker_id FROM blocks b WHERE b.blocked_id = $1)
+              )
+          )
+        ORDER BY id
+        LIMIT $3;
+    `
+
+	rows, err := r.db.QueryContext(ctx, query, userID, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanStreamEvents(rows)
+}
+
+func (r *streamRepository) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+        DELETE FROM stream_events
+        WHERE created_at < $1;
+    `
+
+	result, err := r.db.ExecContext(ctx, query, before)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+func scanStreamEvents(rows *sql.Rows) ([]*models.StreamEvent, error) {
+	var events []*models.StreamEvent
+	for rows.Next() {
+		var event models.StreamEvent
+		var data []byte
+		if err := rows.Scan(&event.ID, &event.Type, &event.UserID, &event.AuthorID, &data, &event.CreatedAt); err != nil {
+			return nil, err
+		}
+		event.Data = data
+		events = append(events, &event)
+	}
+
+	return events, rows.Err()
+}
@@ -10,6 +10,8 @@ import (
 type TokenRepository interface {
 	Save(ctx context.Context, tx *sql.Tx, role *models.Token) error
 	FindByUserID(ctx context.Context, userID string) (*models.Token, error)
+	Consume(ctx context.Context, tokenType string, value string) (string, error)
+	DeleteExpired(ctx context.Context, tokenType string) (int64, error)
 }
 
 type tokenRepository struct {
@@ -79,3 +81,40 @@ func (r *tokenRepository) FindByUserID(ctx context.Context, userID string) (*mod
 
 	return token, nil
 }
+
+// Consume deletes an unexpired token and returns the user it was issued to, so
+// that it can only be used once.
+func (r *tokenRepository) Consume(ctx context.Context, tokenType string, value string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+		DELETE FROM tokens
+		WHERE type = $1 AND value = $2 AND expired_at > NOW()
+		RETURNING user_id;
+	`
+
+	var userID string
+	if err := r.db.QueryRowContext(ctx, query, tokenType, value).Scan(&userID); err != nil {
+		return "", err
+	}
+
+	return userID, nil
+}
+
+func (r *tokenRepository) DeleteExpired(ctx context.Context, tokenType string) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+		DELETE FROM tokens
+		WHERE type = $1 AND expired_at <= NOW();
+	`
+
+	result, err := r.db.ExecContext(ctx, query, tokenType)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
@@ -27,8 +27,8 @@ func (r *userRepository) Save(ctx context.Context, tx *sql.Tx, user *models.User
 	defer cancel()
 
 	query := `
-        INSERT INTO users (username, email, password, created_at, created_by, role_id)
-        VALUES ($1, $2, $3, $4, $5, $6)
+        INSERT INTO users (username, email, password, created_at, created_by, role_id, locale)
+        VALUES ($1, $2, $3, $4, $5, $6, $7)
         RETURNING id;
     `
 
@@ -42,6 +42,7 @@ func (r *userRepository) Save(ctx context.Context, tx *sql.Tx, user *models.User
 			user.CreatedAt,
 			user.CreatedBy,
 			user.RoleID,
+			user.Locale,
 		)
 	} else {
 		row = r.db.QueryRowContext(ctx, query,
@@ -51,6 +52,7 @@ func (r *userRepository) Save(ctx context.Context, tx *sql.Tx, user *models.User
 			user.CreatedAt,
 			user.CreatedBy,
 			user.RoleID,
+			user.Locale,
 		)
 	}
 
@@ -72,6 +74,8 @@ func (r *userRepository) FindByEmail(ctx context.Context, email string) (*models
                u.email, 
                u.password, 
                u.is_active, 
+               u.is_private,
+               u.locale,
                u.created_at, 
                u.created_by, 
                u.updated_at, 
@@ -97,6 +101,8 @@ func (r *userRepository) FindByEmail(ctx context.Context, email string) (*models
 		&user.Email,
 		&user.Password,
 		&user.IsActive,
+		&user.IsPrivate,
+		&user.Locale,
 		&user.CreatedAt,
 		&user.CreatedBy,
 		&user.UpdatedAt,
@@ -128,6 +134,8 @@ func (r *userRepository) FindByID(ctx context.Context, userID string) (*models.U
                u.email, 
                u.password, 
                u.is_active, 
+               u.is_private,
+               u.locale,
                u.created_at, 
                u.created_by, 
                u.updated_at, 
@@ -153,6 +161,8 @@ func (r *userRepository) FindByID(ctx context.Context, userID string) (*models.U
 		&user.Email,
 		&user.Password,
 		&user.IsActive,
+		&user.IsPrivate,
+		&user.Locale,
 		&user.CreatedAt,
 		&user.CreatedBy,
 		&user.UpdatedAt,
@@ -180,8 +190,8 @@ func (r *userRepository) Update(ctx context.Context, tx *sql.Tx, user *models.Us
 
 	query := `
         UPDATE users 
-        SET username = $1, email = $2, password = $3, role_id = $4, is_active = $5, updated_at = $6, updated_by = $7
-        WHERE id = $8
+        SET username = $1, email = $2, password = $3, role_id = $4, is_active = $5, is_private = $6, locale = $7, updated_at = $8, updated_by = $9
+        WHERE id = $10
         RETURNING updated_at, updated_by;
     `
 
@@ -193,6 +203,8 @@ func (r *userRepository) Update(ctx context.Context, tx *sql.Tx, user *models.Us
 			&user.Password,
 			&user.Role.ID,
 			&user.IsActive,
+			&user.IsPrivate,
+			&user.Locale,
 			&user.UpdatedAt,
 			&user.UpdatedBy,
 			&user.ID,
@@ -202,8 +214,10 @@ func (r *userRepository) Update(ctx context.Context, tx *sql.Tx, user *models.Us
 			&user.Username,
 			&user.Email,
 			&user.Password,
-			&user.Role,
+			&user.Role.ID,
 			&user.IsActive,
+			&user.IsPrivate,
+			&user.Locale,
 			&user.UpdatedAt,
 			&user.UpdatedBy,
 			&user.ID,
@@ -0,0 +1,464 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/lib/pq"
+	"github.com/wanafiq/feed-api/internal/constants"
+	"github.com/wanafiq/feed-api/internal/models"
+	"time"
+)
+
+type WebhookRepository interface {
+	Save(ctx context.Context, tx *sql.Tx, webhook *models.Webhook) error
+	FindAll(ctx context.Context, offset int, limit int) ([]*models.Webhook, int, error)
+	FindByID(ctx context.Context, webhookID string) (*models.Webhook, error)
+	Update(ctx context.Context, tx *sql.Tx, webhook *models.Webhook) error
+	Delete(ctx context.Context, tx *sql.Tx, webhookID string) error
+	RecordSuccess(ctx context.Context, webhookID string) error
+	RecordFailure(ctx context.Context, webhookID string, disableAfter int) (bool, error)
+	Enqueue(ctx context.Context, eventID int64, eventType string, payload []byte) (int64, error)
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*models.WebhookDelivery, error)
+	MarkSucceeded(ctx context.Context, deliveryID int64, attempt *models.WebhookAttempt) error
+	MarkFailed(ctx context.Context, deliveryID int64, attempt *models.WebhookAttempt, nextAttemptAt *time.Time) error
+	FindDeliveries(ctx context.Context, webhookID string, status string, offset int, limit int) ([]*models.WebhookDelivery, int, error)
+	FindDeliveryByID(ctx context.Context, webhookID string, deliveryID int64) (*models.WebhookDelivery, error)
+	Redeliver(ctx context.Context, delivery *models.WebhookDelivery) (*models.WebhookDelivery, error)
+}
+
+type webhookRepository struct {
+	db *sql.DB
+}
+
+func NewWebhookRepository(db *sql.DB) WebhookRepository {
+	return &webhookRepository{db: db}
+}
+
+func (r *webhookRepository) Save(ctx context.Context, tx *sql.Tx, webhook *models.Webhook) error {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+        INSERT INTO webhooks (url, description, events, secret, is_active, created_by)
+        VALUES ($1, $2, $3, $4, $5, $6)
+        RETURNING id, failure_count, created_at;
+    `
+
+	args := []any{webhook.URL, webhook.Description, pq.Array(webhook.Events), webhook.Secret, webhook.IsActive, webhook.CreatedBy}
+
+	var row *sql.Row
+	if tx != nil {
+		row = tx.QueryRowContext(ctx, query, args...)
+	} else {
+		row = r.db.QueryRowContext(ctx, query, args...)
+	}
+
+	return row.Scan(&webhook.ID, &webhook.FailureCount, &webhook.CreatedAt)
+}
+
+func (r *webhookRepository) FindAll(ctx context.Context, offset int, limit int) ([]*models.Webhook, int, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	countQuery := `
+        SELECT COUNT(*)
+        FROM webhooks;
+    `
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, countQuery).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+        SELECT id, url, description, events, secret, is_active, failure_count, disabled_at,
+               created_at, created_by, updated_at, updated_by
+        FROM webhooks
+        ORDER BY created_at DESC, id
+        OFFSET $1 LIMIT $2;
+    `
+
+	rows, err := r.db.QueryContext(ctx, query, offset, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var webhooks []*models.Webhook
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		webhooks = append(webhooks, webhook)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return webhooks, total, nil
+}
+
+func (r *webhookRepository) FindByID(ctx context.Context, webhookID string) (*models.Webhook, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+        SELECT id, url, description, events, secret, is_active, failure_count, disabled_at,
+               created_at, created_by, updated_at, updated_by
+        FROM webhooks
+        WHERE id = $1;
+    `
+
+	return scanWebhook(r.db.QueryRowContext(ctx, query, webhookID))
+}
+
+// Update saves the settings of a webhook. Turning a webhook back on clears its
+// failures, so it gets a fresh start.
+func (r *webhookRepository) Update(ctx context.Context, tx *sql.Tx, webhook *models.Webhook) error {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+        UPDATE webhooks
+        SET url = $2,
+            description = $3,
+            events = $4,
+            failure_count = CASE WHEN $5 AND NOT is_active THEN 0 ELSE failure_count END,
+            disabled_at = CASE WHEN $5 THEN NULL ELSE disabled_at END,
+            is_active = $5,
+            updated_at = NOW(),
+            updated_by = $6
+        WHERE id = $1
+        RETURNING failure_count, disabled_at, updated_at;
+    `
+
+	args := []any{webhook.ID, webhook.URL, webhook.Description, pq.Array(webhook.Events), webhook.IsActive, webhook.UpdatedBy}
+
+	var row *sql.Row
+	if tx != nil {
+		row = tx.QueryRowContext(ctx, query, args...)
+	} else {
+		row = r.db.QueryRowContext(ctx, query, args...)
+	}
+
+	return row.Scan(&webhook.FailureCount, &webhook.DisabledAt, &webhook.UpdatedAt)
+}
+
+// Delete removes a webhook and its deliveries. It returns sql.ErrNoRows when
+// there is no webhook with that id.
+func (r *webhookRepository) Delete(ctx context.Context, tx *sql.Tx, webhookID string) error {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+        DELETE FROM webhooks
+        WHERE id = $1;
+    `
+
+	var result sql.Result
+	var err error
+	if tx != nil {
+		result, err = tx.ExecContext(ctx, query, webhookID)
+	} else {
+		result, err = r.db.ExecContext(ctx, query, webhookID)
+	}
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (r *webhookRepository) RecordSuccess(ctx context.Context, webhookID string) error {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+        UPDATE webhooks
+        SET failure_count = 0
+        WHERE id = $1 AND failure_count > 0;
+    `
+
+	_, err := r.db.ExecContext(ctx, query, webhookID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RecordFailure counts a delivery that ran out of attempts, and turns the
+// webhook off once disableAfter deliveries failed in a row. It reports whether
+// the webhook was turned off, or returns sql.ErrNoRows when it already was.
+func (r *webhookRepository) RecordFailure(ctx context.Context, webhookID string, disableAfter int) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+        UPDATE webhooks
+        SET failure_count = failure_count + 1,
+            is_active = failure_count + 1 < $2,
+            disabled_at = CASE WHEN failure_count + 1 >= $2 THEN NOW() ELSE disabled_at END
+        WHERE id = $1 AND is_active
+        RETURNING NOT is_active;
+    `
+
+	var disabled bool
+	if err := r.db.QueryRowContext(ctx, query, webhookID, disableAfter).Scan(&disabled); err != nil {
+		return false, err
+	}
+
+	return disabled, nil
+}
+
+// Enqueue queues an event for every active webhook subscribed to its type and
+// returns how many were queued. An event is only queued once per webhook.
+func (r *webhookRepository) Enqueue(ctx context.Context, eventID int64, eventType string, payload []byte) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+        INSERT INTO webhook_deliveries (webhook_id, event_id, event_type, payload)
+        SELECT id, $1::bigint, $2::varchar, $3::jsonb
+        FROM webhooks
+        WHERE is_active AND $2 = ANY (events)
+        ON CONFLICT (webhook_id, event_id) WHERE redelivery_of IS NULL DO NOTHING;
+    `
+
+	result, err := r.db.ExecContext(ctx, query, eventID, eventType, payload)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// ClaimDue picks pending deliveries of active webhooks whose next attempt is due
+// and pushes that attempt back by lease, so concurrent senders skip them.
+func (r *webhookRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*models.WebhookDelivery, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+        UPDATE webhook_deliveries d
+        SET attempts = d.attempts + 1,
+            next_attempt_at = NOW() + make_interval(secs => $2)
+        WHERE d.id IN (
+            SELECT wd.id
+            FROM webhook_deliveries wd
+            JOIN webhooks w ON w.id = wd.webhook_id
+            WHERE wd.status = $3 AND wd.next_attempt_at <= NOW() AND w.is_active
+            ORDER BY wd.next_attempt_at, wd.id
+            LIMIT $1
+            FOR UPDATE OF wd SKIP LOCKED
+        )
+        RETURNING d.id, d.webhook_id, d.event_id, d.event_type, d.payload, d.status, d.attempts, d.next_attempt_at,
+                  d.response_status, d.response_body, d.last_error, d.duration_ms, d.redelivery_of, d.created_at, d.delivered_at;
+    `
+
+	rows, err := r.db.QueryContext(ctx, query, limit, lease.Seconds(), constants.WebhookDeliveryPending)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanWebhookDeliveries(rows)
+}
+
+func (r *webhookRepository) MarkSucceeded(ctx context.Context, deliveryID int64, attempt *models.WebhookAttempt) error {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+        UPDATE webhook_deliveries
+        SET status = $2, response_status = $3, response_body = $4, last_error = NULL, duration_ms = $5, delivered_at = NOW()
+        WHERE id = $1;
+    `
+
+	_, err := r.db.ExecContext(ctx, query, deliveryID, constants.WebhookDeliverySucceeded,
+		attempt.ResponseStatus, attempt.ResponseBody, attempt.DurationMs)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// MarkFailed records a failed attempt and when to try again, or gives up on the
+// delivery when nextAttemptAt is nil.
+func (r *webhookRepository) MarkFailed(ctx context.Context, deliveryID int64, attempt *models.WebhookAttempt, nextAttemptAt *time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+        UPDATE webhook_deliveries
+        SET status = CASE WHEN $6::timestamptz IS NULL THEN $2 ELSE status END,
+            next_attempt_at = COALESCE($6, next_attempt_at),
+            response_status = $3,
+            response_body = $4,
+            last_error = $5,
+            duration_ms = $7
+        WHERE id = $1;
+    `
+
+	_, err := r.db.ExecContext(ctx, query, deliveryID, constants.WebhookDeliveryFailed,
+		attempt.ResponseStatus, attempt.ResponseBody, attempt.Error, nextAttemptAt, attempt.DurationMs)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// FindDeliveries lists the deliveries of a webhook, newest first, optionally
+// filtered by status.
+func (r *webhookRepository) FindDeliveries(ctx context.Context, webhookID string, status string, offset int, limit int) ([]*models.WebhookDelivery, int, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	countQuery := `
+        SELECT COUNT(*)
+        FROM webhook_deliveries
+        WHERE webhook_id = $1 AND ($2 = '' OR status = $2);
+    `
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, countQuery, webhookID, status).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+        SELECT id, webhook_id, event_id, event_type, payload, status, attempts, next_attempt_at,
+               response_status, response_body, last_error, duration_ms, redelivery_of, created_at, delivered_at
+        FROM webhook_deliveries
+        WHERE webhook_id = $1 AND ($2 = '' OR status = $2)
+        ORDER BY created_at DESC, id DESC
+        OFFSET $3 LIMIT $4;
+    `
+
+	rows, err := r.db.QueryContext(ctx, query, webhookID, status, offset, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	deliveries, err := scanWebhookDeliveries(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return deliveries, total, nil
+}
+
+func (r *webhookRepository) FindDeliveryByID(ctx context.Context, webhookID string, deliveryID int64) (*models.WebhookDelivery, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+        SELECT id, webhook_id, event_id, event_type, payload, status, attempts, next_attempt_at,
+               response_status, response_body, last_error, duration_ms, redelivery_of, created_at, delivered_at
+        FROM webhook_deliveries
+        WHERE webhook_id = $1 AND id = $2;
+    `
+
+	return scanWebhookDelivery(r.db.QueryRowContext(ctx, query, webhookID, deliveryID))
+}
+
+// Redeliver queues the event of a delivery again, as a new delivery.
+func (r *webhookRepository) Redeliver(ctx context.Context, delivery *models.WebhookDelivery) (*models.WebhookDelivery, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.QueryTimeout)
+	defer cancel()
+
+	query := `
+        INSERT INTO webhook_deliveries (webhook_id, event_id, event_type, payload, redelivery_of)
+        VALUES ($1, $2, $3, $4, $5)
+        RETURNING id, webhook_id, event_id, event_type, payload, status, attempts, next_attempt_at,
+                  response_status, response_body, last_error, duration_ms, redelivery_of, created_at, delivered_at;
+    `
+
+	return scanWebhookDelivery(r.db.QueryRowContext(ctx, query,
+		delivery.WebhookID,
+		delivery.EventID,
+		delivery.EventType,
+		[]byte(delivery.Payload),
+		delivery.ID,
+	))
+}
+
+// scanner is implemented by both *sql.Row and *sql.Rows.
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanWebhook(row scanner) (*models.Webhook, error) {
+	var webhook models.Webhook
+	err := row.Scan(
+		&webhook.ID,
+		&webhook.URL,
+		&webhook.Description,
+		pq.Array(&webhook.Events),
+		&webhook.Secret,
+		&webhook.IsActive,
+		&webhook.FailureCount,
+		&webhook.DisabledAt,
+		&webhook.CreatedAt,
+		&webhook.CreatedBy,
+		&webhook.UpdatedAt,
+		&webhook.UpdatedBy,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &webhook, nil
+}
+
+func scanWebhookDeliveries(rows *sql.Rows) ([]*models.WebhookDelivery, error) {
+	var deliveries []*models.WebhookDelivery
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, rows.Err()
+}
+
+func scanWebhookDelivery(row scanner) (*models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	var payload []byte
+	err := row.Scan(
+		&delivery.ID,
+		&delivery.WebhookID,
+		&delivery.EventID,
+		&delivery.EventType,
+		&payload,
+		&delivery.Status,
+		&delivery.Attempts,
+		&delivery.NextAttemptAt,
+		&delivery.ResponseStatus,
+		&delivery.ResponseBody,
+		&delivery.LastError,
+		&delivery.DurationMs,
+		&delivery.RedeliveryOf,
+		&delivery.CreatedAt,
+		&delivery.DeliveredAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	delivery.Payload = payload
+
+	return &delivery, nil
+}
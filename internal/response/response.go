@@ -13,11 +13,17 @@ type Pagination struct {
 	Prev   int `json:"prev"`
 }
 
+type Cursor struct {
+	Limit int    `json:"limit"`
+	Next  string `json:"next,omitempty"`
+}
+
 type response struct {
 	Status     int         `json:"status"`
 	Message    string      `json:"message,omitzero"`
 	Data       any         `json:"data,omitzero"`
 	Pagination *Pagination `json:"pagination,omitempty"`
+	Cursor     *Cursor     `json:"cursor,omitempty"`
 }
 
 // Success
@@ -29,6 +35,18 @@ func OK(c *gin.Context, data any, pagination *Pagination) {
 	successResponse(c, http.StatusOK, data)
 }
 
+func OKWithCursor(c *gin.Context, data any, cursor *Cursor) {
+	var d = data
+	if data == nil {
+		d = struct{}{}
+	}
+	c.JSON(http.StatusOK, response{
+		Status: http.StatusOK,
+		Data:   d,
+		Cursor: cursor,
+	})
+}
+
 func Created(c *gin.Context, data any) {
 	successResponse(c, http.StatusCreated, data)
 }
@@ -58,6 +76,18 @@ func Conflict(c *gin.Context, error error) {
 	errorResponse(c, http.StatusConflict, error)
 }
 
+func RequestEntityTooLarge(c *gin.Context, error error) {
+	errorResponse(c, http.StatusRequestEntityTooLarge, error)
+}
+
+func UnsupportedMediaType(c *gin.Context, error error) {
+	errorResponse(c, http.StatusUnsupportedMediaType, error)
+}
+
+func TooManyRequests(c *gin.Context, error error) {
+	errorResponse(c, http.StatusTooManyRequests, error)
+}
+
 func InternalServerError(c *gin.Context) {
 	errorResponse(c, http.StatusInternalServerError, nil)
 }
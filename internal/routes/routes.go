@@ -1,14 +1,60 @@
 package routes
 
 import (
+	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/wanafiq/feed-api/internal/constants"
 	"github.com/wanafiq/feed-api/internal/handlers"
 	"github.com/wanafiq/feed-api/internal/middleware"
+	"slices"
+	"time"
 )
 
-func NewRoutes(m *middleware.Middleware, authHandler *handlers.AuthHandler, userHandler *handlers.UserHandler, postHandler *handlers.PostHandler) *gin.Engine {
-	router := gin.Default()
+// redactedQueryPaths take credentials in the query string, which is kept out of
+// the access log for them.
+var redactedQueryPaths = []string{"/api/v1/stream", "/api/v1/digest/unsubscribe"}
+
+func NewRoutes(
+	m *middleware.Middleware,
+	authHandler *handlers.AuthHandler,
+	userHandler *handlers.UserHandler,
+	postHandler *handlers.PostHandler,
+	bookmarkHandler *handlers.BookmarkHandler,
+	dashboardHandler *handlers.DashboardHandler,
+	seriesHandler *handlers.SeriesHandler,
+	mediaHandler *handlers.MediaHandler,
+	feedHandler *handlers.FeedHandler,
+	sitemapHandler *handlers.SitemapHandler,
+	archiveHandler *handlers.ArchiveHandler,
+	importHandler *handlers.ImportHandler,
+	eventHandler *handlers.EventHandler,
+	notificationHandler *handlers.NotificationHandler,
+	digestHandler *handlers.DigestHandler,
+	streamHandler *handlers.StreamHandler,
+	webhookHandler *handlers.WebhookHandler,
+	auditHandler *handlers.AuditHandler,
+	mailHandler *handlers.MailHandler,
+) *gin.Engine {
+	router := gin.New()
+	router.Use(gin.LoggerWithFormatter(logFormatter), gin.Recovery())
+
+	// Syndication feeds, one route per format
+	for _, format := range []string{"rss", "atom", "json"} {
+		router.GET("/feeds/posts."+format, feedHandler.GetSite)
+		router.GET("/feeds/users/:userID/posts."+format, feedHandler.GetByUser)
+		router.GET("/feeds/tags/:tag/posts."+format, feedHandler.GetByTag)
+	}
+
+	// Sitemaps
+	router.GET("/sitemap.xml", sitemapHandler.GetIndex)
+	router.GET("/sitemaps/:name", sitemapHandler.GetSitemap)
+
+	// Captured emails, only available in development
+	if mailHandler != nil {
+		router.GET("/dev/mail", mailHandler.GetAll)
+		router.GET("/dev/mail/:messageID", mailHandler.GetByID)
+		router.DELETE("/dev/mail", mailHandler.DeleteAll)
+	}
 
 	api := router.Group("/api/v1")
 	{
@@ -17,8 +63,20 @@ func NewRoutes(m *middleware.Middleware, authHandler *handlers.AuthHandler, user
 		api.POST("/auth/login", authHandler.Login)
 
 		// Post routes
-		api.GET("/posts", postHandler.GetAll)
-		api.GET("/posts/:postID", postHandler.GetByID)
+		api.GET("/posts", m.OptionalAuth(), postHandler.GetAll)
+		api.GET("/posts/:postID", m.OptionalAuth(), postHandler.GetByID)
+		api.GET("/posts/:postID/meta", m.OptionalAuth(), postHandler.GetMeta)
+		api.GET("/users/:userID/posts", m.OptionalAuth(), postHandler.GetByUser)
+
+		// Series routes
+		api.GET("/series/:seriesID", m.OptionalAuth(), seriesHandler.GetByID)
+
+		// Digest unsubscribe links, signed instead of authenticated
+		api.GET("/digest/unsubscribe", digestHandler.ConfirmUnsubscribe)
+		api.POST("/digest/unsubscribe", digestHandler.Unsubscribe)
+
+		// Server-sent events, a stream ticket may come in the query for EventSource
+		api.GET("/stream", m.StreamAuth(), streamHandler.Stream)
 	}
 
 	privateApi := router.Group("/api/v1")
@@ -28,13 +86,115 @@ func NewRoutes(m *middleware.Middleware, authHandler *handlers.AuthHandler, user
 		privateApi.GET("/users/:userID", userHandler.GetByID)
 		privateApi.PUT("/users/:userID/follow", userHandler.Follow)
 		privateApi.PUT("/users/:userID/unfollow", userHandler.Unfollow)
+		privateApi.PUT("/users/:userID/block", userHandler.Block)
+		privateApi.PUT("/users/:userID/unblock", userHandler.Unblock)
+		privateApi.PUT("/users/:userID/mute", userHandler.Mute)
+		privateApi.PUT("/users/:userID/unmute", userHandler.Unmute)
 		privateApi.PUT("/users/:userID", m.RequireRoles(constants.RoleAdmin), userHandler.Deactivate)
 
+		// Current user routes
+		privateApi.GET("/me/dashboard", dashboardHandler.Get)
+		privateApi.GET("/me/blocks", userHandler.GetBlocked)
+		privateApi.GET("/me/mutes", userHandler.GetMuted)
+		privateApi.PUT("/me/privacy", userHandler.UpdatePrivacy)
+		privateApi.PUT("/me/locale", userHandler.UpdateLocale)
+		privateApi.GET("/me/follow-requests/incoming", userHandler.GetIncomingFollowRequests)
+		privateApi.GET("/me/follow-requests/outgoing", userHandler.GetOutgoingFollowRequests)
+		privateApi.PUT("/me/follow-requests/:userID/approve", userHandler.ApproveFollowRequest)
+		privateApi.PUT("/me/follow-requests/:userID/reject", userHandler.RejectFollowRequest)
+
 		// Post routes
 		privateApi.POST("/posts", postHandler.Save)
-		privateApi.PUT("/posts/:postID", m.RequireRoles(constants.RoleModerator, constants.RoleAdmin), postHandler.Update)
-		privateApi.DELETE("/posts/:postID", m.RequireRoles(constants.RoleAdmin), postHandler.Delete)
+		privateApi.PUT("/posts/:postID", postHandler.Update)
+		privateApi.DELETE("/posts/:postID", postHandler.Delete)
+		privateApi.GET("/posts/:postID/stats", postHandler.GetStats)
+		privateApi.PUT("/posts/:postID/reactions/:type", postHandler.React)
+		privateApi.DELETE("/posts/:postID/reactions/:type", postHandler.Unreact)
+		privateApi.POST("/posts/:postID/coauthors", postHandler.InviteCoAuthor)
+		privateApi.DELETE("/posts/:postID/coauthors/:userID", postHandler.RemoveCoAuthor)
+		privateApi.PUT("/posts/:postID/coauthors/accept", postHandler.AcceptCoAuthor)
+		privateApi.PUT("/posts/:postID/coauthors/decline", postHandler.DeclineCoAuthor)
+		privateApi.GET("/me/coauthor-invitations", postHandler.GetCoAuthorInvitations)
+		privateApi.GET("/me/posts/export", archiveHandler.Export)
+		privateApi.POST("/me/posts/import", archiveHandler.Import)
+
+		// Series routes
+		privateApi.POST("/series", seriesHandler.Create)
+		privateApi.PUT("/series/:seriesID/order", seriesHandler.Reorder)
+		privateApi.PUT("/series/:seriesID/posts/:postID", seriesHandler.AddPost)
+		privateApi.DELETE("/series/:seriesID/posts/:postID", seriesHandler.RemovePost)
+
+		// Stream routes
+		privateApi.POST("/stream/ticket", streamHandler.CreateTicket)
+
+		// Media routes
+		privateApi.POST("/media", mediaHandler.Upload)
+
+		// Import routes
+		privateApi.POST("/imports/wordpress", m.RequireRoles(constants.RoleAdmin), importHandler.WordPress)
+
+		// Event routes
+		privateApi.GET("/events", m.RequireRoles(constants.RoleAdmin), eventHandler.GetAll)
+		privateApi.PUT("/events/:eventID/retry", m.RequireRoles(constants.RoleAdmin), eventHandler.Retry)
+
+		// Webhook routes
+		privateApi.GET("/webhooks", m.RequireRoles(constants.RoleAdmin), webhookHandler.GetAll)
+		privateApi.POST("/webhooks", m.RequireRoles(constants.RoleAdmin), webhookHandler.Create)
+		privateApi.GET("/webhooks/:webhookID", m.RequireRoles(constants.RoleAdmin), webhookHandler.GetByID)
+		privateApi.PUT("/webhooks/:webhookID", m.RequireRoles(constants.RoleAdmin), webhookHandler.Update)
+		privateApi.DELETE("/webhooks/:webhookID", m.RequireRoles(constants.RoleAdmin), webhookHandler.Delete)
+		privateApi.GET("/webhooks/:webhookID/deliveries", m.RequireRoles(constants.RoleAdmin), webhookHandler.GetDeliveries)
+		privateApi.POST("/webhooks/:webhookID/deliveries/:deliveryID/redeliver", m.RequireRoles(constants.RoleAdmin), webhookHandler.Redeliver)
+
+		// Audit routes
+		privateApi.GET("/audit-logs", m.RequireRoles(constants.RoleAdmin), auditHandler.GetAll)
+
+		// Notification routes
+		privateApi.GET("/notifications", notificationHandler.GetAll)
+		privateApi.PUT("/notifications/read", notificationHandler.MarkAllRead)
+		privateApi.PUT("/notifications/:notificationID/read", notificationHandler.MarkRead)
+		privateApi.GET("/notifications/preferences", notificationHandler.GetPreferences)
+		privateApi.PUT("/notifications/preferences", notificationHandler.UpdatePreferences)
+		privateApi.GET("/me/digest", digestHandler.GetSettings)
+		privateApi.PUT("/me/digest", digestHandler.UpdateSettings)
+
+		// Bookmark routes
+		privateApi.PUT("/posts/:postID/bookmark", bookmarkHandler.Add)
+		privateApi.DELETE("/posts/:postID/bookmark", bookmarkHandler.Remove)
+		privateApi.GET("/me/bookmarks", bookmarkHandler.GetAll)
+		privateApi.GET("/me/bookmark-collections", bookmarkHandler.GetCollections)
+		privateApi.POST("/me/bookmark-collections", bookmarkHandler.CreateCollection)
+		privateApi.DELETE("/me/bookmark-collections/:collectionID", bookmarkHandler.DeleteCollection)
 	}
 
 	return router
 }
+
+// logFormatter is gin's default log line, without the query string on
+// redactedQueryPaths.
+func logFormatter(param gin.LogFormatterParams) string {
+	if slices.Contains(redactedQueryPaths, param.Request.URL.Path) {
+		param.Path = param.Request.URL.Path
+	}
+
+	var statusColor, methodColor, resetColor string
+	if param.IsOutputColor() {
+		statusColor = param.StatusCodeColor()
+		methodColor = param.MethodColor()
+		resetColor = param.ResetColor()
+	}
+
+	if param.Latency > time.Minute {
+		param.Latency = param.Latency.Truncate(time.Second)
+	}
+
+	return fmt.Sprintf("[GIN] %v |%s %3d %s| %13v | %15s |%s %-7s %s %#v\n%s",
+		param.TimeStamp.Format("2006/01/02 - 15:04:05"),
+		statusColor, param.StatusCode, resetColor,
+		param.Latency,
+		param.ClientIP,
+		methodColor, param.Method, resetColor,
+		param.Path,
+		param.ErrorMessage,
+	)
+}
@@ -0,0 +1,173 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"github.com/wanafiq/feed-api/internal/config"
+	"github.com/wanafiq/feed-api/internal/constants"
+	"github.com/wanafiq/feed-api/internal/middleware"
+	"github.com/wanafiq/feed-api/internal/models"
+	"github.com/wanafiq/feed-api/internal/repository"
+	"go.uber.org/zap"
+	"sync"
+	"time"
+)
+
+// AnalyticsService counts post views in memory and periodically flushes them
+// to the daily aggregate tables, so reading a post never waits on a write.
+type AnalyticsService struct {
+	config        *config.Config
+	db            *sql.DB
+	logger        *zap.SugaredLogger
+	analyticsRepo repository.AnalyticsRepository
+	postRepo      repository.PostRepository
+	reactionRepo  repository.ReactionRepository
+
+	mu     sync.Mutex
+	seen   map[string]time.Time // postID|viewerKey -> end of the de-duplication window
+	buffer []models.PostView
+	full   chan struct{}
+}
+
+func NewAnalyticsService(
+	config *config.Config,
+	db *sql.DB,
+	logger *zap.SugaredLogger,
+	analyticsRepo repository.AnalyticsRepository,
+	postRepo repository.PostRepository,
+	reactionRepo repository.ReactionRepository,
+) *AnalyticsService {
+	return &AnalyticsService{
+		config:        config,
+		db:            db,
+		logger:        logger,
+		analyticsRepo: analyticsRepo,
+		postRepo:      postRepo,
+		reactionRepo:  reactionRepo,
+		seen:          make(map[string]time.Time),
+		full:          make(chan struct{}, 1),
+	}
+}
+
+// RecordView buffers a view unless the same viewer already viewed the post within the window.
+func (s *AnalyticsService) RecordView(postID string, viewerKey string) {
+	now := time.Now()
+	key := postID + "|" + viewerKey
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if until, ok := s.seen[key]; ok && now.Before(until) {
+		return
+	}
+	s.seen[key] = now.Add(s.config.Analytics.ViewDedupWindow)
+
+	s.buffer = append(s.buffer, models.PostView{
+		PostID:    postID,
+		ViewerKey: viewerKey,
+		ViewedAt:  now,
+	})
+
+	if len(s.buffer) >= s.config.Analytics.ViewBufferSize {
+		select {
+		case s.full <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Run flushes buffered views on every interval, or earlier when the buffer is full,
+// until ctx is cancelled. Remaining views are flushed before it returns.
+func (s *AnalyticsService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.config.Analytics.ViewFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.flush(context.Background())
+			return
+		case <-ticker.C:
+			s.flush(ctx)
+		case <-s.full:
+			s.flush(ctx)
+		}
+	}
+}
+
+func (s *AnalyticsService) flush(ctx context.Context) {
+	now := time.Now()
+
+	s.mu.Lock()
+	views := s.buffer
+	s.buffer = nil
+	for key, until := range s.seen {
+		if now.After(until) {
+			delete(s.seen, key)
+		}
+	}
+	s.mu.Unlock()
+
+	if len(views) == 0 {
+		return
+	}
+
+	if err := s.analyticsRepo.SaveViews(ctx, nil, views); err != nil {
+		s.logger.Errorw("failed to flush post views", "count", len(views), "error", err.Error())
+
+		// keep the views for the next flush, as long as the buffer has room
+		s.mu.Lock()
+		if len(s.buffer)+len(views) <= s.config.Analytics.ViewBufferSize {
+			s.buffer = append(views, s.buffer...)
+		}
+		s.mu.Unlock()
+	}
+}
+
+// GetPostStats returns view and reaction statistics of a post, for its author or an admin.
+func (s *AnalyticsService) GetPostStats(ctx context.Context, userCtx middleware.UserContext, postID string, from time.Time, to time.Time) (*models.PostStats, error) {
+	post, err := s.postRepo.FindByID(ctx, postID)
+	if err != nil {
+		s.logger.Errorw("failed to find post by id", "postID", postID, "error", err.Error())
+		return nil, err
+	}
+
+	if post.AuthorID != userCtx.ID && userCtx.Role != constants.RoleAdmin {
+		return nil, constants.ErrForbidden
+	}
+
+	daily, err := s.analyticsRepo.FindDailyViews(ctx, postID, from, to)
+	if err != nil {
+		s.logger.Errorw("failed to find daily views", "postID", postID, "error", err.Error())
+		return nil, err
+	}
+
+	uniqueViewers, err := s.analyticsRepo.CountUniqueViewers(ctx, postID, from, to)
+	if err != nil {
+		s.logger.Errorw("failed to count unique viewers", "postID", postID, "error", err.Error())
+		return nil, err
+	}
+
+	reactions, err := s.reactionRepo.CountByPostIDs(ctx, []string{postID})
+	if err != nil {
+		s.logger.Errorw("failed to count reactions", "postID", postID, "error", err.Error())
+		return nil, err
+	}
+
+	stats := &models.PostStats{
+		PostID:        postID,
+		From:          from,
+		To:            to,
+		UniqueViewers: uniqueViewers,
+		Reactions:     reactions[postID],
+		Daily:         daily,
+	}
+	if stats.Reactions == nil {
+		stats.Reactions = map[string]int{}
+	}
+	for _, d := range daily {
+		stats.Views += d.Views
+	}
+
+	return stats, nil
+}
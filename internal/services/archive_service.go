@@ -0,0 +1,219 @@
+package services
+
+import (
+	"archive/zip"
+	"cmp"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/wanafiq/feed-api/internal/config"
+	"github.com/wanafiq/feed-api/internal/constants"
+	"github.com/wanafiq/feed-api/internal/markdown"
+	"github.com/wanafiq/feed-api/internal/models"
+	"github.com/wanafiq/feed-api/internal/repository"
+	"github.com/wanafiq/feed-api/internal/types"
+	"github.com/wanafiq/feed-api/internal/utils"
+	"go.uber.org/zap"
+	"io"
+	"path"
+	"strings"
+)
+
+const (
+	// maxImportFileSize bounds a single uncompressed post, whatever the archive claims.
+	maxImportFileSize = 5 << 20
+
+	// maxImportFiles bounds the entries of an archive, directories included.
+	maxImportFiles = 1000
+)
+
+// ArchiveService moves posts in and out of the platform as a zip of Markdown
+// files with YAML front matter.
+type ArchiveService struct {
+	config      *config.Config
+	db          *sql.DB
+	logger      *zap.SugaredLogger
+	postRepo    repository.PostRepository
+	postService *PostService
+}
+
+func NewArchiveService(config *config.Config, db *sql.DB, logger *zap.SugaredLogger, postRepo repository.PostRepository, postService *PostService) *ArchiveService {
+	return &ArchiveService{
+		config:      config,
+		db:          db,
+		logger:      logger,
+		postRepo:    postRepo,
+		postService: postService,
+	}
+}
+
+// Export writes every post of userID, drafts included, to w as a zip archive.
+// Posts the user only co-authored are left to their primary author.
+func (s *ArchiveService) Export(ctx context.Context, userID string, w io.Writer) error {
+	posts, err := s.postRepo.FindByAuthorID(ctx, userID)
+	if err != nil {
+		s.logger.Errorw("failed to find posts by author", "userID", userID, "error", err.Error())
+		return err
+	}
+
+	archive := zip.NewWriter(w)
+	names := make(map[string]int)
+
+	for _, post := range posts {
+		fm := markdown.FrontMatter{
+			Title:       post.Title,
+			Slug:        post.Slug,
+			Visibility:  post.Visibility,
+			Published:   post.IsPublished,
+			CreatedAt:   &post.CreatedAt,
+			PublishedAt: post.PublishedAt,
+			UpdatedAt:   post.UpdatedAt,
+		}
+		for _, tag := range post.Tags {
+			fm.Tags = append(fm.Tags, tag.Name)
+		}
+
+		document, err := markdown.WriteDocument(fm, post.Content)
+		if err != nil {
+			s.logger.Errorw("failed to write post document", "postID", post.ID, "error", err.Error())
+			return err
+		}
+
+		// slugs are not unique, so repeated names get a counter
+		name := fmt.Sprintf("%s-%s", post.CreatedAt.Format("2006-01-02"), cmp.Or(post.Slug, "post"))
+		names[name]++
+		if names[name] > 1 {
+			name = fmt.Sprintf("%s-%d", name, names[name])
+		}
+
+		file, err := archive.CreateHeader(&zip.FileHeader{
+			Name:     name + ".md",
+			Method:   zip.Deflate,
+			Modified: postModified(post),
+		})
+		if err != nil {
+			return err
+		}
+		if _, err := file.Write(document); err != nil {
+			return err
+		}
+	}
+
+	return archive.Close()
+}
+
+// Import creates a post for every Markdown file of the archive, as if each had been
+// sent to PostService.Save, keeping the slug and dates of its front matter. A file
+// that fails is reported and does not stop the others. With dryRun, files are only
+// parsed and validated.
+func (s *ArchiveService) Import(ctx context.Context, userID string, r io.ReaderAt, size int64, dryRun bool) (*models.ImportResult, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, constants.ErrInvalidArchive
+	}
+	if len(archive.File) > maxImportFiles {
+		return nil, constants.ErrTooManyImportFiles
+	}
+
+	result := &models.ImportResult{DryRun: dryRun, Files: []*models.ImportFileResult{}}
+
+	for _, file := range archive.File {
+		if file.FileInfo().IsDir() || isArchiveMetadata(file.Name) {
+			continue
+		}
+
+		fileResult := &models.ImportFileResult{Name: file.Name}
+		result.Files = append(result.Files, fileResult)
+
+		if err := s.importFile(ctx, userID, file, fileResult, dryRun); err != nil {
+			fileResult.Error = err.Error()
+			result.Failed++
+			continue
+		}
+		result.Imported++
+	}
+
+	if result.Imported > 0 && !dryRun {
+		s.logger.Infow("imported posts", "userID", userID, "imported", result.Imported, "failed", result.Failed)
+	}
+
+	return result, nil
+}
+
+func (s *ArchiveService) importFile(ctx context.Context, userID string, file *zip.File, fileResult *models.ImportFileResult, dryRun bool) error {
+	if !strings.EqualFold(path.Ext(file.Name), ".md") {
+		return errors.New("not a markdown file")
+	}
+
+	data, err := readArchiveFile(file)
+	if err != nil {
+		return err
+	}
+
+	fm, body, err := markdown.ParseDocument(data)
+	if err != nil {
+		return fmt.Errorf("invalid front matter: %w", err)
+	}
+
+	req := &types.PostRequest{
+		Title:      fm.Title,
+		Content:    body,
+		Tags:       fm.Tags,
+		Publish:    fm.Published,
+		Visibility: fm.Visibility,
+	}
+	if err := binding.Validator.ValidateStruct(req); err != nil {
+		return err
+	}
+
+	origin := postOrigin{
+		slug:        utils.GenerateSlug(fm.Slug),
+		createdAt:   fm.CreatedAt,
+		publishedAt: fm.PublishedAt,
+	}
+
+	fileResult.Title = req.Title
+	fileResult.Slug = cmp.Or(origin.slug, utils.GenerateSlug(req.Title))
+
+	if dryRun {
+		return nil
+	}
+
+	post, err := s.postService.save(ctx, userID, req, origin)
+	if err != nil {
+		return fmt.Errorf("failed to save post: %w", err)
+	}
+	fileResult.PostID = post.ID
+
+	return nil
+}
+
+func readArchiveFile(file *zip.File) ([]byte, error) {
+	if file.UncompressedSize64 > maxImportFileSize {
+		return nil, constants.ErrImportTooLarge
+	}
+
+	rc, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	// the header's size is not trusted
+	data, err := io.ReadAll(io.LimitReader(rc, maxImportFileSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxImportFileSize {
+		return nil, constants.ErrImportTooLarge
+	}
+
+	return data, nil
+}
+
+// isArchiveMetadata reports files added by archivers rather than by the user.
+func isArchiveMetadata(name string) bool {
+	return strings.HasPrefix(name, "__MACOSX/") || strings.HasPrefix(path.Base(name), ".")
+}
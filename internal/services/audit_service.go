@@ -0,0 +1,76 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"github.com/wanafiq/feed-api/internal/config"
+	"github.com/wanafiq/feed-api/internal/middleware"
+	"github.com/wanafiq/feed-api/internal/models"
+	"github.com/wanafiq/feed-api/internal/repository"
+	"go.uber.org/zap"
+)
+
+// AuditService keeps the audit log. Changes are recorded within the
+// transaction that makes them, so an entry commits or rolls back with its
+// action. Logins and imports are not transactional and are recorded on their
+// own afterwards, best effort: a failed write is logged and the action stands.
+type AuditService struct {
+	config    *config.Config
+	db        *sql.DB
+	logger    *zap.SugaredLogger
+	auditRepo repository.AuditRepository
+}
+
+func NewAuditService(config *config.Config, db *sql.DB, logger *zap.SugaredLogger, auditRepo repository.AuditRepository) *AuditService {
+	return &AuditService{
+		config:    config,
+		db:        db,
+		logger:    logger,
+		auditRepo: auditRepo,
+	}
+}
+
+// Record logs action on a target by actor within tx, or on its own when tx is
+// nil. before and after are snapshots of the target, nil when there is none.
+func (s *AuditService) Record(ctx context.Context, tx *sql.Tx, actor middleware.UserContext, action string, targetType string, targetID string, before any, after any) error {
+	log := &models.AuditLog{
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		IP:         actor.IP,
+		UserAgent:  actor.UserAgent,
+	}
+	if actor.ID != "" {
+		log.ActorID = &actor.ID
+	}
+
+	var err error
+	if before != nil {
+		if log.Before, err = json.Marshal(before); err != nil {
+			return err
+		}
+	}
+	if after != nil {
+		if log.After, err = json.Marshal(after); err != nil {
+			return err
+		}
+	}
+
+	if err := s.auditRepo.Save(ctx, tx, log); err != nil {
+		s.logger.Errorw("failed to save audit log", "action", action, "targetType", targetType, "targetID", targetID, "error", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (s *AuditService) GetAll(ctx context.Context, filter models.AuditLogFilter) ([]*models.AuditLog, int, error) {
+	logs, total, err := s.auditRepo.FindAll(ctx, filter)
+	if err != nil {
+		s.logger.Errorw("failed to find audit logs", "error", err.Error())
+		return nil, 0, err
+	}
+
+	return logs, total, nil
+}
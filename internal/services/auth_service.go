@@ -1,14 +1,17 @@
 package services
 
 import (
+	"cmp"
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/google/uuid"
 	"github.com/wanafiq/feed-api/internal/config"
 	"github.com/wanafiq/feed-api/internal/constants"
 	"github.com/wanafiq/feed-api/internal/email"
+	"github.com/wanafiq/feed-api/internal/middleware"
 	"github.com/wanafiq/feed-api/internal/models"
 	"github.com/wanafiq/feed-api/internal/repository"
 	"github.com/wanafiq/feed-api/internal/types"
@@ -25,6 +28,8 @@ type AuthService struct {
 	roleRepo     repository.RoleRepository
 	tokenRepo    repository.TokenRepository
 	emailService *EmailService
+	eventService *EventService
+	auditService *AuditService
 }
 
 func NewAuthService(
@@ -34,8 +39,10 @@ func NewAuthService(
 	roleRepo repository.RoleRepository,
 	tokenRepo repository.TokenRepository,
 	emailService *EmailService,
+	eventService *EventService,
+	auditService *AuditService,
 ) *AuthService {
-	return &AuthService{
+	s := &AuthService{
 		config:       config,
 		db:           db,
 		logger:       logger,
@@ -43,7 +50,13 @@ func NewAuthService(
 		roleRepo:     roleRepo,
 		tokenRepo:    tokenRepo,
 		emailService: emailService,
+		eventService: eventService,
+		auditService: auditService,
 	}
+
+	eventService.Subscribe(constants.EventUserRegistered, "confirmation_email", s.sendConfirmationEmail)
+
+	return s
 }
 
 func (s *AuthService) Register(ctx context.Context, req *types.RegisterRequest) (*models.User, error) {
@@ -75,6 +88,7 @@ func (s *AuthService) Register(ctx context.Context, req *types.RegisterRequest)
 		Email:     req.Email,
 		Password:  hashedPassword,
 		RoleID:    role.ID,
+		Locale:    cmp.Or(req.Locale, email.DefaultLocale),
 		IsActive:  false,
 		CreatedAt: time.Now(),
 		CreatedBy: req.Email,
@@ -87,32 +101,8 @@ func (s *AuthService) Register(ctx context.Context, req *types.RegisterRequest)
 			return err
 		}
 
-		rawToken := uuid.New().String()
-		hashedToken, err := utils.Hash(rawToken)
-		if err != nil {
-			s.logger.Errorw("failed to hash confirmation token", "token", rawToken, "error", err.Error())
-			return err
-		}
-
-		token := models.Token{
-			Type:      constants.ConfirmationToken,
-			Value:     hashedToken,
-			ExpiredAt: time.Now().Add(constants.ConfirmationTokenExpireTime),
-			UserID:    user.ID,
-		}
-
-		if err := s.tokenRepo.Save(ctx, tx, &token); err != nil {
-			s.logger.Errorw("failed to save confirmation token", "token", rawToken, "error", err.Error())
-			return err
-		}
-
-		data := types.ConfirmationEmailData{
-			Username:      user.Username,
-			ActivationUrl: fmt.Sprintf("%s/confirm/%s", s.config.Url.Web, rawToken),
-		}
-
-		if err := s.emailService.Send(email.ConfirmationEmail, data, &user); err != nil {
-			s.logger.Errorw("failed to send confirmation email", "error", err.Error())
+		event := types.UserRegisteredEvent{UserID: user.ID}
+		if err := s.eventService.Publish(ctx, tx, constants.EventUserRegistered, event, user.Email); err != nil {
 			return err
 		}
 
@@ -126,18 +116,76 @@ func (s *AuthService) Register(ctx context.Context, req *types.RegisterRequest)
 	return &user, nil
 }
 
-func (s *AuthService) Login(ctx context.Context, req *types.LoginRequest) (string, error) {
+// sendConfirmationEmail handles user.registered. The token is created here rather
+// than at registration so the raw token never sits in the events table.
+func (s *AuthService) sendConfirmationEmail(ctx context.Context, event *models.Event) error {
+	var payload types.UserRegisteredEvent
+	if err := json.Unmarshal(event.Payload, &payload); err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.FindByID(ctx, payload.UserID)
+	if err != nil {
+		s.logger.Errorw("failed to find user by id", "userID", payload.UserID, "error", err.Error())
+		return err
+	}
+
+	rawToken := uuid.New().String()
+	hashedToken, err := utils.Hash(rawToken)
+	if err != nil {
+		s.logger.Errorw("failed to hash confirmation token", "error", err.Error())
+		return err
+	}
+
+	token := models.Token{
+		Type:      constants.ConfirmationToken,
+		Value:     hashedToken,
+		ExpiredAt: time.Now().Add(constants.ConfirmationTokenExpireTime),
+		UserID:    user.ID,
+	}
+
+	if err := s.tokenRepo.Save(ctx, nil, &token); err != nil {
+		s.logger.Errorw("failed to save confirmation token", "userID", user.ID, "error", err.Error())
+		return err
+	}
+
+	data := types.ConfirmationEmailData{
+		Username:      user.Username,
+		ActivationUrl: fmt.Sprintf("%s/confirm/%s", s.config.Url.Web, rawToken),
+	}
+
+	if err := s.emailService.Send(ctx, email.ConfirmationEmail, data, user); err != nil {
+		s.logger.Errorw("failed to send confirmation email", "userID", user.ID, "error", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// Login returns a token for the user with the given credentials. Every attempt
+// is audited, client carries the IP and user agent it came from. Auditing logins
+// is best effort: a failed audit write is logged but does not change the
+// outcome, so the audit log being down locks nobody out. Attempts are not rate
+// limited here, that belongs in front of the API.
+func (s *AuthService) Login(ctx context.Context, client middleware.UserContext, req *types.LoginRequest) (string, error) {
+	attempt := map[string]string{"email": req.Email}
+
 	user, err := s.userRepo.FindByEmail(ctx, req.Email)
 	if err != nil {
 		s.logger.Errorw("failed to find user by email", "email", req.Email, "error", err.Error())
+		_ = s.auditService.Record(ctx, nil, client, constants.AuditLoginFailed, constants.AuditTargetUser, "", nil, attempt)
 		return "", constants.ErrUnauthorized
 	}
 
 	if ok := utils.VerifyHash(user.Password, req.Password); !ok {
 		s.logger.Errorw("failed to verify password", "email", req.Email)
+		_ = s.auditService.Record(ctx, nil, client, constants.AuditLoginFailed, constants.AuditTargetUser, user.ID, nil, attempt)
 		return "", constants.ErrUnauthorized
 	}
 
+	client.ID = user.ID
+	_ = s.auditService.Record(ctx, nil, client, constants.AuditLogin, constants.AuditTargetUser, user.ID, nil, nil)
+
 	secret := s.config.Jwt.Secret
 	duration := time.Duration(s.config.Jwt.ExpiryInHours) * time.Hour
 	expiredAt := time.Now().Add(duration)
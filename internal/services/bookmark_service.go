@@ -0,0 +1,145 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/wanafiq/feed-api/internal/config"
+	"github.com/wanafiq/feed-api/internal/constants"
+	"github.com/wanafiq/feed-api/internal/models"
+	"github.com/wanafiq/feed-api/internal/repository"
+	"github.com/wanafiq/feed-api/internal/utils"
+	"go.uber.org/zap"
+)
+
+type BookmarkService struct {
+	config       *config.Config
+	db           *sql.DB
+	logger       *zap.SugaredLogger
+	bookmarkRepo repository.BookmarkRepository
+	postService  *PostService
+}
+
+func NewBookmarkService(config *config.Config, db *sql.DB, logger *zap.SugaredLogger, bookmarkRepo repository.BookmarkRepository, postService *PostService) *BookmarkService {
+	return &BookmarkService{
+		config:       config,
+		db:           db,
+		logger:       logger,
+		bookmarkRepo: bookmarkRepo,
+		postService:  postService,
+	}
+}
+
+// Add bookmarks a post the user can view, optionally inside one of the user's collections.
+func (s *BookmarkService) Add(ctx context.Context, userID string, postID string, collectionID string) (*models.Bookmark, error) {
+	post, err := s.postService.GetPostByID(ctx, userID, postID)
+	if err != nil {
+		return nil, err
+	}
+
+	bookmark := &models.Bookmark{
+		UserID: userID,
+		PostID: post.ID,
+	}
+
+	if collectionID != "" {
+		collection, err := s.bookmarkRepo.FindCollectionByID(ctx, userID, collectionID)
+		if err != nil {
+			s.logger.Errorw("failed to find bookmark collection", "collectionID", collectionID, "error", err.Error())
+			return nil, err
+		}
+		bookmark.CollectionID = &collection.ID
+	}
+
+	if err := s.bookmarkRepo.Save(ctx, nil, bookmark); err != nil {
+		s.logger.Errorw("failed to save bookmark", "userID", userID, "postID", postID, "error", err.Error())
+		return nil, err
+	}
+
+	return bookmark, nil
+}
+
+func (s *BookmarkService) Remove(ctx context.Context, userID string, postID string) error {
+	if err := s.bookmarkRepo.Delete(ctx, nil, userID, postID); err != nil {
+		s.logger.Errorw("failed to delete bookmark", "userID", userID, "postID", postID, "error", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// GetAll returns a page of the user's bookmarks and the cursor for the next page,
+// which is empty on the last page.
+func (s *BookmarkService) GetAll(ctx context.Context, userID string, collectionID string, limit int, cursor string) ([]*models.Bookmark, string, error) {
+	filter := models.BookmarkFilter{
+		UserID:       userID,
+		CollectionID: collectionID,
+		Limit:        limit + 1,
+	}
+
+	if cursor != "" {
+		cursorTime, cursorID, err := utils.DecodeCursor(cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		filter.CursorTime = &cursorTime
+		filter.CursorID = cursorID
+	}
+
+	bookmarks, err := s.bookmarkRepo.FindAll(ctx, filter)
+	if err != nil {
+		s.logger.Errorw("failed to find bookmarks", "userID", userID, "error", err.Error())
+		return nil, "", err
+	}
+
+	var next string
+	if len(bookmarks) > limit {
+		bookmarks = bookmarks[:limit]
+		last := bookmarks[len(bookmarks)-1]
+		next = utils.EncodeCursor(last.CreatedAt, last.ID)
+	}
+
+	return bookmarks, next, nil
+}
+
+func (s *BookmarkService) CreateCollection(ctx context.Context, userID string, name string) (*models.BookmarkCollection, error) {
+	collection := &models.BookmarkCollection{
+		UserID: userID,
+		Name:   name,
+	}
+
+	if err := s.bookmarkRepo.SaveCollection(ctx, nil, collection); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, constants.ErrCollectionExists
+		}
+		s.logger.Errorw("failed to save bookmark collection", "userID", userID, "name", name, "error", err.Error())
+		return nil, err
+	}
+
+	return collection, nil
+}
+
+func (s *BookmarkService) GetCollections(ctx context.Context, userID string) ([]*models.BookmarkCollection, error) {
+	collections, err := s.bookmarkRepo.FindCollections(ctx, userID)
+	if err != nil {
+		s.logger.Errorw("failed to find bookmark collections", "userID", userID, "error", err.Error())
+		return nil, err
+	}
+
+	return collections, nil
+}
+
+// DeleteCollection removes a collection. Bookmarks inside it are kept without a collection.
+func (s *BookmarkService) DeleteCollection(ctx context.Context, userID string, collectionID string) error {
+	if _, err := s.bookmarkRepo.FindCollectionByID(ctx, userID, collectionID); err != nil {
+		s.logger.Errorw("failed to find bookmark collection", "collectionID", collectionID, "error", err.Error())
+		return err
+	}
+
+	if err := s.bookmarkRepo.DeleteCollection(ctx, nil, userID, collectionID); err != nil {
+		s.logger.Errorw("failed to delete bookmark collection", "collectionID", collectionID, "error", err.Error())
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"github.com/wanafiq/feed-api/internal/config"
+	"github.com/wanafiq/feed-api/internal/constants"
+	"github.com/wanafiq/feed-api/internal/models"
+	"github.com/wanafiq/feed-api/internal/repository"
+	"go.uber.org/zap"
+	"time"
+)
+
+const (
+	dashboardTopPostsLimit   = 5
+	dashboardActivitiesLimit = 20
+)
+
+type DashboardService struct {
+	config        *config.Config
+	db            *sql.DB
+	logger        *zap.SugaredLogger
+	dashboardRepo repository.DashboardRepository
+}
+
+func NewDashboardService(config *config.Config, db *sql.DB, logger *zap.SugaredLogger, dashboardRepo repository.DashboardRepository) *DashboardService {
+	return &DashboardService{
+		config:        config,
+		db:            db,
+		logger:        logger,
+		dashboardRepo: dashboardRepo,
+	}
+}
+
+func (s *DashboardService) Get(ctx context.Context, userID string, from time.Time, to time.Time) (*models.Dashboard, error) {
+	dashboard := &models.Dashboard{
+		From: from,
+		To:   to,
+	}
+
+	var err error
+
+	if dashboard.Posts, err = s.dashboardRepo.CountPosts(ctx, userID); err != nil {
+		s.logger.Errorw("failed to count posts", "userID", userID, "error", err.Error())
+		return nil, err
+	}
+
+	if dashboard.Followers, err = s.dashboardRepo.CountFollowers(ctx, userID); err != nil {
+		s.logger.Errorw("failed to count followers", "userID", userID, "error", err.Error())
+		return nil, err
+	}
+
+	if dashboard.FollowerGrowth, err = s.dashboardRepo.FindFollowerGrowth(ctx, userID, from, to); err != nil {
+		s.logger.Errorw("failed to find follower growth", "userID", userID, "error", err.Error())
+		return nil, err
+	}
+
+	dashboard.TopByViews, err = s.dashboardRepo.FindTopPosts(ctx, userID, from, to, constants.TopPostsByViews, dashboardTopPostsLimit)
+	if err != nil {
+		s.logger.Errorw("failed to find top posts by views", "userID", userID, "error", err.Error())
+		return nil, err
+	}
+
+	dashboard.TopByReactions, err = s.dashboardRepo.FindTopPosts(ctx, userID, from, to, constants.TopPostsByReactions, dashboardTopPostsLimit)
+	if err != nil {
+		s.logger.Errorw("failed to find top posts by reactions", "userID", userID, "error", err.Error())
+		return nil, err
+	}
+
+	if dashboard.RecentActivities, err = s.dashboardRepo.FindRecentActivities(ctx, userID, dashboardActivitiesLimit); err != nil {
+		s.logger.Errorw("failed to find recent activities", "userID", userID, "error", err.Error())
+		return nil, err
+	}
+
+	return dashboard, nil
+}
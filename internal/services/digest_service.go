@@ -0,0 +1,256 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/wanafiq/feed-api/internal/config"
+	"github.com/wanafiq/feed-api/internal/constants"
+	"github.com/wanafiq/feed-api/internal/email"
+	"github.com/wanafiq/feed-api/internal/models"
+	"github.com/wanafiq/feed-api/internal/repository"
+	"github.com/wanafiq/feed-api/internal/types"
+	"go.uber.org/zap"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// DigestService periodically emails users the posts of the people they follow
+// and their unread notifications. Each digest picks up where the previous one
+// ended, and is recorded before it is sent, so the same content is never sent
+// twice; a digest that fails to send is dropped and retried on the next run.
+type DigestService struct {
+	config              *config.Config
+	db                  *sql.DB
+	logger              *zap.SugaredLogger
+	digestRepo          repository.DigestRepository
+	notificationService *NotificationService
+	emailService        *EmailService
+}
+
+func NewDigestService(
+	config *config.Config,
+	db *sql.DB,
+	logger *zap.SugaredLogger,
+	digestRepo repository.DigestRepository,
+	notificationService *NotificationService,
+	emailService *EmailService,
+) *DigestService {
+	return &DigestService{
+		config:              config,
+		db:                  db,
+		logger:              logger,
+		digestRepo:          digestRepo,
+		notificationService: notificationService,
+		emailService:        emailService,
+	}
+}
+
+// Run sends due digests on every interval until ctx is cancelled.
+func (s *DigestService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.config.Digest.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.sendDue(ctx)
+		}
+	}
+}
+
+// sendDue sends batches of due digests until none are left. It stops at the
+// first failed email, the mail server is likely down for the others as well.
+func (s *DigestService) sendDue(ctx context.Context) {
+	for ctx.Err() == nil {
+		recipients, err := s.digestRepo.FindDue(ctx, s.config.Digest.DefaultFrequency, s.config.Digest.Interval, s.config.Digest.BatchSize)
+		if err != nil {
+			s.logger.Errorw("failed to find due digests", "error", err.Error())
+			return
+		}
+
+		for _, recipient := range recipients {
+			if err := s.send(ctx, recipient); err != nil {
+				return
+			}
+		}
+
+		if len(recipients) < s.config.Digest.BatchSize {
+			return
+		}
+	}
+}
+
+func (s *DigestService) send(ctx context.Context, recipient *models.DigestRecipient) error {
+	user := &recipient.User
+	until := time.Now()
+	since := until.Add(-digestPeriod(recipient.Frequency))
+	if recipient.LastPeriodEnd != nil {
+		since = *recipient.LastPeriodEnd
+	}
+
+	posts, postCount, err := s.digestRepo.FindPosts(ctx, user.ID, since, until, s.config.Digest.MaxPosts)
+	if err != nil {
+		s.logger.Errorw("failed to find digest posts", "userID", user.ID, "error", err.Error())
+		return err
+	}
+
+	filter := models.NotificationFilter{
+		UnreadOnly: true,
+		Since:      &since,
+		Until:      &until,
+		Limit:      s.config.Digest.MaxNotifications,
+	}
+	notifications, _, err := s.notificationService.GetAll(ctx, user.ID, filter)
+	if err != nil {
+		return err
+	}
+
+	digest := &models.Digest{
+		UserID:            user.ID,
+		Frequency:         recipient.Frequency,
+		PeriodStart:       since,
+		PeriodEnd:         until,
+		PostCount:         postCount,
+		NotificationCount: notifications.UnreadCount,
+	}
+	if err := s.digestRepo.Save(ctx, digest); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		s.logger.Errorw("failed to save digest", "userID", user.ID, "error", err.Error())
+		return err
+	}
+
+	// nothing new, the digest is only recorded to move on to the next period
+	if postCount == 0 && notifications.UnreadCount == 0 {
+		return nil
+	}
+
+	unsubscribeURL := s.unsubscribeURL(user.ID)
+	data := types.DigestEmailData{
+		Username:          user.Username,
+		Frequency:         recipient.Frequency,
+		MorePosts:         postCount - len(posts),
+		MoreNotifications: notifications.UnreadCount - len(notifications.Notifications),
+		NotificationsUrl:  s.config.Url.Web + "/notifications",
+		UnsubscribeUrl:    unsubscribeURL,
+	}
+	for _, post := range posts {
+		data.Posts = append(data.Posts, types.DigestPostData{
+			Title:   post.Title,
+			Author:  post.AuthorUsername,
+			Excerpt: post.Excerpt,
+			Url:     fmt.Sprintf("%s/posts/%s", s.config.Url.Web, post.ID),
+		})
+	}
+	for _, notification := range notifications.Notifications {
+		data.Notifications = append(data.Notifications, notification.Message)
+	}
+
+	// RFC 8058 one-click unsubscribe from the mail client
+	headers := map[string]string{
+		"List-Unsubscribe":      "<" + unsubscribeURL + ">",
+		"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+	}
+
+	if err := s.emailService.SendWithHeaders(ctx, email.DigestEmail, data, user, headers); err != nil {
+		if err := s.digestRepo.Delete(ctx, digest.ID); err != nil {
+			s.logger.Errorw("failed to delete unsent digest", "digestID", digest.ID, "error", err.Error())
+		}
+		return err
+	}
+
+	if err := s.digestRepo.MarkSent(ctx, digest.ID); err != nil {
+		s.logger.Errorw("failed to mark digest sent", "digestID", digest.ID, "error", err.Error())
+	}
+
+	return nil
+}
+
+func (s *DigestService) GetSettings(ctx context.Context, userID string) (*models.DigestSettings, error) {
+	frequency, err := s.digestRepo.FindFrequency(ctx, userID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		s.logger.Errorw("failed to find digest frequency", "userID", userID, "error", err.Error())
+		return nil, err
+	}
+	if frequency == "" {
+		frequency = s.config.Digest.DefaultFrequency
+	}
+
+	return &models.DigestSettings{Frequency: frequency}, nil
+}
+
+func (s *DigestService) UpdateSettings(ctx context.Context, userID string, req *types.DigestSettingsRequest) (*models.DigestSettings, error) {
+	if err := s.digestRepo.SaveFrequency(ctx, userID, req.Frequency); err != nil {
+		s.logger.Errorw("failed to save digest frequency", "userID", userID, "error", err.Error())
+		return nil, err
+	}
+
+	return &models.DigestSettings{Frequency: req.Frequency}, nil
+}
+
+// CheckUnsubscribe verifies that token was signed for userID and expires, and
+// that the link has not expired.
+func (s *DigestService) CheckUnsubscribe(userID string, expires string, token string) error {
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > expiresAt {
+		return constants.ErrInvalidUnsubscribe
+	}
+
+	expected := s.unsubscribeToken(userID, expiresAt)
+	if !hmac.Equal([]byte(token), []byte(expected)) {
+		return constants.ErrInvalidUnsubscribe
+	}
+
+	return nil
+}
+
+// Unsubscribe turns digests off for userID if the link was signed for them.
+func (s *DigestService) Unsubscribe(ctx context.Context, userID string, expires string, token string) error {
+	if err := s.CheckUnsubscribe(userID, expires, token); err != nil {
+		return err
+	}
+
+	if err := s.digestRepo.SaveFrequency(ctx, userID, constants.DigestFrequencyOff); err != nil {
+		s.logger.Errorw("failed to save digest frequency", "userID", userID, "error", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// unsubscribeURL is a link that unsubscribes userID without logging in. It
+// works for the configured TTL after the digest is sent.
+func (s *DigestService) unsubscribeURL(userID string) string {
+	expires := time.Now().Add(s.config.Digest.UnsubscribeTTL).Unix()
+
+	query := url.Values{}
+	query.Set("user", userID)
+	query.Set("expires", strconv.FormatInt(expires, 10))
+	query.Set("token", s.unsubscribeToken(userID, expires))
+
+	return s.config.Url.API + "/api/v1/digest/unsubscribe?" + query.Encode()
+}
+
+func (s *DigestService) unsubscribeToken(userID string, expires int64) string {
+	mac := hmac.New(sha256.New, []byte(s.config.Digest.UnsubscribeSecret))
+	fmt.Fprintf(mac, "digest-unsubscribe:%s:%d", userID, expires)
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func digestPeriod(frequency string) time.Duration {
+	if frequency == constants.DigestFrequencyDaily {
+		return 24 * time.Hour
+	}
+
+	return 7 * 24 * time.Hour
+}
@@ -1,106 +1,60 @@
 package services
 
 import (
-	"bytes"
+	"context"
+	"github.com/google/uuid"
 	"github.com/wanafiq/feed-api/internal/config"
-	"github.com/wanafiq/feed-api/internal/constants"
 	"github.com/wanafiq/feed-api/internal/email"
+	"github.com/wanafiq/feed-api/internal/mailer"
 	"github.com/wanafiq/feed-api/internal/models"
 	"go.uber.org/zap"
-	gomail "gopkg.in/mail.v2"
-	"html/template"
+	"time"
 )
 
 type EmailService struct {
-	config *config.Config
-	logger *zap.SugaredLogger
+	config    *config.Config
+	logger    *zap.SugaredLogger
+	mailer    mailer.Mailer
+	templates *email.Registry
 }
 
-func NewEmailService(config *config.Config, logger *zap.SugaredLogger) *EmailService {
+func NewEmailService(config *config.Config, logger *zap.SugaredLogger, mailer mailer.Mailer, templates *email.Registry) *EmailService {
 	return &EmailService{
-		config: config,
-		logger: logger,
+		config:    config,
+		logger:    logger,
+		mailer:    mailer,
+		templates: templates,
 	}
 }
 
-func (s *EmailService) Send(emailType string, data any, user *models.User) error {
-	env := s.config.Env
-	if env == constants.DevEnv {
-		s.logger.Infow("skipping email sending", "env", env)
-		return nil
-	}
-
-	var err error
-	var tmpl *template.Template
-	var subject string
-	var htmlBody string
+// Send renders an email in the user's locale and sends it to them. data must be
+// of the type the email's definition declares.
+func (s *EmailService) Send(ctx context.Context, emailType string, data any, user *models.User) error {
+	return s.SendWithHeaders(ctx, emailType, data, user, nil)
+}
 
-	switch emailType {
-	case email.ConfirmationEmail:
-		tmpl, err = s.getEmailTemplate(email.ConfirmationEmailTemplate)
-		subject, err = s.getEmailSubject(tmpl)
-		htmlBody, err = s.getEmailHtml(tmpl, data)
-	default:
-		return err
-	}
+// SendWithHeaders is Send with extra message headers.
+func (s *EmailService) SendWithHeaders(ctx context.Context, emailType string, data any, user *models.User, headers map[string]string) error {
+	rendered, err := s.templates.Render(emailType, user.Locale, data)
 	if err != nil {
+		s.logger.Errorw("failed to render email", "emailType", emailType, "locale", user.Locale, "error", err.Error())
 		return err
 	}
 
-	if err := s.send(user.Email, subject, htmlBody); err != nil {
-		return err
+	msg := &mailer.Message{
+		ID:      uuid.NewString(),
+		From:    s.config.Smtp.From,
+		To:      user.Email,
+		Subject: rendered.Subject,
+		HTML:    rendered.HTML,
+		Text:    rendered.Text,
+		SentAt:  time.Now(),
+		Headers: headers,
 	}
-
-	return nil
-}
-
-func (s *EmailService) send(to, subject, htmlBody string) error {
-	msg := gomail.NewMessage()
-	msg.SetHeader("From", s.config.Smtp.From)
-	msg.SetHeader("To", to)
-	msg.SetHeader("Subject", subject)
-	msg.AddAlternative("text/html", htmlBody)
-
-	dialer := gomail.NewDialer(
-		s.config.Smtp.Host,
-		s.config.Smtp.Port,
-		s.config.Smtp.Username,
-		s.config.Smtp.Password,
-	)
-	if err := dialer.DialAndSend(msg); err != nil {
+	if err := s.mailer.Send(ctx, msg); err != nil {
+		s.logger.Errorw("failed to send email", "emailType", emailType, "to", user.Email, "error", err.Error())
 		return err
 	}
 
 	return nil
 }
-
-func (s *EmailService) getEmailTemplate(templateName string) (*template.Template, error) {
-	tmpl, err := template.ParseFS(email.Templates, templateName)
-	if err != nil {
-		return nil, err
-	}
-
-	return tmpl, nil
-}
-
-func (s *EmailService) getEmailSubject(template *template.Template) (string, error) {
-	subject := new(bytes.Buffer)
-
-	err := template.ExecuteTemplate(subject, "subject", nil)
-	if err != nil {
-		return "", err
-	}
-
-	return subject.String(), nil
-}
-
-func (s *EmailService) getEmailHtml(template *template.Template, data any) (string, error) {
-	body := new(bytes.Buffer)
-
-	err := template.ExecuteTemplate(body, "body", data)
-	if err != nil {
-		return "", err
-	}
-
-	return body.String(), nil
-}
@@ -0,0 +1,195 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"github.com/wanafiq/feed-api/internal/config"
+	"github.com/wanafiq/feed-api/internal/constants"
+	"github.com/wanafiq/feed-api/internal/middleware"
+	"github.com/wanafiq/feed-api/internal/models"
+	"github.com/wanafiq/feed-api/internal/repository"
+	"go.uber.org/zap"
+	"strconv"
+	"time"
+)
+
+// EventHandler processes one event. Events are delivered at least once, so a
+// handler may see the same event again after a crash or a failed acknowledgement.
+type EventHandler func(ctx context.Context, event *models.Event) error
+
+type eventSubscriber struct {
+	name    string
+	handler EventHandler
+}
+
+// EventService is a transactional outbox: Publish stores an event in the
+// transaction of the change it describes, and Run dispatches stored events to
+// their handlers, retrying failures with exponential backoff until they succeed
+// or are dead-lettered.
+type EventService struct {
+	config       *config.Config
+	db           *sql.DB
+	logger       *zap.SugaredLogger
+	eventRepo    repository.EventRepository
+	auditService *AuditService
+
+	subscribers map[string][]eventSubscriber // event type -> subscribers
+	handlers    map[string]EventHandler      // subscriber name -> handler
+}
+
+func NewEventService(config *config.Config, db *sql.DB, logger *zap.SugaredLogger, eventRepo repository.EventRepository, auditService *AuditService) *EventService {
+	return &EventService{
+		config:       config,
+		db:           db,
+		logger:       logger,
+		eventRepo:    eventRepo,
+		auditService: auditService,
+		subscribers:  make(map[string][]eventSubscriber),
+		handlers:     make(map[string]EventHandler),
+	}
+}
+
+// Subscribe registers handler under a unique name for an event type. Each
+// subscriber gets its own copy of every event, retried independently. It must
+// be called before Run and before events are published.
+func (s *EventService) Subscribe(eventType string, name string, handler EventHandler) {
+	s.subscribers[eventType] = append(s.subscribers[eventType], eventSubscriber{name: name, handler: handler})
+	s.handlers[name] = handler
+}
+
+// Publish stores an event for every subscriber of eventType within tx.
+func (s *EventService) Publish(ctx context.Context, tx *sql.Tx, eventType string, payload any, createdBy string) error {
+	subscribers := s.subscribers[eventType]
+	if len(subscribers) == 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	for _, subscriber := range subscribers {
+		event := &models.Event{
+			EventType: eventType,
+			Handler:   subscriber.name,
+			Payload:   data,
+			CreatedBy: createdBy,
+		}
+		if err := s.eventRepo.Save(ctx, tx, event); err != nil {
+			s.logger.Errorw("failed to save event", "eventType", eventType, "handler", subscriber.name, "error", err.Error())
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetAll lists stored events, optionally only those with status.
+func (s *EventService) GetAll(ctx context.Context, status string, offset int, limit int) ([]*models.Event, int, error) {
+	events, total, err := s.eventRepo.FindAll(ctx, status, offset, limit)
+	if err != nil {
+		s.logger.Errorw("failed to find events", "status", status, "error", err.Error())
+		return nil, 0, err
+	}
+
+	return events, total, nil
+}
+
+// Retry queues a dead-lettered event again.
+func (s *EventService) Retry(ctx context.Context, userCtx middleware.UserContext, eventID int64) error {
+	return withTx(ctx, s.db, func(tx *sql.Tx) error {
+		if err := s.eventRepo.Retry(ctx, tx, eventID, userCtx.Email); err != nil {
+			return err
+		}
+
+		return s.auditService.Record(ctx, tx, userCtx, constants.AuditEventRetried, constants.AuditTargetEvent, strconv.FormatInt(eventID, 10), nil, nil)
+	})
+}
+
+// Run dispatches due events on every poll interval until ctx is cancelled.
+func (s *EventService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.config.Events.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.dispatch(ctx)
+		}
+	}
+}
+
+// dispatch processes batches of due events until none are left.
+func (s *EventService) dispatch(ctx context.Context) {
+	for ctx.Err() == nil {
+		events, err := s.eventRepo.ClaimDue(ctx, s.config.Events.BatchSize, s.config.Events.Lease)
+		if err != nil {
+			s.logger.Errorw("failed to claim events", "error", err.Error())
+			return
+		}
+
+		for _, event := range events {
+			s.process(ctx, event)
+		}
+
+		if len(events) < s.config.Events.BatchSize {
+			return
+		}
+	}
+}
+
+func (s *EventService) process(ctx context.Context, event *models.Event) {
+	err := s.handle(ctx, event)
+	if err == nil {
+		if err := s.eventRepo.MarkCompleted(ctx, event.ID); err != nil {
+			s.logger.Errorw("failed to mark event completed", "eventID", event.ID, "error", err.Error())
+		}
+		return
+	}
+
+	if event.Attempts >= s.config.Events.MaxAttempts {
+		s.logger.Errorw("event dead-lettered", "eventID", event.ID, "eventType", event.EventType,
+			"handler", event.Handler, "attempts", event.Attempts, "error", err.Error())
+		if err := s.eventRepo.MarkDead(ctx, event.ID, err.Error()); err != nil {
+			s.logger.Errorw("failed to mark event dead", "eventID", event.ID, "error", err.Error())
+		}
+		return
+	}
+
+	nextAttemptAt := time.Now().Add(s.backoff(event.Attempts))
+	s.logger.Warnw("event failed, will retry", "eventID", event.ID, "eventType", event.EventType,
+		"handler", event.Handler, "attempts", event.Attempts, "nextAttemptAt", nextAttemptAt, "error", err.Error())
+	if err := s.eventRepo.MarkFailed(ctx, event.ID, err.Error(), nextAttemptAt); err != nil {
+		s.logger.Errorw("failed to mark event failed", "eventID", event.ID, "error", err.Error())
+	}
+}
+
+func (s *EventService) handle(ctx context.Context, event *models.Event) (err error) {
+	handler, ok := s.handlers[event.Handler]
+	if !ok {
+		return fmt.Errorf("no handler named %q", event.Handler)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("handler panicked: %v", r)
+		}
+	}()
+
+	return handler(ctx, event)
+}
+
+// backoff doubles the retry delay with every attempt, up to the configured maximum.
+func (s *EventService) backoff(attempts int) time.Duration {
+	delay := s.config.Events.RetryBackoff
+	for i := 1; i < attempts && delay < s.config.Events.MaxBackoff; i++ {
+		delay *= 2
+	}
+
+	return min(delay, s.config.Events.MaxBackoff)
+}
@@ -0,0 +1,377 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"github.com/wanafiq/feed-api/internal/config"
+	"github.com/wanafiq/feed-api/internal/models"
+	"github.com/wanafiq/feed-api/internal/repository"
+	"go.uber.org/zap"
+	"time"
+)
+
+const (
+	FeedFormatRSS  = "rss"
+	FeedFormatAtom = "atom"
+	FeedFormatJSON = "json"
+
+	feedItemLimit = 20
+)
+
+// Feed is a list of published posts, independent of the output format.
+type Feed struct {
+	Title        string
+	Description  string
+	Link         string // page on the web frontend
+	SelfURL      string // address of the feed on this API
+	LastModified time.Time
+	ETag         string
+	Posts        []*models.Post // set by LoadPosts
+
+	filter models.PostFilter
+}
+
+type FeedService struct {
+	config      *config.Config
+	db          *sql.DB
+	logger      *zap.SugaredLogger
+	userRepo    repository.UserRepository
+	postRepo    repository.PostRepository
+	postService *PostService
+}
+
+func NewFeedService(config *config.Config, db *sql.DB, logger *zap.SugaredLogger, userRepo repository.UserRepository, postRepo repository.PostRepository, postService *PostService) *FeedService {
+	return &FeedService{
+		config:      config,
+		db:          db,
+		logger:      logger,
+		userRepo:    userRepo,
+		postRepo:    postRepo,
+		postService: postService,
+	}
+}
+
+// GetSiteFeed returns the feed of the latest published posts of everyone. path
+// is the route the feed was requested on, its self link is built on the API URL.
+func (s *FeedService) GetSiteFeed(ctx context.Context, path string) (*Feed, error) {
+	feed := &Feed{
+		Title:       "Latest posts",
+		Description: "Latest posts",
+		Link:        s.config.Url.Web,
+		SelfURL:     s.config.Url.API + path,
+	}
+
+	return s.stat(ctx, feed, models.PostFilter{})
+}
+
+// GetAuthorFeed returns the feed of the latest published posts written or co-written by a user.
+func (s *FeedService) GetAuthorFeed(ctx context.Context, userID string, path string) (*Feed, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		s.logger.Errorw("failed to find user by id", "userID", userID, "error", err.Error())
+		return nil, err
+	}
+
+	feed := &Feed{
+		Title:       fmt.Sprintf("Posts by %s", user.Username),
+		Description: fmt.Sprintf("Latest posts by %s", user.Username),
+		Link:        fmt.Sprintf("%s/users/%s", s.config.Url.Web, user.ID),
+		SelfURL:     s.config.Url.API + path,
+	}
+
+	return s.stat(ctx, feed, models.PostFilter{AuthorID: user.ID})
+}
+
+// GetTagFeed returns the feed of the latest published posts with a tag.
+func (s *FeedService) GetTagFeed(ctx context.Context, tag string, path string) (*Feed, error) {
+	feed := &Feed{
+		Title:       fmt.Sprintf("Posts tagged %s", tag),
+		Description: fmt.Sprintf("Latest posts tagged %s", tag),
+		Link:        fmt.Sprintf("%s/tags/%s", s.config.Url.Web, tag),
+		SelfURL:     s.config.Url.API + path,
+	}
+
+	return s.stat(ctx, feed, models.PostFilter{Tags: []string{tag}})
+}
+
+// Encode renders the feed with its posts' HTML content, returning the body and its content type.
+func (s *FeedService) Encode(feed *Feed, format string) ([]byte, string, error) {
+	if err := s.postService.RenderHTML(feed.Posts...); err != nil {
+		return nil, "", err
+	}
+
+	switch format {
+	case FeedFormatAtom:
+		body, err := s.encodeAtom(feed)
+		return body, "application/atom+xml; charset=utf-8", err
+	case FeedFormatJSON:
+		body, err := s.encodeJSON(feed)
+		return body, "application/feed+json; charset=utf-8", err
+	default:
+		body, err := s.encodeRSS(feed)
+		return body, "application/rss+xml; charset=utf-8", err
+	}
+}
+
+// stat sets Last-Modified and the ETag of a feed from the count and the latest
+// change of the posts matching filter, so a poll can be answered before any post
+// is loaded.
+func (s *FeedService) stat(ctx context.Context, feed *Feed, filter models.PostFilter) (*Feed, error) {
+	filter.Limit = feedItemLimit
+	filter.Published = true
+	filter.Content = true
+	feed.filter = filter
+
+	count, lastModified, err := s.postRepo.FindLastModified(ctx, filter)
+	if err != nil {
+		s.logger.Errorw("failed to find feed last modified", "error", err.Error())
+		return nil, err
+	}
+
+	if lastModified != nil {
+		feed.LastModified = *lastModified
+	}
+
+	hash := sha256.Sum256([]byte(fmt.Sprintf("%s|%d|%d", feed.SelfURL, count, feed.LastModified.UnixNano())))
+	feed.ETag = `"` + hex.EncodeToString(hash[:16]) + `"`
+
+	return feed, nil
+}
+
+// LoadPosts fills the feed with its latest posts.
+func (s *FeedService) LoadPosts(ctx context.Context, feed *Feed) error {
+	posts, _, err := s.postService.GetAll(ctx, feed.filter)
+	if err != nil {
+		return err
+	}
+	feed.Posts = posts
+
+	return nil
+}
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Atom    string     `xml:"xmlns:atom,attr"`
+	DC      string     `xml:"xmlns:dc,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	AtomLink      atomLink  `xml:"atom:link"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link"`
+	GUID        rssGUID `xml:"guid"`
+	PubDate     string  `xml:"pubDate"`
+	Author      string  `xml:"dc:creator,omitempty"`
+	Description string  `xml:"description"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+func (s *FeedService) encodeRSS(feed *Feed) ([]byte, error) {
+	rss := rssFeed{
+		Version: "2.0",
+		Atom:    "http://www.w3.org/2005/Atom",
+		DC:      "http://purl.org/dc/elements/1.1/",
+		Channel: rssChannel{
+			Title:       feed.Title,
+			Link:        feed.Link,
+			Description: feed.Description,
+			AtomLink:    atomLink{Href: feed.SelfURL, Rel: "self", Type: "application/rss+xml"},
+		},
+	}
+	if !feed.LastModified.IsZero() {
+		rss.Channel.LastBuildDate = feed.LastModified.UTC().Format(time.RFC1123Z)
+	}
+
+	for _, post := range feed.Posts {
+		link := s.postService.postURL(post)
+		rss.Channel.Items = append(rss.Channel.Items, rssItem{
+			Title:       post.Title,
+			Link:        link,
+			GUID:        rssGUID{IsPermaLink: true, Value: link},
+			PubDate:     postPublished(post).UTC().Format(time.RFC1123Z),
+			Author:      post.Author.Username,
+			Description: post.ContentHTML,
+		})
+	}
+
+	body, err := xml.MarshalIndent(rss, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), body...), nil
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"feed"`
+	Xmlns   string      `xml:"xmlns,attr"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomEntry struct {
+	ID        string      `xml:"id"`
+	Title     string      `xml:"title"`
+	Link      atomLink    `xml:"link"`
+	Published string      `xml:"published"`
+	Updated   string      `xml:"updated"`
+	Authors   []atomName  `xml:"author"`
+	Summary   string      `xml:"summary,omitempty"`
+	Content   atomContent `xml:"content"`
+}
+
+type atomName struct {
+	Name string `xml:"name"`
+}
+
+type atomContent struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+func (s *FeedService) encodeAtom(feed *Feed) ([]byte, error) {
+	updated := feed.LastModified
+	if updated.IsZero() {
+		updated = time.Now()
+	}
+
+	atom := atomFeed{
+		Xmlns:   "http://www.w3.org/2005/Atom",
+		ID:      feed.SelfURL,
+		Title:   feed.Title,
+		Updated: updated.UTC().Format(time.RFC3339),
+		Links: []atomLink{
+			{Href: feed.Link, Rel: "alternate", Type: "text/html"},
+			{Href: feed.SelfURL, Rel: "self", Type: "application/atom+xml"},
+		},
+	}
+
+	for _, post := range feed.Posts {
+		link := s.postService.postURL(post)
+		atom.Entries = append(atom.Entries, atomEntry{
+			ID:        link,
+			Title:     post.Title,
+			Link:      atomLink{Href: link, Rel: "alternate", Type: "text/html"},
+			Published: postPublished(post).UTC().Format(time.RFC3339),
+			Updated:   postModified(post).UTC().Format(time.RFC3339),
+			Authors:   feedAuthors(post, func(name string) atomName { return atomName{Name: name} }),
+			Summary:   post.Excerpt,
+			Content:   atomContent{Type: "html", Value: post.ContentHTML},
+		})
+	}
+
+	body, err := xml.MarshalIndent(atom, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), body...), nil
+}
+
+type jsonFeed struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url"`
+	FeedURL     string         `json:"feed_url"`
+	Description string         `json:"description,omitempty"`
+	Items       []jsonFeedItem `json:"items"`
+}
+
+type jsonFeedItem struct {
+	ID            string           `json:"id"`
+	URL           string           `json:"url"`
+	Title         string           `json:"title"`
+	ContentHTML   string           `json:"content_html"`
+	Summary       string           `json:"summary,omitempty"`
+	Image         string           `json:"image,omitempty"`
+	DatePublished string           `json:"date_published"`
+	DateModified  string           `json:"date_modified"`
+	Authors       []jsonFeedAuthor `json:"authors,omitempty"`
+}
+
+type jsonFeedAuthor struct {
+	Name string `json:"name"`
+}
+
+func (s *FeedService) encodeJSON(feed *Feed) ([]byte, error) {
+	jf := jsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       feed.Title,
+		HomePageURL: feed.Link,
+		FeedURL:     feed.SelfURL,
+		Description: feed.Description,
+		Items:       []jsonFeedItem{},
+	}
+
+	for _, post := range feed.Posts {
+		jf.Items = append(jf.Items, jsonFeedItem{
+			ID:            post.ID,
+			URL:           s.postService.postURL(post),
+			Title:         post.Title,
+			ContentHTML:   post.ContentHTML,
+			Summary:       post.Excerpt,
+			Image:         post.CoverImage,
+			DatePublished: postPublished(post).UTC().Format(time.RFC3339),
+			DateModified:  postModified(post).UTC().Format(time.RFC3339),
+			Authors:       feedAuthors(post, func(name string) jsonFeedAuthor { return jsonFeedAuthor{Name: name} }),
+		})
+	}
+
+	return json.MarshalIndent(jf, "", "  ")
+}
+
+// feedAuthors lists the author and co-authors of a post in the shape of a feed format.
+func feedAuthors[T any](post *models.Post, newAuthor func(name string) T) []T {
+	authors := post.Authors
+	if len(authors) == 0 {
+		authors = []models.User{post.Author}
+	}
+
+	var names []T
+	for _, author := range authors {
+		names = append(names, newAuthor(author.Username))
+	}
+	return names
+}
+
+func postPublished(post *models.Post) time.Time {
+	if post.PublishedAt != nil {
+		return *post.PublishedAt
+	}
+	return post.CreatedAt
+}
+
+func postModified(post *models.Post) time.Time {
+	if post.UpdatedAt != nil {
+		return *post.UpdatedAt
+	}
+	return postPublished(post)
+}
@@ -0,0 +1,265 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/wanafiq/feed-api/internal/config"
+	"github.com/wanafiq/feed-api/internal/constants"
+	"github.com/wanafiq/feed-api/internal/models"
+	"github.com/wanafiq/feed-api/internal/repository"
+	"github.com/wanafiq/feed-api/internal/storage"
+	"go.uber.org/zap"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+	"time"
+)
+
+const mediaGCBatchSize = 100
+
+// mediaTypes maps the accepted image types to the file extension they are stored with.
+var mediaTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+type MediaService struct {
+	config    *config.Config
+	db        *sql.DB
+	logger    *zap.SugaredLogger
+	mediaRepo repository.MediaRepository
+	storage   storage.Storage
+}
+
+func NewMediaService(config *config.Config, db *sql.DB, logger *zap.SugaredLogger, mediaRepo repository.MediaRepository, storage storage.Storage) *MediaService {
+	return &MediaService{
+		config:    config,
+		db:        db,
+		logger:    logger,
+		mediaRepo: mediaRepo,
+		storage:   storage,
+	}
+}
+
+// Upload stores an image with its thumbnails. The type is sniffed from the
+// content, the client supplied content type is ignored. The dimensions are
+// checked before the image is decoded, as a small file can claim a huge image.
+func (s *MediaService) Upload(ctx context.Context, userID string, file io.Reader, size int64) (*models.Media, error) {
+	maxSize := s.config.Media.MaxUploadSize
+	if size > maxSize {
+		return nil, constants.ErrMediaTooLarge
+	}
+
+	data, err := io.ReadAll(io.LimitReader(file, maxSize+1))
+	if err != nil {
+		s.logger.Errorw("failed to read upload", "userID", userID, "error", err.Error())
+		return nil, err
+	}
+	if int64(len(data)) > maxSize {
+		return nil, constants.ErrMediaTooLarge
+	}
+
+	contentType := http.DetectContentType(data)
+	ext, ok := mediaTypes[contentType]
+	if !ok {
+		return nil, constants.ErrUnsupportedMediaType
+	}
+
+	imgConfig, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, constants.ErrUnsupportedMediaType
+	}
+	if err := s.checkDimensions(imgConfig.Width, imgConfig.Height); err != nil {
+		return nil, err
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, constants.ErrUnsupportedMediaType
+	}
+
+	name := userID + "/" + uuid.NewString()
+	media := &models.Media{
+		UserID:      userID,
+		Key:         name + ext,
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		Width:       img.Bounds().Dx(),
+		Height:      img.Bounds().Dy(),
+	}
+
+	if err := s.storage.Put(ctx, media.Key, bytes.NewReader(data), contentType); err != nil {
+		s.logger.Errorw("failed to store upload", "key", media.Key, "error", err.Error())
+		return nil, err
+	}
+
+	thumbnails, err := s.storeThumbnails(ctx, name, img, contentType)
+	media.Thumbnails = thumbnails
+	if err != nil {
+		s.deleteFiles(ctx, media)
+		return nil, err
+	}
+
+	// the quota is checked and the upload saved with the user row locked, so
+	// concurrent uploads cannot both fit in the space left
+	err = withTx(ctx, s.db, func(tx *sql.Tx) error {
+		used, err := s.mediaRepo.SumSizeByUserID(ctx, tx, userID)
+		if err != nil {
+			s.logger.Errorw("failed to sum media size", "userID", userID, "error", err.Error())
+			return err
+		}
+		if used+media.TotalSize() > s.config.Media.UserQuota {
+			return constants.ErrMediaQuotaExceeded
+		}
+
+		if err := s.mediaRepo.Save(ctx, tx, media); err != nil {
+			s.logger.Errorw("failed to save media", "key", media.Key, "error", err.Error())
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		s.deleteFiles(ctx, media)
+		return nil, err
+	}
+
+	s.setURLs(media)
+
+	return media, nil
+}
+
+// checkDimensions rejects images larger than the configured width, height or
+// pixel count, before they are decoded into memory.
+func (s *MediaService) checkDimensions(width int, height int) error {
+	if width <= 0 || height <= 0 {
+		return constants.ErrUnsupportedMediaType
+	}
+
+	if width > s.config.Media.MaxImageWidth ||
+		height > s.config.Media.MaxImageHeight ||
+		int64(width)*int64(height) > s.config.Media.MaxImagePixels {
+		return constants.ErrImageTooLarge
+	}
+
+	return nil
+}
+
+// Run deletes orphaned uploads on every interval until ctx is cancelled.
+func (s *MediaService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.config.Media.GCInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.CollectOrphans(ctx)
+		}
+	}
+}
+
+// CollectOrphans deletes uploads no post has referenced for longer than the orphan TTL.
+func (s *MediaService) CollectOrphans(ctx context.Context) {
+	before := time.Now().Add(-s.config.Media.OrphanTTL)
+
+	for {
+		orphans, err := s.mediaRepo.FindOrphans(ctx, before, mediaGCBatchSize)
+		if err != nil {
+			s.logger.Errorw("failed to find orphaned media", "error", err.Error())
+			return
+		}
+
+		for _, media := range orphans {
+			if err := s.mediaRepo.Delete(ctx, nil, media.ID); err != nil {
+				// linked to a post again since it was found
+				if errors.Is(err, sql.ErrNoRows) {
+					continue
+				}
+				s.logger.Errorw("failed to delete media", "mediaID", media.ID, "error", err.Error())
+				return
+			}
+			s.deleteFiles(ctx, media)
+		}
+
+		if len(orphans) < mediaGCBatchSize {
+			return
+		}
+	}
+}
+
+// storeThumbnails stores a scaled down copy of img for every configured width
+// smaller than the image. PNG and GIF thumbnails stay PNG to keep transparency.
+func (s *MediaService) storeThumbnails(ctx context.Context, name string, img image.Image, contentType string) (models.Thumbnails, error) {
+	bounds := img.Bounds()
+
+	var thumbnails models.Thumbnails
+	for _, width := range s.config.Media.ThumbnailWidths {
+		if width <= 0 || width >= bounds.Dx() {
+			continue
+		}
+
+		height := max(1, bounds.Dy()*width/bounds.Dx())
+		dst := image.NewRGBA(image.Rect(0, 0, width, height))
+		draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Src, nil)
+
+		var buf bytes.Buffer
+		thumbnailType := "image/jpeg"
+		ext := ".jpg"
+		var err error
+		if contentType == "image/png" || contentType == "image/gif" {
+			thumbnailType = "image/png"
+			ext = ".png"
+			err = png.Encode(&buf, dst)
+		} else {
+			err = jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 85})
+		}
+		if err != nil {
+			s.logger.Errorw("failed to encode thumbnail", "name", name, "width", width, "error", err.Error())
+			return thumbnails, err
+		}
+
+		size := int64(buf.Len())
+		key := fmt.Sprintf("%s_w%d%s", name, width, ext)
+		if err := s.storage.Put(ctx, key, &buf, thumbnailType); err != nil {
+			s.logger.Errorw("failed to store thumbnail", "key", key, "error", err.Error())
+			return thumbnails, err
+		}
+
+		thumbnails = append(thumbnails, models.Thumbnail{Width: width, Height: height, Size: size, Key: key})
+	}
+
+	return thumbnails, nil
+}
+
+func (s *MediaService) deleteFiles(ctx context.Context, media *models.Media) {
+	keys := []string{media.Key}
+	for _, thumbnail := range media.Thumbnails {
+		keys = append(keys, thumbnail.Key)
+	}
+
+	for _, key := range keys {
+		if err := s.storage.Delete(ctx, key); err != nil {
+			s.logger.Errorw("failed to delete stored file", "key", key, "error", err.Error())
+		}
+	}
+}
+
+func (s *MediaService) setURLs(media *models.Media) {
+	media.URL = s.storage.URL(media.Key)
+	for i := range media.Thumbnails {
+		media.Thumbnails[i].URL = s.storage.URL(media.Thumbnails[i].Key)
+	}
+}
@@ -0,0 +1,298 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/wanafiq/feed-api/internal/config"
+	"github.com/wanafiq/feed-api/internal/constants"
+	"github.com/wanafiq/feed-api/internal/email"
+	"github.com/wanafiq/feed-api/internal/models"
+	"github.com/wanafiq/feed-api/internal/repository"
+	"github.com/wanafiq/feed-api/internal/types"
+	"go.uber.org/zap"
+	"slices"
+)
+
+// notificationTypes are the types a user can turn off.
+var notificationTypes = []string{
+	constants.NotificationFollow,
+	constants.NotificationFollowRequest,
+	constants.NotificationFollowAccepted,
+	constants.NotificationReaction,
+}
+
+// notificationText holds the notification messages of one locale. In messages,
+// %[1]s is the actors, %[2]s the reaction and %[3]q the post title.
+type notificationText struct {
+	two      string // two actors, e.g. "%s and %s"
+	many     string // the newest actor and how many others, e.g. "%s and %d others"
+	messages map[string]string
+}
+
+// notificationTexts are keyed by locale, see email.LocaleCandidates for the
+// fallback. Every type needs a message in email.DefaultLocale.
+var notificationTexts = map[string]notificationText{
+	"en": {
+		two:  "%s and %s",
+		many: "%s and %d others",
+		messages: map[string]string{
+			constants.NotificationFollow:         "%[1]s started following you",
+			constants.NotificationFollowRequest:  "%[1]s requested to follow you",
+			constants.NotificationFollowAccepted: "%[1]s accepted your follow request",
+			constants.NotificationReaction:       "%[1]s reacted with %[2]s to your post %[3]q",
+		},
+	},
+	"ms": {
+		two:  "%s dan %s",
+		many: "%s dan %d yang lain",
+		messages: map[string]string{
+			constants.NotificationFollow:         "%[1]s mula mengikuti anda",
+			constants.NotificationFollowRequest:  "%[1]s meminta untuk mengikuti anda",
+			constants.NotificationFollowAccepted: "%[1]s menerima permintaan ikutan anda",
+			constants.NotificationReaction:       "%[1]s memberi reaksi %[2]s pada siaran anda %[3]q",
+		},
+	},
+}
+
+// NotificationService records notifications for domain events and serves them.
+type NotificationService struct {
+	config           *config.Config
+	db               *sql.DB
+	logger           *zap.SugaredLogger
+	notificationRepo repository.NotificationRepository
+	userRepo         repository.UserRepository
+	postRepo         repository.PostRepository
+	streamService    *StreamService
+}
+
+func NewNotificationService(
+	config *config.Config,
+	db *sql.DB,
+	logger *zap.SugaredLogger,
+	notificationRepo repository.NotificationRepository,
+	userRepo repository.UserRepository,
+	postRepo repository.PostRepository,
+	streamService *StreamService,
+	eventService *EventService,
+) *NotificationService {
+	s := &NotificationService{
+		config:           config,
+		db:               db,
+		logger:           logger,
+		notificationRepo: notificationRepo,
+		userRepo:         userRepo,
+		postRepo:         postRepo,
+		streamService:    streamService,
+	}
+
+	eventService.Subscribe(constants.EventUserFollowed, "notify_follow", s.notifyFollow)
+	eventService.Subscribe(constants.EventFollowRequested, "notify_follow_request", s.notifyFollowRequest)
+	eventService.Subscribe(constants.EventPostReacted, "notify_reaction", s.notifyReaction)
+
+	return s
+}
+
+func (s *NotificationService) notifyFollow(ctx context.Context, event *models.Event) error {
+	var payload types.UserFollowedEvent
+	if err := json.Unmarshal(event.Payload, &payload); err != nil {
+		return err
+	}
+
+	// an approved request was already notified to the followee, tell the follower instead
+	if payload.Approved {
+		return s.save(ctx, payload.FollowerID, constants.NotificationFollowAccepted, payload.FolloweeID, "", "")
+	}
+
+	return s.save(ctx, payload.FolloweeID, constants.NotificationFollow, payload.FollowerID, "", "")
+}
+
+func (s *NotificationService) notifyFollowRequest(ctx context.Context, event *models.Event) error {
+	var payload types.FollowRequestedEvent
+	if err := json.Unmarshal(event.Payload, &payload); err != nil {
+		return err
+	}
+
+	return s.save(ctx, payload.TargetID, constants.NotificationFollowRequest, payload.RequesterID, "", "")
+}
+
+// notifyReaction notifies every author of the post but the one reacting.
+func (s *NotificationService) notifyReaction(ctx context.Context, event *models.Event) error {
+	var payload types.PostReactedEvent
+	if err := json.Unmarshal(event.Payload, &payload); err != nil {
+		return err
+	}
+
+	return s.notifyAuthors(ctx, payload.PostID, payload.UserID, constants.NotificationReaction, payload.Type)
+}
+
+// notifyAuthors notifies the author and co-authors of a post of something actorID did to it.
+func (s *NotificationService) notifyAuthors(ctx context.Context, postID string, actorID string, notificationType string, reaction string) error {
+	authors, err := s.postRepo.FindAuthorsByPostIDs(ctx, []string{postID})
+	if err != nil {
+		s.logger.Errorw("failed to find post authors", "postID", postID, "error", err.Error())
+		return err
+	}
+
+	for _, author := range authors[postID] {
+		if author.ID == actorID {
+			continue
+		}
+		if err := s.save(ctx, author.ID, notificationType, actorID, postID, reaction); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// save records a notification and streams it to the user if they are connected.
+func (s *NotificationService) save(ctx context.Context, userID string, notificationType string, actorID string, postID string, reaction string) error {
+	id, err := s.notificationRepo.Save(ctx, userID, notificationType, actorID, postID, reaction)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		s.logger.Errorw("failed to save notification", "userID", userID, "type", notificationType, "actorID", actorID, "error", err.Error())
+		return err
+	}
+
+	s.streamService.PublishNotification(ctx, userID, map[string]any{
+		"notificationId": id,
+		"type":           notificationType,
+		"actorId":        actorID,
+		"postId":         postID,
+		"reaction":       reaction,
+	})
+
+	return nil
+}
+
+// GetAll lists the grouped notifications of userID along with the number of
+// unread groups. Messages are in the user's locale.
+func (s *NotificationService) GetAll(ctx context.Context, userID string, filter models.NotificationFilter) (*models.NotificationList, int, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		s.logger.Errorw("failed to find user by id", "userID", userID, "error", err.Error())
+		return nil, 0, err
+	}
+
+	notifications, total, unread, err := s.notificationRepo.FindAll(ctx, userID, filter)
+	if err != nil {
+		s.logger.Errorw("failed to find notifications", "userID", userID, "error", err.Error())
+		return nil, 0, err
+	}
+
+	text := notificationTextFor(user.Locale)
+	for _, notification := range notifications {
+		notification.Message = text.message(notification)
+	}
+
+	list := &models.NotificationList{
+		UnreadCount:   unread,
+		Notifications: notifications,
+	}
+	if list.Notifications == nil {
+		list.Notifications = []*models.Notification{}
+	}
+
+	return list, total, nil
+}
+
+// MarkRead marks a notification, and the others grouped with it, as read.
+func (s *NotificationService) MarkRead(ctx context.Context, userID string, notificationID int64) error {
+	return s.notificationRepo.MarkRead(ctx, userID, notificationID)
+}
+
+func (s *NotificationService) MarkAllRead(ctx context.Context, userID string) error {
+	if err := s.notificationRepo.MarkAllRead(ctx, userID); err != nil {
+		s.logger.Errorw("failed to mark notifications read", "userID", userID, "error", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// GetPreferences returns whether userID receives each type of notification.
+func (s *NotificationService) GetPreferences(ctx context.Context, userID string) (map[string]bool, error) {
+	saved, err := s.notificationRepo.FindPreferences(ctx, userID)
+	if err != nil {
+		s.logger.Errorw("failed to find notification preferences", "userID", userID, "error", err.Error())
+		return nil, err
+	}
+
+	preferences := make(map[string]bool, len(notificationTypes))
+	for _, notificationType := range notificationTypes {
+		enabled, ok := saved[notificationType]
+		preferences[notificationType] = !ok || enabled
+	}
+
+	return preferences, nil
+}
+
+// UpdatePreferences turns the given notification types on or off, leaving the
+// others as they are.
+func (s *NotificationService) UpdatePreferences(ctx context.Context, userID string, preferences map[string]bool) (map[string]bool, error) {
+	for notificationType := range preferences {
+		if !slices.Contains(notificationTypes, notificationType) {
+			return nil, constants.ErrInvalidNotification
+		}
+	}
+
+	err := withTx(ctx, s.db, func(tx *sql.Tx) error {
+		for notificationType, enabled := range preferences {
+			if err := s.notificationRepo.SavePreference(ctx, tx, userID, notificationType, enabled); err != nil {
+				s.logger.Errorw("failed to save notification preference", "userID", userID, "type", notificationType, "error", err.Error())
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetPreferences(ctx, userID)
+}
+
+// notificationTextFor returns the messages of locale, or of its nearest parent
+// that has them.
+func notificationTextFor(locale string) notificationText {
+	for _, candidate := range email.LocaleCandidates(locale) {
+		if text, ok := notificationTexts[candidate]; ok {
+			return text
+		}
+	}
+	return notificationTexts[email.DefaultLocale]
+}
+
+// message describes a group, e.g. "ali and 4 others reacted with like to your
+// post". Types without a message in the locale fall back to the default one.
+func (t notificationText) message(notification *models.Notification) string {
+	var actors string
+	switch notification.ActorCount {
+	case 1:
+		actors = notification.Actors[0].Username
+	case 2:
+		actors = fmt.Sprintf(t.two, notification.Actors[0].Username, notification.Actors[1].Username)
+	default:
+		actors = fmt.Sprintf(t.many, notification.Actors[0].Username, notification.ActorCount-1)
+	}
+
+	format, ok := t.messages[notification.Type]
+	if !ok {
+		format, ok = notificationTexts[email.DefaultLocale].messages[notification.Type]
+	}
+	if !ok {
+		return actors
+	}
+
+	var title string
+	if notification.PostTitle != nil {
+		title = *notification.PostTitle
+	}
+
+	return fmt.Sprintf(format, actors, notification.Reaction, title)
+}
@@ -1,40 +1,101 @@
 package services
 
 import (
+	"cmp"
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"github.com/wanafiq/feed-api/internal/config"
+	"github.com/wanafiq/feed-api/internal/constants"
+	"github.com/wanafiq/feed-api/internal/markdown"
 	"github.com/wanafiq/feed-api/internal/middleware"
 	"github.com/wanafiq/feed-api/internal/models"
 	"github.com/wanafiq/feed-api/internal/repository"
 	"github.com/wanafiq/feed-api/internal/types"
 	"github.com/wanafiq/feed-api/internal/utils"
 	"go.uber.org/zap"
+	"html"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 )
 
 type PostService struct {
-	config   *config.Config
-	db       *sql.DB
-	logger   *zap.SugaredLogger
-	postRepo repository.PostRepository
-	tagRepo  repository.TagRepository
-	userRepo repository.UserRepository
+	config        *config.Config
+	db            *sql.DB
+	logger        *zap.SugaredLogger
+	postRepo      repository.PostRepository
+	tagRepo       repository.TagRepository
+	userRepo      repository.UserRepository
+	blockRepo     repository.BlockRepository
+	followerRepo  repository.FollowerRepository
+	reactionRepo  repository.ReactionRepository
+	bookmarkRepo  repository.BookmarkRepository
+	analyticsRepo repository.AnalyticsRepository
+	seriesRepo    repository.SeriesRepository
+	mediaRepo     repository.MediaRepository
+	renderer      *markdown.Renderer
+
+	sitemapService *SitemapService
+	eventService   *EventService
+	auditService   *AuditService
 }
 
-func NewPostService(config *config.Config, db *sql.DB, logger *zap.SugaredLogger, postRepo repository.PostRepository, tagRepo repository.TagRepository, userRepo repository.UserRepository) *PostService {
+func NewPostService(
+	config *config.Config,
+	db *sql.DB,
+	logger *zap.SugaredLogger,
+	postRepo repository.PostRepository,
+	tagRepo repository.TagRepository,
+	userRepo repository.UserRepository,
+	blockRepo repository.BlockRepository,
+	followerRepo repository.FollowerRepository,
+	reactionRepo repository.ReactionRepository,
+	bookmarkRepo repository.BookmarkRepository,
+	analyticsRepo repository.AnalyticsRepository,
+	seriesRepo repository.SeriesRepository,
+	mediaRepo repository.MediaRepository,
+	sitemapService *SitemapService,
+	eventService *EventService,
+	auditService *AuditService,
+) *PostService {
 	return &PostService{
-		config:   config,
-		db:       db,
-		logger:   logger,
-		postRepo: postRepo,
-		tagRepo:  tagRepo,
-		userRepo: userRepo,
+		config:         config,
+		db:             db,
+		logger:         logger,
+		postRepo:       postRepo,
+		tagRepo:        tagRepo,
+		userRepo:       userRepo,
+		blockRepo:      blockRepo,
+		followerRepo:   followerRepo,
+		reactionRepo:   reactionRepo,
+		bookmarkRepo:   bookmarkRepo,
+		analyticsRepo:  analyticsRepo,
+		seriesRepo:     seriesRepo,
+		mediaRepo:      mediaRepo,
+		sitemapService: sitemapService,
+		eventService:   eventService,
+		auditService:   auditService,
+		renderer:       markdown.NewRenderer(config.Post.RenderCacheSize),
 	}
 }
 
+// postOrigin is the slug and dates a post had on the platform it was imported
+// from. onSave runs in the transaction creating the post.
+type postOrigin struct {
+	slug        string
+	createdAt   *time.Time
+	publishedAt *time.Time
+	onSave      func(tx *sql.Tx, post *models.Post) error
+}
+
 func (s *PostService) Save(ctx context.Context, userID string, req *types.PostRequest) (*models.Post, error) {
+	return s.save(ctx, userID, req, postOrigin{})
+}
+
+func (s *PostService) save(ctx context.Context, userID string, req *types.PostRequest, origin postOrigin) (*models.Post, error) {
 	author, err := s.userRepo.FindByID(ctx, userID)
 	if err != nil {
 		s.logger.Errorw("failed to find user by id", "userID", userID, "error", err.Error())
@@ -42,16 +103,31 @@ func (s *PostService) Save(ctx context.Context, userID string, req *types.PostRe
 	}
 
 	post := &models.Post{
-		Title:     req.Title,
-		Slug:      utils.GenerateSlug(req.Title),
-		Content:   req.Content,
-		CreatedAt: time.Now(),
-		CreatedBy: author.Email,
-		AuthorID:  author.ID,
+		Title:      req.Title,
+		Slug:       cmp.Or(origin.slug, utils.GenerateSlug(req.Title)),
+		Content:    req.Content,
+		CreatedAt:  time.Now(),
+		CreatedBy:  author.Email,
+		AuthorID:   author.ID,
+		Visibility: req.Visibility,
+	}
+
+	if origin.createdAt != nil {
+		post.CreatedAt = *origin.createdAt
 	}
 
+	if post.Visibility == "" {
+		post.Visibility = constants.PostVisibilityPublic
+	}
+
+	s.applyOutline(post, req.Summary)
+	applyMeta(post, req)
+
 	if req.Publish {
 		now := time.Now()
+		if origin.publishedAt != nil {
+			now = *origin.publishedAt
+		}
 		post.IsPublished = true
 		post.PublishedAt = &now
 	}
@@ -71,6 +147,23 @@ func (s *PostService) Save(ctx context.Context, userID string, req *types.PostRe
 			return err
 		}
 
+		if err := s.mediaRepo.SyncPostMedia(ctx, tx, post.ID, post.AuthorID, mediaRefs(post)); err != nil {
+			s.logger.Errorw("failed to link post media", "error", err.Error())
+			return err
+		}
+
+		if origin.onSave != nil {
+			if err := origin.onSave(tx, post); err != nil {
+				return err
+			}
+		}
+
+		// imported posts were published elsewhere long ago, they are not news
+		if post.IsPublished && origin.publishedAt == nil {
+			event := types.PostPublishedEvent{PostID: post.ID, AuthorID: post.AuthorID}
+			return s.eventService.Publish(ctx, tx, constants.EventPostPublished, event, post.AuthorID)
+		}
+
 		return nil
 	})
 
@@ -78,6 +171,10 @@ func (s *PostService) Save(ctx context.Context, userID string, req *types.PostRe
 		return nil, err
 	}
 
+	if post.IsPublished {
+		s.sitemapService.Invalidate()
+	}
+
 	post.Author = *author
 
 	return post, nil
@@ -90,16 +187,55 @@ func (s *PostService) GetAll(ctx context.Context, filter models.PostFilter) ([]*
 		return nil, 0, err
 	}
 
+	if err := s.attachReactions(ctx, filter.ViewerID, posts...); err != nil {
+		return nil, 0, err
+	}
+
+	if err := s.attachBookmarks(ctx, filter.ViewerID, posts...); err != nil {
+		return nil, 0, err
+	}
+
+	if err := s.attachAuthors(ctx, posts...); err != nil {
+		return nil, 0, err
+	}
+
 	return posts, count, nil
 }
 
-func (s *PostService) GetPostByID(ctx context.Context, postID string) (*models.Post, error) {
+// GetPostByID returns the post visible to viewerID. Posts between users who
+// blocked each other, and posts by private accounts the viewer does not
+// follow, are reported as not found.
+func (s *PostService) GetPostByID(ctx context.Context, viewerID string, postID string) (*models.Post, error) {
 	post, err := s.postRepo.FindByID(ctx, postID)
 	if err != nil {
 		s.logger.Errorw("failed to find post by id", "postID", postID, "error", err.Error())
 		return nil, err
 	}
 
+	visible, err := s.canView(ctx, viewerID, post)
+	if err != nil {
+		return nil, err
+	}
+	if !visible {
+		return nil, sql.ErrNoRows
+	}
+
+	if err := s.attachReactions(ctx, viewerID, post); err != nil {
+		return nil, err
+	}
+
+	if err := s.attachBookmarks(ctx, viewerID, post); err != nil {
+		return nil, err
+	}
+
+	if err := s.attachAuthors(ctx, post); err != nil {
+		return nil, err
+	}
+
+	if err := s.attachSeries(ctx, viewerID, post); err != nil {
+		return nil, err
+	}
+
 	//tags, err := s.tagRepo.FindByPostID(ctx, postID)
 	//if err != nil {
 	//	return nil, err
@@ -109,6 +245,7 @@ func (s *PostService) GetPostByID(ctx context.Context, postID string) (*models.P
 	return post, nil
 }
 
+// Update edits a post on behalf of its author, an accepted co-author, a moderator or an admin.
 func (s *PostService) Update(ctx context.Context, userCtx middleware.UserContext, postID string, req *types.PostRequest) (*models.Post, error) {
 	post, err := s.postRepo.FindByID(ctx, postID)
 	if err != nil {
@@ -116,25 +253,114 @@ func (s *PostService) Update(ctx context.Context, userCtx middleware.UserContext
 		return nil, err
 	}
 
+	if userCtx.Role != constants.RoleAdmin && userCtx.Role != constants.RoleModerator {
+		isAuthor, err := s.isAuthor(ctx, userCtx.ID, post)
+		if err != nil {
+			return nil, err
+		}
+		if !isAuthor {
+			return nil, constants.ErrForbidden
+		}
+	}
+
+	before := *post
 	now := time.Now()
+	published := req.Publish && !post.IsPublished
 
 	post.Title = req.Title
 	post.Content = req.Content
 	post.IsPublished = req.Publish
+	if published && post.PublishedAt == nil {
+		post.PublishedAt = &now
+	}
 	post.UpdatedAt = &now
 	post.UpdatedBy = &userCtx.Username
+	if req.Visibility != "" {
+		post.Visibility = req.Visibility
+	}
+
+	s.applyOutline(post, req.Summary)
+	applyMeta(post, req)
+
+	var updatedPost *models.Post
+	err = withTx(ctx, s.db, func(tx *sql.Tx) error {
+		updatedPost, err = s.postRepo.Update(ctx, tx, post)
+		if err != nil {
+			s.logger.Errorw("failed to update post", "error", err.Error())
+			return err
+		}
+
+		if err := s.mediaRepo.SyncPostMedia(ctx, tx, post.ID, userCtx.ID, mediaRefs(post)); err != nil {
+			s.logger.Errorw("failed to link post media", "postID", post.ID, "error", err.Error())
+			return err
+		}
+
+		if published {
+			event := types.PostPublishedEvent{PostID: post.ID, AuthorID: post.AuthorID}
+			if err := s.eventService.Publish(ctx, tx, constants.EventPostPublished, event, userCtx.ID); err != nil {
+				return err
+			}
+		} else {
+			event := types.PostUpdatedEvent{PostID: post.ID, AuthorID: post.AuthorID}
+			if err := s.eventService.Publish(ctx, tx, constants.EventPostUpdated, event, userCtx.ID); err != nil {
+				return err
+			}
+		}
+
+		return s.auditService.Record(ctx, tx, userCtx, constants.AuditPostUpdated, constants.AuditTargetPost, post.ID, before, updatedPost)
+	})
 
-	updatedPost, err := s.postRepo.Update(ctx, nil, post)
 	if err != nil {
-		s.logger.Errorw("failed to update post", "error", err.Error())
+		return nil, err
+	}
+
+	s.sitemapService.Invalidate()
+
+	if err := s.attachAuthors(ctx, updatedPost); err != nil {
 		return nil, err
 	}
 
 	return updatedPost, nil
 }
 
-func (s *PostService) Delete(ctx context.Context, postID string) error {
-	return withTx(ctx, s.db, func(tx *sql.Tx) error {
+// Delete removes a post on behalf of its primary author or an admin. Co-authors cannot delete.
+func (s *PostService) Delete(ctx context.Context, userCtx middleware.UserContext, postID string) error {
+	post, err := s.postRepo.FindByID(ctx, postID)
+	if err != nil {
+		s.logger.Errorw("failed to find post by id", "postID", postID, "error", err.Error())
+		return err
+	}
+
+	if post.AuthorID != userCtx.ID && userCtx.Role != constants.RoleAdmin {
+		return constants.ErrForbidden
+	}
+
+	err = withTx(ctx, s.db, func(tx *sql.Tx) error {
+		if err := s.reactionRepo.DeleteByPostID(ctx, tx, postID); err != nil {
+			s.logger.Errorw("failed to delete reactions", "error", err.Error())
+			return err
+		}
+
+		if err := s.bookmarkRepo.DeleteByPostID(ctx, tx, postID); err != nil {
+			s.logger.Errorw("failed to delete bookmarks", "error", err.Error())
+			return err
+		}
+
+		if err := s.analyticsRepo.DeleteByPostID(ctx, tx, postID); err != nil {
+			s.logger.Errorw("failed to delete post views", "error", err.Error())
+			return err
+		}
+
+		if err := s.mediaRepo.UnlinkPost(ctx, tx, postID); err != nil {
+			s.logger.Errorw("failed to unlink post media", "error", err.Error())
+			return err
+		}
+
+		if err := s.seriesRepo.DeleteByPostID(ctx, tx, postID); err != nil {
+			s.logger.Errorw("failed to delete series post", "error", err.Error())
+			return err
+		}
+
 		if err := s.postRepo.DeletePostTag(ctx, tx, postID); err != nil {
 			s.logger.Errorw("failed to delete post tag", "error", err.Error())
 			return err
@@ -150,8 +376,446 @@ func (s *PostService) Delete(ctx context.Context, postID string) error {
 			return err
 		}
 
+		return s.auditService.Record(ctx, tx, userCtx, constants.AuditPostDeleted, constants.AuditTargetPost, post.ID, post, nil)
+	})
+
+	if err != nil {
+		return err
+	}
+
+	s.sitemapService.Invalidate()
+
+	return nil
+}
+
+// InviteCoAuthor invites userID to co-author a post. Only the primary author can invite.
+func (s *PostService) InviteCoAuthor(ctx context.Context, authorID string, postID string, userID string) error {
+	if authorID == userID {
+		return constants.ErrSelfAction
+	}
+
+	post, err := s.postRepo.FindByID(ctx, postID)
+	if err != nil {
+		s.logger.Errorw("failed to find post by id", "postID", postID, "error", err.Error())
+		return err
+	}
+
+	if post.AuthorID != authorID {
+		return constants.ErrForbidden
+	}
+
+	if _, err := s.userRepo.FindByID(ctx, userID); err != nil {
+		s.logger.Errorw("failed to find user by id", "userID", userID, "error", err.Error())
+		return err
+	}
+
+	blocked, err := s.blockRepo.ExistsBetween(ctx, authorID, userID)
+	if err != nil {
+		s.logger.Errorw("failed to check block", "userID", authorID, "targetID", userID, "error", err.Error())
+		return err
+	}
+	if blocked {
+		return constants.ErrBlocked
+	}
+
+	if err := s.postRepo.SaveCoAuthor(ctx, nil, postID, userID); err != nil {
+		s.logger.Errorw("failed to save co-author", "postID", postID, "userID", userID, "error", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (s *PostService) AcceptCoAuthor(ctx context.Context, userID string, postID string) error {
+	if err := s.findPendingInvitation(ctx, userID, postID); err != nil {
+		return err
+	}
+
+	if err := s.postRepo.UpdatePostUserStatus(ctx, nil, postID, userID, constants.PostUserStatusAccepted); err != nil {
+		s.logger.Errorw("failed to accept co-author invitation", "postID", postID, "userID", userID, "error", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (s *PostService) DeclineCoAuthor(ctx context.Context, userID string, postID string) error {
+	if err := s.findPendingInvitation(ctx, userID, postID); err != nil {
+		return err
+	}
+
+	if err := s.postRepo.DeleteCoAuthor(ctx, nil, postID, userID); err != nil {
+		s.logger.Errorw("failed to decline co-author invitation", "postID", postID, "userID", userID, "error", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// RemoveCoAuthor removes a co-author or cancels an invitation. The primary author
+// can remove anyone, a co-author can only remove themselves.
+func (s *PostService) RemoveCoAuthor(ctx context.Context, userID string, postID string, coAuthorID string) error {
+	post, err := s.postRepo.FindByID(ctx, postID)
+	if err != nil {
+		s.logger.Errorw("failed to find post by id", "postID", postID, "error", err.Error())
+		return err
+	}
+
+	if post.AuthorID != userID && coAuthorID != userID {
+		return constants.ErrForbidden
+	}
+
+	if err := s.postRepo.DeleteCoAuthor(ctx, nil, postID, coAuthorID); err != nil {
+		s.logger.Errorw("failed to delete co-author", "postID", postID, "userID", coAuthorID, "error", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (s *PostService) GetCoAuthorInvitations(ctx context.Context, userID string) ([]*models.PostUser, error) {
+	invitations, err := s.postRepo.FindPendingCoAuthorInvitations(ctx, userID)
+	if err != nil {
+		s.logger.Errorw("failed to find co-author invitations", "userID", userID, "error", err.Error())
+		return nil, err
+	}
+
+	return invitations, nil
+}
+
+// RenderHTML sets the sanitized HTML rendering of the markdown content of each post.
+// Renderings are cached per post version, so an edit is rendered again.
+func (s *PostService) RenderHTML(posts ...*models.Post) error {
+	for _, post := range posts {
+		// listings without the full content have nothing to render
+		if post.Content == "" {
+			continue
+		}
+
+		version := post.CreatedAt
+		if post.UpdatedAt != nil {
+			version = *post.UpdatedAt
+		}
+
+		html, err := s.renderer.Render(post.ID+"|"+strconv.FormatInt(version.UnixNano(), 10), post.Content)
+		if err != nil {
+			s.logger.Errorw("failed to render post content", "postID", post.ID, "error", err.Error())
+			return err
+		}
+		post.ContentHTML = html
+	}
+
+	return nil
+}
+
+// React adds reactionType from userID to a post the user can view.
+func (s *PostService) React(ctx context.Context, userID string, postID string, reactionType string) error {
+	if !slices.Contains(s.config.Post.ReactionTypes, reactionType) {
+		return constants.ErrInvalidReaction
+	}
+
+	if _, err := s.GetPostByID(ctx, userID, postID); err != nil {
+		return err
+	}
+
+	return withTx(ctx, s.db, func(tx *sql.Tx) error {
+		if err := s.reactionRepo.Save(ctx, tx, userID, postID, reactionType); err != nil {
+			s.logger.Errorw("failed to save reaction", "userID", userID, "postID", postID, "type", reactionType, "error", err.Error())
+			return err
+		}
+
+		event := types.PostReactedEvent{UserID: userID, PostID: postID, Type: reactionType}
+		return s.eventService.Publish(ctx, tx, constants.EventPostReacted, event, userID)
+	})
+}
+
+func (s *PostService) Unreact(ctx context.Context, userID string, postID string, reactionType string) error {
+	if !slices.Contains(s.config.Post.ReactionTypes, reactionType) {
+		return constants.ErrInvalidReaction
+	}
+
+	if err := s.reactionRepo.Delete(ctx, nil, userID, postID, reactionType); err != nil {
+		s.logger.Errorw("failed to delete reaction", "userID", userID, "postID", postID, "type", reactionType, "error", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// attachReactions loads reaction counts, and the viewer's own reactions, for all posts at once.
+func (s *PostService) attachReactions(ctx context.Context, viewerID string, posts ...*models.Post) error {
+	if len(posts) == 0 {
 		return nil
+	}
+
+	postIDs := make([]string, 0, len(posts))
+	for _, post := range posts {
+		postIDs = append(postIDs, post.ID)
+	}
+
+	counts, err := s.reactionRepo.CountByPostIDs(ctx, postIDs)
+	if err != nil {
+		s.logger.Errorw("failed to count reactions", "error", err.Error())
+		return err
+	}
+
+	var mine map[string][]string
+	if viewerID != "" {
+		mine, err = s.reactionRepo.FindTypesByUser(ctx, viewerID, postIDs)
+		if err != nil {
+			s.logger.Errorw("failed to find user reactions", "userID", viewerID, "error", err.Error())
+			return err
+		}
+	}
+
+	for _, post := range posts {
+		post.Reactions = counts[post.ID]
+		post.MyReactions = mine[post.ID]
+	}
+
+	return nil
+}
+
+// attachBookmarks flags the posts the viewer has bookmarked.
+func (s *PostService) attachBookmarks(ctx context.Context, viewerID string, posts ...*models.Post) error {
+	if viewerID == "" || len(posts) == 0 {
+		return nil
+	}
+
+	postIDs := make([]string, 0, len(posts))
+	for _, post := range posts {
+		postIDs = append(postIDs, post.ID)
+	}
+
+	bookmarked, err := s.bookmarkRepo.FindBookmarkedPostIDs(ctx, viewerID, postIDs)
+	if err != nil {
+		s.logger.Errorw("failed to find bookmarked posts", "userID", viewerID, "error", err.Error())
+		return err
+	}
+
+	for _, post := range posts {
+		post.Bookmarked = bookmarked[post.ID]
+	}
+
+	return nil
+}
+
+// attachAuthors lists the author and accepted co-authors of all posts at once.
+func (s *PostService) attachAuthors(ctx context.Context, posts ...*models.Post) error {
+	if len(posts) == 0 {
+		return nil
+	}
+
+	postIDs := make([]string, 0, len(posts))
+	for _, post := range posts {
+		postIDs = append(postIDs, post.ID)
+	}
+
+	authors, err := s.postRepo.FindAuthorsByPostIDs(ctx, postIDs)
+	if err != nil {
+		s.logger.Errorw("failed to find post authors", "error", err.Error())
+		return err
+	}
+
+	for _, post := range posts {
+		post.Authors = authors[post.ID]
+	}
+
+	return nil
+}
+
+// GetMeta returns the title, description, canonical link, Open Graph and Twitter
+// card tags of a post the viewer can see. Unset fields fall back to the post itself.
+func (s *PostService) GetMeta(ctx context.Context, viewerID string, postID string) (*models.PostMeta, error) {
+	post, err := s.postRepo.FindByID(ctx, postID)
+	if err != nil {
+		s.logger.Errorw("failed to find post by id", "postID", postID, "error", err.Error())
+		return nil, err
+	}
+
+	visible, err := s.canView(ctx, viewerID, post)
+	if err != nil {
+		return nil, err
+	}
+	if !visible {
+		return nil, sql.ErrNoRows
+	}
+
+	description := cmp.Or(post.MetaDescription, post.Excerpt)
+	canonicalURL := cmp.Or(post.CanonicalURL, s.postURL(post))
+	ogTitle := cmp.Or(post.OGTitle, post.Title)
+	ogDescription := cmp.Or(post.OGDescription, description)
+	ogImage := cmp.Or(post.OGImage, post.CoverImage)
+	twitterCard := post.TwitterCard
+	if twitterCard == "" {
+		twitterCard = "summary"
+		if ogImage != "" {
+			twitterCard = "summary_large_image"
+		}
+	}
+
+	tags := []models.MetaTag{
+		{Tag: "meta", Name: "description", Content: description},
+		{Tag: "link", Rel: "canonical", Href: canonicalURL},
+		{Tag: "meta", Property: "og:type", Content: "article"},
+		{Tag: "meta", Property: "og:title", Content: ogTitle},
+		{Tag: "meta", Property: "og:description", Content: ogDescription},
+		{Tag: "meta", Property: "og:url", Content: canonicalURL},
+		{Tag: "meta", Property: "og:image", Content: ogImage},
+		{Tag: "meta", Name: "twitter:card", Content: twitterCard},
+		{Tag: "meta", Name: "twitter:title", Content: ogTitle},
+		{Tag: "meta", Name: "twitter:description", Content: ogDescription},
+		{Tag: "meta", Name: "twitter:image", Content: ogImage},
+	}
+	if post.PublishedAt != nil {
+		tags = append(tags, models.MetaTag{Tag: "meta", Property: "article:published_time", Content: post.PublishedAt.Format(time.RFC3339)})
+	}
+	if post.UpdatedAt != nil {
+		tags = append(tags, models.MetaTag{Tag: "meta", Property: "article:modified_time", Content: post.UpdatedAt.Format(time.RFC3339)})
+	}
+
+	// drop tags left without a value
+	tags = slices.DeleteFunc(tags, func(tag models.MetaTag) bool {
+		return tag.Content == "" && tag.Href == ""
 	})
+
+	return &models.PostMeta{
+		Title: post.Title,
+		Tags:  tags,
+		HTML:  renderMetaTags(post.Title, tags),
+	}, nil
+}
+
+// postURL is the address of a post on the web frontend.
+func (s *PostService) postURL(post *models.Post) string {
+	return fmt.Sprintf("%s/posts/%s", s.config.Url.Web, post.ID)
+}
+
+// applyMeta copies the cover image and social preview fields of the request.
+func applyMeta(post *models.Post, req *types.PostRequest) {
+	post.CoverImage = req.CoverImage
+	post.MetaDescription = req.MetaDescription
+	post.CanonicalURL = req.CanonicalURL
+	post.OGTitle = req.OGTitle
+	post.OGDescription = req.OGDescription
+	post.OGImage = req.OGImage
+	post.TwitterCard = req.TwitterCard
+}
+
+// mediaRefs is the text uploads are looked up in: the content and the image
+// URLs of the post, so a cover image is kept even when the body does not use it.
+func mediaRefs(post *models.Post) string {
+	return strings.Join([]string{post.Content, post.CoverImage, post.OGImage}, "\n")
+}
+
+func renderMetaTags(title string, tags []models.MetaTag) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "<title>%s</title>\n", html.EscapeString(title))
+
+	for _, tag := range tags {
+		switch {
+		case tag.Tag == "link":
+			fmt.Fprintf(&b, "<link rel=\"%s\" href=\"%s\">\n", html.EscapeString(tag.Rel), html.EscapeString(tag.Href))
+		case tag.Property != "":
+			fmt.Fprintf(&b, "<meta property=\"%s\" content=\"%s\">\n", html.EscapeString(tag.Property), html.EscapeString(tag.Content))
+		default:
+			fmt.Fprintf(&b, "<meta name=\"%s\" content=\"%s\">\n", html.EscapeString(tag.Name), html.EscapeString(tag.Content))
+		}
+	}
+
+	return b.String()
+}
+
+// applyOutline computes the excerpt, word count, reading time and table of
+// contents of the post content. An author-supplied summary replaces the excerpt.
+func (s *PostService) applyOutline(post *models.Post, summary string) {
+	outline := s.renderer.Outline(post.Content)
+
+	post.Excerpt = outline.Excerpt
+	if summary != "" {
+		post.Excerpt = summary
+	}
+	post.WordCount = outline.WordCount
+	post.ReadingTime = outline.ReadingTime
+	post.TOC = outline.TOC
+}
+
+// attachSeries sets the series navigation of a post that belongs to a series.
+// Previous and next links only point to posts the viewer can see.
+func (s *PostService) attachSeries(ctx context.Context, viewerID string, post *models.Post) error {
+	series, err := s.seriesRepo.FindByPostID(ctx, post.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		s.logger.Errorw("failed to find series by post id", "postID", post.ID, "error", err.Error())
+		return err
+	}
+
+	posts, err := s.seriesRepo.FindPosts(ctx, series.ID, viewerID)
+	if err != nil {
+		s.logger.Errorw("failed to find series posts", "seriesID", series.ID, "error", err.Error())
+		return err
+	}
+
+	navigation := &models.SeriesNavigation{
+		ID:    series.ID,
+		Title: series.Title,
+		Slug:  series.Slug,
+		Total: len(posts),
+	}
+	for i, p := range posts {
+		if p.ID != post.ID {
+			continue
+		}
+		navigation.Position = i + 1
+		if i > 0 {
+			navigation.Previous = &models.PostLink{ID: posts[i-1].ID, Title: posts[i-1].Title, Slug: posts[i-1].Slug}
+		}
+		if i < len(posts)-1 {
+			navigation.Next = &models.PostLink{ID: posts[i+1].ID, Title: posts[i+1].Title, Slug: posts[i+1].Slug}
+		}
+	}
+	post.Series = navigation
+
+	return nil
+}
+
+// isAuthor reports whether userID is the primary author or an accepted co-author of the post.
+func (s *PostService) isAuthor(ctx context.Context, userID string, post *models.Post) (bool, error) {
+	if userID == "" {
+		return false, nil
+	}
+	if post.AuthorID == userID {
+		return true, nil
+	}
+
+	postUser, err := s.postRepo.FindPostUser(ctx, post.ID, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		s.logger.Errorw("failed to find post user", "postID", post.ID, "userID", userID, "error", err.Error())
+		return false, err
+	}
+
+	return postUser.Status == constants.PostUserStatusAccepted, nil
+}
+
+func (s *PostService) findPendingInvitation(ctx context.Context, userID string, postID string) error {
+	postUser, err := s.postRepo.FindPostUser(ctx, postID, userID)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			s.logger.Errorw("failed to find post user", "postID", postID, "userID", userID, "error", err.Error())
+		}
+		return err
+	}
+
+	if postUser.Role != constants.PostUserRoleCoAuthor || postUser.Status != constants.PostUserStatusPending {
+		return sql.ErrNoRows
+	}
+
+	return nil
 }
 
 func (s *PostService) processTags(ctx context.Context, tx *sql.Tx, post *models.Post, tagNames []string) error {
@@ -202,3 +866,48 @@ func (s *PostService) linkExistingTag(ctx context.Context, tx *sql.Tx, post *mod
 
 	return nil
 }
+
+// canView applies blocks, account privacy and post visibility for viewerID,
+// which is empty for anonymous requests. Unlisted posts are viewable by
+// anyone who has the link.
+func (s *PostService) canView(ctx context.Context, viewerID string, post *models.Post) (bool, error) {
+	isAuthor, err := s.isAuthor(ctx, viewerID, post)
+	if err != nil {
+		return false, err
+	}
+	if isAuthor {
+		return true, nil
+	}
+
+	if post.Visibility == constants.PostVisibilityPrivate {
+		return false, nil
+	}
+
+	if viewerID != "" {
+		blocked, err := s.blockRepo.ExistsBetween(ctx, viewerID, post.AuthorID)
+		if err != nil {
+			s.logger.Errorw("failed to check block", "viewerID", viewerID, "authorID", post.AuthorID, "error", err.Error())
+			return false, err
+		}
+		if blocked {
+			return false, nil
+		}
+	}
+
+	followersOnly := post.Author.IsPrivate || post.Visibility == constants.PostVisibilityFollowers
+	if !followersOnly {
+		return true, nil
+	}
+
+	if viewerID == "" {
+		return false, nil
+	}
+
+	following, err := s.followerRepo.Exists(ctx, viewerID, post.AuthorID)
+	if err != nil {
+		s.logger.Errorw("failed to check follower", "viewerID", viewerID, "authorID", post.AuthorID, "error", err.Error())
+		return false, err
+	}
+
+	return following, nil
+}
@@ -0,0 +1,177 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/wanafiq/feed-api/internal/config"
+	"github.com/wanafiq/feed-api/internal/constants"
+	"github.com/wanafiq/feed-api/internal/models"
+	"github.com/wanafiq/feed-api/internal/repository"
+	"github.com/wanafiq/feed-api/internal/types"
+	"github.com/wanafiq/feed-api/internal/utils"
+	"go.uber.org/zap"
+	"slices"
+)
+
+type SeriesService struct {
+	config      *config.Config
+	db          *sql.DB
+	logger      *zap.SugaredLogger
+	seriesRepo  repository.SeriesRepository
+	postRepo    repository.PostRepository
+	postService *PostService
+}
+
+func NewSeriesService(
+	config *config.Config,
+	db *sql.DB,
+	logger *zap.SugaredLogger,
+	seriesRepo repository.SeriesRepository,
+	postRepo repository.PostRepository,
+	postService *PostService,
+) *SeriesService {
+	return &SeriesService{
+		config:      config,
+		db:          db,
+		logger:      logger,
+		seriesRepo:  seriesRepo,
+		postRepo:    postRepo,
+		postService: postService,
+	}
+}
+
+func (s *SeriesService) Create(ctx context.Context, userID string, req *types.SeriesRequest) (*models.Series, error) {
+	series := &models.Series{
+		AuthorID: userID,
+		Title:    req.Title,
+		Slug:     utils.GenerateSlug(req.Title),
+	}
+	if req.Description != "" {
+		series.Description = &req.Description
+	}
+
+	if err := s.seriesRepo.Save(ctx, nil, series); err != nil {
+		s.logger.Errorw("failed to save series", "userID", userID, "error", err.Error())
+		return nil, err
+	}
+
+	return series, nil
+}
+
+// GetByID returns a series with the posts the viewer can see, in reading order.
+func (s *SeriesService) GetByID(ctx context.Context, viewerID string, seriesID string) (*models.Series, error) {
+	series, err := s.seriesRepo.FindByID(ctx, seriesID)
+	if err != nil {
+		s.logger.Errorw("failed to find series by id", "seriesID", seriesID, "error", err.Error())
+		return nil, err
+	}
+
+	posts, err := s.seriesRepo.FindPosts(ctx, seriesID, viewerID)
+	if err != nil {
+		s.logger.Errorw("failed to find series posts", "seriesID", seriesID, "error", err.Error())
+		return nil, err
+	}
+
+	if err := s.postService.attachAuthors(ctx, posts...); err != nil {
+		return nil, err
+	}
+
+	series.Posts = posts
+
+	return series, nil
+}
+
+// AddPost appends a post to a series. Only the series author can add posts,
+// and only posts they wrote or co-wrote.
+func (s *SeriesService) AddPost(ctx context.Context, userID string, seriesID string, postID string) error {
+	if _, err := s.findOwnSeries(ctx, userID, seriesID); err != nil {
+		return err
+	}
+
+	post, err := s.postRepo.FindByID(ctx, postID)
+	if err != nil {
+		s.logger.Errorw("failed to find post by id", "postID", postID, "error", err.Error())
+		return err
+	}
+
+	isAuthor, err := s.postService.isAuthor(ctx, userID, post)
+	if err != nil {
+		return err
+	}
+	if !isAuthor {
+		return constants.ErrForbidden
+	}
+
+	_, err = s.seriesRepo.FindByPostID(ctx, postID)
+	if err == nil {
+		return constants.ErrPostInSeries
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		s.logger.Errorw("failed to find series by post id", "postID", postID, "error", err.Error())
+		return err
+	}
+
+	if err := s.seriesRepo.AddPost(ctx, nil, seriesID, postID); err != nil {
+		s.logger.Errorw("failed to add post to series", "seriesID", seriesID, "postID", postID, "error", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (s *SeriesService) RemovePost(ctx context.Context, userID string, seriesID string, postID string) error {
+	if _, err := s.findOwnSeries(ctx, userID, seriesID); err != nil {
+		return err
+	}
+
+	if err := s.seriesRepo.RemovePost(ctx, nil, seriesID, postID); err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			s.logger.Errorw("failed to remove post from series", "seriesID", seriesID, "postID", postID, "error", err.Error())
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Reorder sets the reading order of a series. postIDs must list every post of the series once.
+func (s *SeriesService) Reorder(ctx context.Context, userID string, seriesID string, postIDs []string) error {
+	if _, err := s.findOwnSeries(ctx, userID, seriesID); err != nil {
+		return err
+	}
+
+	current, err := s.seriesRepo.FindPostIDs(ctx, seriesID)
+	if err != nil {
+		s.logger.Errorw("failed to find series post ids", "seriesID", seriesID, "error", err.Error())
+		return err
+	}
+
+	sorted := slices.Clone(postIDs)
+	slices.Sort(sorted)
+	slices.Sort(current)
+	if !slices.Equal(sorted, current) {
+		return constants.ErrInvalidSeriesOrder
+	}
+
+	if err := s.seriesRepo.UpdatePositions(ctx, nil, seriesID, postIDs); err != nil {
+		s.logger.Errorw("failed to update series positions", "seriesID", seriesID, "error", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (s *SeriesService) findOwnSeries(ctx context.Context, userID string, seriesID string) (*models.Series, error) {
+	series, err := s.seriesRepo.FindByID(ctx, seriesID)
+	if err != nil {
+		s.logger.Errorw("failed to find series by id", "seriesID", seriesID, "error", err.Error())
+		return nil, err
+	}
+
+	if series.AuthorID != userID {
+		return nil, constants.ErrForbidden
+	}
+
+	return series, nil
+}
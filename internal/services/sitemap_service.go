@@ -0,0 +1,199 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/xml"
+	"fmt"
+	"github.com/wanafiq/feed-api/internal/config"
+	"github.com/wanafiq/feed-api/internal/models"
+	"github.com/wanafiq/feed-api/internal/repository"
+	"go.uber.org/zap"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const (
+	SitemapPosts = "posts"
+	SitemapTags  = "tags"
+	SitemapUsers = "users"
+
+	// sitemapMaxURLs is the protocol's limit of URLs per sitemap file.
+	sitemapMaxURLs = 50000
+
+	// Scheduled posts go live without a write, so cached sitemaps also expire.
+	sitemapCacheTTL = time.Hour
+)
+
+type cachedSitemap struct {
+	body      []byte
+	expiresAt time.Time
+}
+
+type SitemapService struct {
+	config      *config.Config
+	db          *sql.DB
+	logger      *zap.SugaredLogger
+	sitemapRepo repository.SitemapRepository
+
+	mu         sync.Mutex
+	cache      map[string]cachedSitemap
+	generation int // bumped on every invalidation
+}
+
+func NewSitemapService(config *config.Config, db *sql.DB, logger *zap.SugaredLogger, sitemapRepo repository.SitemapRepository) *SitemapService {
+	return &SitemapService{
+		config:      config,
+		db:          db,
+		logger:      logger,
+		sitemapRepo: sitemapRepo,
+		cache:       make(map[string]cachedSitemap),
+	}
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name     `xml:"sitemapindex"`
+	Xmlns    string       `xml:"xmlns,attr"`
+	Sitemaps []sitemapLoc `xml:"sitemap"`
+}
+
+type sitemapLoc struct {
+	Loc string `xml:"loc"`
+}
+
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+// GetIndex returns the sitemap index, listing every page of the post, tag and
+// user sitemaps served by this API.
+func (s *SitemapService) GetIndex(ctx context.Context) ([]byte, error) {
+	return s.cached("index", func() ([]byte, error) {
+		counts, err := s.sitemapRepo.Count(ctx)
+		if err != nil {
+			s.logger.Errorw("failed to count sitemap entries", "error", err.Error())
+			return nil, err
+		}
+
+		index := sitemapIndex{Xmlns: "http://www.sitemaps.org/schemas/sitemap/0.9"}
+		for _, child := range []struct {
+			kind  string
+			count int
+		}{
+			{SitemapPosts, counts.Posts},
+			{SitemapTags, counts.Tags},
+			{SitemapUsers, counts.Users},
+		} {
+			for page := 1; page <= sitemapPages(child.count); page++ {
+				index.Sitemaps = append(index.Sitemaps, sitemapLoc{
+					Loc: fmt.Sprintf("%s/sitemaps/%s-%d.xml", s.config.Url.API, child.kind, page),
+				})
+			}
+		}
+
+		return encodeSitemap(index)
+	})
+}
+
+// GetSitemap returns one page of the post, tag or user sitemap. It returns
+// sql.ErrNoRows for an unknown kind or a page past the end.
+func (s *SitemapService) GetSitemap(ctx context.Context, kind string, page int) ([]byte, error) {
+	var find func(ctx context.Context, offset int, limit int) ([]models.SitemapEntry, error)
+	var location func(key string) string
+
+	switch kind {
+	case SitemapPosts:
+		find = s.sitemapRepo.FindPosts
+		location = func(key string) string { return fmt.Sprintf("%s/posts/%s", s.config.Url.Web, key) }
+	case SitemapTags:
+		find = s.sitemapRepo.FindTags
+		location = func(key string) string { return fmt.Sprintf("%s/tags/%s", s.config.Url.Web, url.PathEscape(key)) }
+	case SitemapUsers:
+		find = s.sitemapRepo.FindUsers
+		location = func(key string) string { return fmt.Sprintf("%s/users/%s", s.config.Url.Web, key) }
+	default:
+		return nil, sql.ErrNoRows
+	}
+
+	if page < 1 {
+		return nil, sql.ErrNoRows
+	}
+
+	return s.cached(fmt.Sprintf("%s|%d", kind, page), func() ([]byte, error) {
+		entries, err := find(ctx, (page-1)*sitemapMaxURLs, sitemapMaxURLs)
+		if err != nil {
+			s.logger.Errorw("failed to find sitemap entries", "kind", kind, "page", page, "error", err.Error())
+			return nil, err
+		}
+		// the first page always exists so an empty site still has a valid sitemap
+		if len(entries) == 0 && page > 1 {
+			return nil, sql.ErrNoRows
+		}
+
+		urlSet := sitemapURLSet{Xmlns: "http://www.sitemaps.org/schemas/sitemap/0.9"}
+		for _, entry := range entries {
+			urlSet.URLs = append(urlSet.URLs, sitemapURL{
+				Loc:     location(entry.Key),
+				LastMod: entry.LastMod.UTC().Format(time.RFC3339),
+			})
+		}
+
+		return encodeSitemap(urlSet)
+	})
+}
+
+// Invalidate drops every cached sitemap. It is called whenever a post is
+// published, updated or deleted.
+func (s *SitemapService) Invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	clear(s.cache)
+	s.generation++
+}
+
+func (s *SitemapService) cached(key string, build func() ([]byte, error)) ([]byte, error) {
+	s.mu.Lock()
+	entry, ok := s.cache[key]
+	generation := s.generation
+	s.mu.Unlock()
+
+	if ok && time.Now().Before(entry.expiresAt) {
+		return entry.body, nil
+	}
+
+	body, err := build()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	// don't store a sitemap built from data invalidated in the meantime
+	if generation == s.generation {
+		s.cache[key] = cachedSitemap{body: body, expiresAt: time.Now().Add(sitemapCacheTTL)}
+	}
+	s.mu.Unlock()
+
+	return body, nil
+}
+
+func sitemapPages(count int) int {
+	return max(1, (count+sitemapMaxURLs-1)/sitemapMaxURLs)
+}
+
+func encodeSitemap(v any) ([]byte, error) {
+	body, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), body...), nil
+}
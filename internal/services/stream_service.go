@@ -0,0 +1,337 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/wanafiq/feed-api/internal/config"
+	"github.com/wanafiq/feed-api/internal/constants"
+	"github.com/wanafiq/feed-api/internal/models"
+	"github.com/wanafiq/feed-api/internal/repository"
+	"github.com/wanafiq/feed-api/internal/types"
+	"github.com/wanafiq/feed-api/internal/utils"
+	"go.uber.org/zap"
+	"slices"
+	"sync"
+	"time"
+)
+
+// streamReplayLimit caps the events replayed to a client resuming a stream.
+const streamReplayLimit = 500
+
+// StreamSubscriber is one open stream of a user. Events is closed when the
+// subscriber is dropped for falling behind or when the server shuts down.
+type StreamSubscriber struct {
+	UserID string
+	Events <-chan *models.StreamEvent
+
+	events chan *models.StreamEvent
+	closed bool
+}
+
+// StreamService pushes notifications and new posts to connected clients. Events
+// are stored, then announced with Postgres NOTIFY so that every API replica
+// hears about them and hands them to its own subscribers.
+type StreamService struct {
+	config       *config.Config
+	db           *sql.DB
+	logger       *zap.SugaredLogger
+	streamRepo   repository.StreamRepository
+	followerRepo repository.FollowerRepository
+	postRepo     repository.PostRepository
+	tokenRepo    repository.TokenRepository
+
+	mu          sync.Mutex
+	subscribers map[string][]*StreamSubscriber // user id -> open streams
+	lastEventID int64                          // last event heard, to catch up after the listener reconnects
+}
+
+func NewStreamService(
+	config *config.Config,
+	db *sql.DB,
+	logger *zap.SugaredLogger,
+	streamRepo repository.StreamRepository,
+	followerRepo repository.FollowerRepository,
+	postRepo repository.PostRepository,
+	tokenRepo repository.TokenRepository,
+	eventService *EventService,
+) *StreamService {
+	s := &StreamService{
+		config:       config,
+		db:           db,
+		logger:       logger,
+		streamRepo:   streamRepo,
+		followerRepo: followerRepo,
+		postRepo:     postRepo,
+		tokenRepo:    tokenRepo,
+		subscribers:  make(map[string][]*StreamSubscriber),
+	}
+
+	eventService.Subscribe(constants.EventPostPublished, "stream_post", s.streamPost)
+
+	return s
+}
+
+// Publish stores an event and announces it to every replica. Streaming is best
+// effort, so failures are only logged.
+func (s *StreamService) Publish(ctx context.Context, event *models.StreamEvent) {
+	if err := s.streamRepo.Save(ctx, event); err != nil {
+		s.logger.Errorw("failed to save stream event", "type", event.Type, "error", err.Error())
+	}
+}
+
+// PublishNotification streams a new notification to its recipient.
+func (s *StreamService) PublishNotification(ctx context.Context, userID string, data any) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		s.logger.Errorw("failed to marshal stream event", "error", err.Error())
+		return
+	}
+
+	s.Publish(ctx, &models.StreamEvent{Type: constants.StreamEventNotification, UserID: &userID, Data: payload})
+}
+
+// streamPost streams a newly published post to the author's followers, unless
+// they could not see it in their feed.
+func (s *StreamService) streamPost(ctx context.Context, event *models.Event) error {
+	var payload types.PostPublishedEvent
+	if err := json.Unmarshal(event.Payload, &payload); err != nil {
+		return err
+	}
+
+	post, err := s.postRepo.FindByID(ctx, payload.PostID)
+	if err != nil {
+		s.logger.Errorw("failed to find post by id", "postID", payload.PostID, "error", err.Error())
+		return err
+	}
+	if !post.IsPublished || (post.Visibility != constants.PostVisibilityPublic && post.Visibility != constants.PostVisibilityFollowers) {
+		return nil
+	}
+
+	data, err := json.Marshal(map[string]any{
+		"postId":      post.ID,
+		"title":       post.Title,
+		"slug":        post.Slug,
+		"excerpt":     post.Excerpt,
+		"authorId":    post.AuthorID,
+		"publishedAt": post.PublishedAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	s.Publish(ctx, &models.StreamEvent{Type: constants.StreamEventPost, AuthorID: &post.AuthorID, Data: data})
+
+	return nil
+}
+
+// IssueTicket returns a ticket that opens one stream for userID within
+// StreamTicketExpireTime. EventSource cannot send an Authorization header, and
+// a ticket in the query string is safer there than the JWT itself.
+func (s *StreamService) IssueTicket(ctx context.Context, userID string) (*types.StreamTicketResponse, error) {
+	rawTicket := uuid.New().String()
+
+	token := models.Token{
+		Type:      constants.StreamTicket,
+		Value:     utils.HashToken(rawTicket),
+		ExpiredAt: time.Now().Add(constants.StreamTicketExpireTime),
+		UserID:    userID,
+	}
+
+	if err := s.tokenRepo.Save(ctx, nil, &token); err != nil {
+		s.logger.Errorw("failed to save stream ticket", "userID", userID, "error", err.Error())
+		return nil, err
+	}
+
+	return &types.StreamTicketResponse{Ticket: rawTicket, ExpiredAt: token.ExpiredAt}, nil
+}
+
+// Subscribe opens a stream for userID, failing with ErrTooManyStreams when the
+// user already has the maximum number open.
+func (s *StreamService) Subscribe(userID string) (*StreamSubscriber, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.subscribers[userID]) >= s.config.Stream.MaxConnectionsPerUser {
+		return nil, constants.ErrTooManyStreams
+	}
+
+	events := make(chan *models.StreamEvent, s.config.Stream.BufferSize)
+	subscriber := &StreamSubscriber{UserID: userID, Events: events, events: events}
+	s.subscribers[userID] = append(s.subscribers[userID], subscriber)
+
+	return subscriber, nil
+}
+
+func (s *StreamService) Unsubscribe(subscriber *StreamSubscriber) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.remove(subscriber)
+}
+
+// remove drops a subscriber and closes its channel. s.mu must be held.
+func (s *StreamService) remove(subscriber *StreamSubscriber) {
+	subscribers := slices.DeleteFunc(s.subscribers[subscriber.UserID], func(other *StreamSubscriber) bool {
+		return other == subscriber
+	})
+	if len(subscribers) == 0 {
+		delete(s.subscribers, subscriber.UserID)
+	} else {
+		s.subscribers[subscriber.UserID] = subscribers
+	}
+
+	if !subscriber.closed {
+		subscriber.closed = true
+		close(subscriber.events)
+	}
+}
+
+// GetMissed returns the events for userID after lastEventID, for a client
+// resuming its stream.
+func (s *StreamService) GetMissed(ctx context.Context, userID string, lastEventID int64) ([]*models.StreamEvent, error) {
+	events, err := s.streamRepo.FindForUserAfter(ctx, userID, lastEventID, streamReplayLimit)
+	if err != nil {
+		s.logger.Errorw("failed to find stream events", "userID", userID, "lastEventID", lastEventID, "error", err.Error())
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// Run listens for events announced by any replica and delivers them to local
+// subscribers, and prunes events too old to resume from, until ctx is
+// cancelled. It then closes every open stream.
+func (s *StreamService) Run(ctx context.Context) {
+	defer s.closeAll()
+
+	listener := pq.NewListener(s.config.DatabaseURL, time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			s.logger.Warnw("stream listener connection event", "event", event, "error", err.Error())
+		}
+	})
+	defer listener.Close()
+
+	if err := listener.Listen(repository.StreamChannel); err != nil {
+		s.logger.Errorw("failed to listen for stream events", "error", err.Error())
+		return
+	}
+
+	ping := time.NewTicker(time.Minute)
+	defer ping.Stop()
+
+	prune := time.NewTicker(time.Hour)
+	defer prune.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case notification := <-listener.Notify:
+			// nil after a reconnect: notifications sent meanwhile were lost
+			if notification == nil {
+				s.catchUp(ctx)
+				continue
+			}
+
+			var event models.StreamEvent
+			if err := json.Unmarshal([]byte(notification.Extra), &event); err != nil {
+				s.logger.Errorw("failed to decode stream event", "error", err.Error())
+				continue
+			}
+			s.dispatch(ctx, &event)
+		case <-ping.C:
+			go listener.Ping()
+		case <-prune.C:
+			s.prune(ctx)
+		}
+	}
+}
+
+func (s *StreamService) catchUp(ctx context.Context) {
+	if s.lastEventID == 0 {
+		return
+	}
+
+	events, err := s.streamRepo.FindAfter(ctx, s.lastEventID, streamReplayLimit)
+	if err != nil {
+		s.logger.Errorw("failed to catch up on stream events", "lastEventID", s.lastEventID, "error", err.Error())
+		return
+	}
+
+	for _, event := range events {
+		s.dispatch(ctx, event)
+	}
+}
+
+// dispatch hands an event to the local subscribers it is meant for.
+func (s *StreamService) dispatch(ctx context.Context, event *models.StreamEvent) {
+	s.lastEventID = max(s.lastEventID, event.ID)
+
+	var recipients []string
+	switch {
+	case event.UserID != nil:
+		recipients = []string{*event.UserID}
+	case event.AuthorID != nil:
+		s.mu.Lock()
+		connected := make([]string, 0, len(s.subscribers))
+		for userID := range s.subscribers {
+			connected = append(connected, userID)
+		}
+		s.mu.Unlock()
+
+		if len(connected) == 0 {
+			return
+		}
+
+		followers, err := s.followerRepo.FilterFollowers(ctx, *event.AuthorID, connected)
+		if err != nil {
+			s.logger.Errorw("failed to find connected followers", "authorID", *event.AuthorID, "error", err.Error())
+			return
+		}
+		recipients = followers
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, userID := range recipients {
+		for _, subscriber := range slices.Clone(s.subscribers[userID]) {
+			select {
+			case subscriber.events <- event:
+			default:
+				// too slow to keep up, the client resumes from Last-Event-ID when it reconnects
+				s.remove(subscriber)
+			}
+		}
+	}
+}
+
+func (s *StreamService) prune(ctx context.Context) {
+	deleted, err := s.streamRepo.DeleteBefore(ctx, time.Now().Add(-s.config.Stream.Retention))
+	if err != nil {
+		s.logger.Errorw("failed to prune stream events", "error", err.Error())
+		return
+	}
+	if deleted > 0 {
+		s.logger.Infow("pruned stream events", "deleted", deleted)
+	}
+
+	// tickets that were never used
+	if _, err := s.tokenRepo.DeleteExpired(ctx, constants.StreamTicket); err != nil {
+		s.logger.Errorw("failed to prune stream tickets", "error", err.Error())
+	}
+}
+
+func (s *StreamService) closeAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, subscribers := range s.subscribers {
+		for _, subscriber := range slices.Clone(subscribers) {
+			s.remove(subscriber)
+		}
+	}
+}
@@ -4,9 +4,13 @@ import (
 	"context"
 	"database/sql"
 	"github.com/wanafiq/feed-api/internal/config"
+	"github.com/wanafiq/feed-api/internal/constants"
+	"github.com/wanafiq/feed-api/internal/middleware"
 	"github.com/wanafiq/feed-api/internal/models"
 	"github.com/wanafiq/feed-api/internal/repository"
+	"github.com/wanafiq/feed-api/internal/types"
 	"go.uber.org/zap"
+	"time"
 )
 
 type UserService struct {
@@ -15,15 +19,36 @@ type UserService struct {
 	logger       *zap.SugaredLogger
 	userRepo     repository.UserRepository
 	followerRepo repository.FollowerRepository
+	blockRepo    repository.BlockRepository
+	muteRepo     repository.MuteRepository
+	requestRepo  repository.FollowRequestRepository
+	eventService *EventService
+	auditService *AuditService
 }
 
-func NewUserService(config *config.Config, db *sql.DB, logger *zap.SugaredLogger, userRepo repository.UserRepository, followerRepo repository.FollowerRepository) *UserService {
+func NewUserService(
+	config *config.Config,
+	db *sql.DB,
+	logger *zap.SugaredLogger,
+	userRepo repository.UserRepository,
+	followerRepo repository.FollowerRepository,
+	blockRepo repository.BlockRepository,
+	muteRepo repository.MuteRepository,
+	requestRepo repository.FollowRequestRepository,
+	eventService *EventService,
+	auditService *AuditService,
+) *UserService {
 	return &UserService{
 		config:       config,
 		db:           db,
 		logger:       logger,
 		userRepo:     userRepo,
 		followerRepo: followerRepo,
+		blockRepo:    blockRepo,
+		muteRepo:     muteRepo,
+		requestRepo:  requestRepo,
+		eventService: eventService,
+		auditService: auditService,
 	}
 }
 
@@ -37,44 +62,255 @@ func (s *UserService) GetByID(ctx context.Context, userID string) (*models.User,
 	return user, nil
 }
 
-func (s *UserService) Follow(ctx context.Context, followerID string, followeeID string) error {
+// Follow follows the target user, or creates a pending follow request when the
+// target account is private. It returns the resulting follow status.
+func (s *UserService) Follow(ctx context.Context, followerID string, followeeID string) (string, error) {
+	if followerID == followeeID {
+		return "", constants.ErrSelfAction
+	}
+
+	followee, err := s.userRepo.FindByID(ctx, followeeID)
+	if err != nil {
+		s.logger.Errorw("failed to find followeeID by id", "followeeID", followeeID, "error", err.Error())
+		return "", err
+	}
+
+	blocked, err := s.blockRepo.ExistsBetween(ctx, followerID, followeeID)
+	if err != nil {
+		s.logger.Errorw("failed to check block", "followerID", followerID, "followeeID", followeeID, "error", err.Error())
+		return "", err
+	}
+	if blocked {
+		return "", constants.ErrBlocked
+	}
+
+	if followee.IsPrivate {
+		following, err := s.followerRepo.Exists(ctx, followerID, followeeID)
+		if err != nil {
+			s.logger.Errorw("failed to check follower", "followerID", followerID, "followeeID", followeeID, "error", err.Error())
+			return "", err
+		}
+		if following {
+			return constants.FollowStatusFollowing, nil
+		}
+
+		err = withTx(ctx, s.db, func(tx *sql.Tx) error {
+			created, err := s.requestRepo.Save(ctx, tx, followerID, followeeID)
+			if err != nil {
+				s.logger.Errorw("failed to save follow request", "followerID", followerID, "followeeID", followeeID, "error", err.Error())
+				return err
+			}
+			// already requested, the target was told then
+			if !created {
+				return nil
+			}
+
+			event := types.FollowRequestedEvent{RequesterID: followerID, TargetID: followeeID}
+			return s.eventService.Publish(ctx, tx, constants.EventFollowRequested, event, followerID)
+		})
+		if err != nil {
+			return "", err
+		}
+
+		return constants.FollowStatusRequested, nil
+	}
+
+	err = withTx(ctx, s.db, func(tx *sql.Tx) error {
+		created, err := s.followerRepo.Save(ctx, tx, followerID, followeeID)
+		if err != nil {
+			s.logger.Errorw("failed to save follower", "followerID", followerID, "followeeID", followeeID, "error", err.Error())
+			return err
+		}
+		// already following
+		if !created {
+			return nil
+		}
+
+		event := types.UserFollowedEvent{FollowerID: followerID, FolloweeID: followeeID}
+		return s.eventService.Publish(ctx, tx, constants.EventUserFollowed, event, followerID)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return constants.FollowStatusFollowing, nil
+}
+
+func (s *UserService) Unfollow(ctx context.Context, followerID string, followeeID string) error {
 	_, err := s.userRepo.FindByID(ctx, followeeID)
 	if err != nil {
 		s.logger.Errorw("failed to find followeeID by id", "followeeID", followeeID, "error", err.Error())
 		return err
 	}
 
-	if err := s.followerRepo.Save(ctx, nil, followerID, followeeID); err != nil {
-		s.logger.Errorw("failed to save follower", "followerID", followerID, "followeeID", followeeID, "error", err.Error())
+	return withTx(ctx, s.db, func(tx *sql.Tx) error {
+		if err := s.followerRepo.Delete(ctx, tx, followerID, followeeID); err != nil {
+			s.logger.Errorw("failed to delete follower", "followerID", followerID, "followeeID", followeeID, "error", err.Error())
+			return err
+		}
+
+		if err := s.requestRepo.Delete(ctx, tx, followerID, followeeID); err != nil {
+			s.logger.Errorw("failed to delete follow request", "followerID", followerID, "followeeID", followeeID, "error", err.Error())
+			return err
+		}
+
+		return nil
+	})
+}
+
+func (s *UserService) Deactivate(ctx context.Context, userCtx middleware.UserContext, userID string) (*models.User, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		s.logger.Errorw("failed to find user by id", "userID", userID, "error", err.Error())
+		return nil, err
+	}
+
+	before := *user
+	now := time.Now()
+
+	user.IsActive = false
+	user.UpdatedAt = &now
+	user.UpdatedBy = &userCtx.Username
+
+	err = withTx(ctx, s.db, func(tx *sql.Tx) error {
+		if err := s.userRepo.Update(ctx, tx, user); err != nil {
+			s.logger.Errorw("failed to update user", "userID", userID, "error", err.Error())
+			return err
+		}
+
+		return s.auditService.Record(ctx, tx, userCtx, constants.AuditUserDeactivated, constants.AuditTargetUser, user.ID, before, user)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// Block blocks the target user and removes any follow relationship or pending
+// follow request between both users.
+func (s *UserService) Block(ctx context.Context, blockerID string, blockedID string) error {
+	if blockerID == blockedID {
+		return constants.ErrSelfAction
+	}
+
+	_, err := s.userRepo.FindByID(ctx, blockedID)
+	if err != nil {
+		s.logger.Errorw("failed to find blockedID by id", "blockedID", blockedID, "error", err.Error())
+		return err
+	}
+
+	return withTx(ctx, s.db, func(tx *sql.Tx) error {
+		if err := s.blockRepo.Save(ctx, tx, blockerID, blockedID); err != nil {
+			s.logger.Errorw("failed to save block", "blockerID", blockerID, "blockedID", blockedID, "error", err.Error())
+			return err
+		}
+
+		if err := s.followerRepo.Delete(ctx, tx, blockerID, blockedID); err != nil {
+			s.logger.Errorw("failed to delete follower", "followerID", blockerID, "followeeID", blockedID, "error", err.Error())
+			return err
+		}
+
+		if err := s.followerRepo.Delete(ctx, tx, blockedID, blockerID); err != nil {
+			s.logger.Errorw("failed to delete follower", "followerID", blockedID, "followeeID", blockerID, "error", err.Error())
+			return err
+		}
+
+		if err := s.requestRepo.Delete(ctx, tx, blockerID, blockedID); err != nil {
+			s.logger.Errorw("failed to delete follow request", "requesterID", blockerID, "targetID", blockedID, "error", err.Error())
+			return err
+		}
+
+		if err := s.requestRepo.Delete(ctx, tx, blockedID, blockerID); err != nil {
+			s.logger.Errorw("failed to delete follow request", "requesterID", blockedID, "targetID", blockerID, "error", err.Error())
+			return err
+		}
+
+		return nil
+	})
+}
+
+func (s *UserService) Unblock(ctx context.Context, blockerID string, blockedID string) error {
+	_, err := s.userRepo.FindByID(ctx, blockedID)
+	if err != nil {
+		s.logger.Errorw("failed to find blockedID by id", "blockedID", blockedID, "error", err.Error())
+		return err
+	}
+
+	if err := s.blockRepo.Delete(ctx, nil, blockerID, blockedID); err != nil {
+		s.logger.Errorw("failed to delete block", "blockerID", blockerID, "blockedID", blockedID, "error", err.Error())
 		return err
 	}
 
 	return nil
 }
 
-func (s *UserService) Unfollow(ctx context.Context, followerID string, followeeID string) error {
-	_, err := s.userRepo.FindByID(ctx, followeeID)
+func (s *UserService) GetBlocked(ctx context.Context, userID string, limit int, offset int) ([]*models.User, int, error) {
+	users, count, err := s.blockRepo.FindBlockedUsers(ctx, userID, limit, offset)
 	if err != nil {
-		s.logger.Errorw("failed to find followeeID by id", "followeeID", followeeID, "error", err.Error())
+		s.logger.Errorw("failed to find blocked users", "userID", userID, "error", err.Error())
+		return nil, 0, err
+	}
+
+	return users, count, nil
+}
+
+// Mute hides the target user's posts from the caller. The muted user is not notified.
+func (s *UserService) Mute(ctx context.Context, muterID string, mutedID string) error {
+	if muterID == mutedID {
+		return constants.ErrSelfAction
+	}
+
+	_, err := s.userRepo.FindByID(ctx, mutedID)
+	if err != nil {
+		s.logger.Errorw("failed to find mutedID by id", "mutedID", mutedID, "error", err.Error())
 		return err
 	}
 
-	if err := s.followerRepo.Delete(ctx, nil, followerID, followeeID); err != nil {
-		s.logger.Errorw("failed to delete follower", "followerID", followerID, "followeeID", followeeID, "error", err.Error())
+	if err := s.muteRepo.Save(ctx, nil, muterID, mutedID); err != nil {
+		s.logger.Errorw("failed to save mute", "muterID", muterID, "mutedID", mutedID, "error", err.Error())
 		return err
 	}
 
 	return nil
 }
 
-func (s *UserService) Deactivate(ctx context.Context, userID string) (*models.User, error) {
+func (s *UserService) Unmute(ctx context.Context, muterID string, mutedID string) error {
+	_, err := s.userRepo.FindByID(ctx, mutedID)
+	if err != nil {
+		s.logger.Errorw("failed to find mutedID by id", "mutedID", mutedID, "error", err.Error())
+		return err
+	}
+
+	if err := s.muteRepo.Delete(ctx, nil, muterID, mutedID); err != nil {
+		s.logger.Errorw("failed to delete mute", "muterID", muterID, "mutedID", mutedID, "error", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (s *UserService) GetMuted(ctx context.Context, userID string, limit int, offset int) ([]*models.User, int, error) {
+	users, count, err := s.muteRepo.FindMutedUsers(ctx, userID, limit, offset)
+	if err != nil {
+		s.logger.Errorw("failed to find muted users", "userID", userID, "error", err.Error())
+		return nil, 0, err
+	}
+
+	return users, count, nil
+}
+
+func (s *UserService) UpdatePrivacy(ctx context.Context, userID string, isPrivate bool) (*models.User, error) {
 	user, err := s.userRepo.FindByID(ctx, userID)
 	if err != nil {
 		s.logger.Errorw("failed to find user by id", "userID", userID, "error", err.Error())
 		return nil, err
 	}
 
-	user.IsActive = false
+	now := time.Now()
+	user.IsPrivate = isPrivate
+	user.UpdatedAt = &now
+	user.UpdatedBy = &user.Email
 
 	if err := s.userRepo.Update(ctx, nil, user); err != nil {
 		s.logger.Errorw("failed to update user", "userID", userID, "error", err.Error())
@@ -83,3 +319,94 @@ func (s *UserService) Deactivate(ctx context.Context, userID string) (*models.Us
 
 	return user, nil
 }
+
+// UpdateLocale sets the locale emails and notifications are sent to the user in.
+func (s *UserService) UpdateLocale(ctx context.Context, userID string, locale string) (*models.User, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		s.logger.Errorw("failed to find user by id", "userID", userID, "error", err.Error())
+		return nil, err
+	}
+
+	now := time.Now()
+	user.Locale = locale
+	user.UpdatedAt = &now
+	user.UpdatedBy = &user.Email
+
+	if err := s.userRepo.Update(ctx, nil, user); err != nil {
+		s.logger.Errorw("failed to update user", "userID", userID, "error", err.Error())
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (s *UserService) GetIncomingFollowRequests(ctx context.Context, userID string, limit int, offset int) ([]*models.FollowRequest, int, error) {
+	requests, count, err := s.requestRepo.FindIncoming(ctx, userID, limit, offset)
+	if err != nil {
+		s.logger.Errorw("failed to find incoming follow requests", "userID", userID, "error", err.Error())
+		return nil, 0, err
+	}
+
+	return requests, count, nil
+}
+
+func (s *UserService) GetOutgoingFollowRequests(ctx context.Context, userID string, limit int, offset int) ([]*models.FollowRequest, int, error) {
+	requests, count, err := s.requestRepo.FindOutgoing(ctx, userID, limit, offset)
+	if err != nil {
+		s.logger.Errorw("failed to find outgoing follow requests", "userID", userID, "error", err.Error())
+		return nil, 0, err
+	}
+
+	return requests, count, nil
+}
+
+// ApproveFollowRequest turns the pending request from requesterID into a follow of targetID.
+func (s *UserService) ApproveFollowRequest(ctx context.Context, targetID string, requesterID string) error {
+	exists, err := s.requestRepo.Exists(ctx, requesterID, targetID)
+	if err != nil {
+		s.logger.Errorw("failed to find follow request", "requesterID", requesterID, "targetID", targetID, "error", err.Error())
+		return err
+	}
+	if !exists {
+		return sql.ErrNoRows
+	}
+
+	return withTx(ctx, s.db, func(tx *sql.Tx) error {
+		if err := s.requestRepo.Delete(ctx, tx, requesterID, targetID); err != nil {
+			s.logger.Errorw("failed to delete follow request", "requesterID", requesterID, "targetID", targetID, "error", err.Error())
+			return err
+		}
+
+		created, err := s.followerRepo.Save(ctx, tx, requesterID, targetID)
+		if err != nil {
+			s.logger.Errorw("failed to save follower", "followerID", requesterID, "followeeID", targetID, "error", err.Error())
+			return err
+		}
+		// approved twice at once
+		if !created {
+			return nil
+		}
+
+		event := types.UserFollowedEvent{FollowerID: requesterID, FolloweeID: targetID, Approved: true}
+		return s.eventService.Publish(ctx, tx, constants.EventUserFollowed, event, targetID)
+	})
+}
+
+func (s *UserService) RejectFollowRequest(ctx context.Context, targetID string, requesterID string) error {
+	exists, err := s.requestRepo.Exists(ctx, requesterID, targetID)
+	if err != nil {
+		s.logger.Errorw("failed to find follow request", "requesterID", requesterID, "targetID", targetID, "error", err.Error())
+		return err
+	}
+	if !exists {
+		return sql.ErrNoRows
+	}
+
+	if err := s.requestRepo.Delete(ctx, nil, requesterID, targetID); err != nil {
+		s.logger.Errorw("failed to delete follow request", "requesterID", requesterID, "targetID", targetID, "error", err.Error())
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,516 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/wanafiq/feed-api/internal/config"
+	"github.com/wanafiq/feed-api/internal/constants"
+	"github.com/wanafiq/feed-api/internal/middleware"
+	"github.com/wanafiq/feed-api/internal/models"
+	"github.com/wanafiq/feed-api/internal/repository"
+	"github.com/wanafiq/feed-api/internal/types"
+	"go.uber.org/zap"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// webhookResponseLimit caps how much of a response body is kept in the delivery log.
+const webhookResponseLimit = 1024
+
+// nonPublicPrefixes are the special-purpose ranges netip has no predicate for.
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"), // carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("192.0.2.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("198.51.100.0/24"),
+	netip.MustParsePrefix("203.0.113.0/24"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"), // NAT64, may reach IPv4 private ranges
+	netip.MustParsePrefix("2001:db8::/32"),
+}
+
+// webhookEvents are the events webhooks can subscribe to.
+var webhookEvents = []string{
+	constants.EventPostPublished,
+	constants.EventPostUpdated,
+	constants.EventUserRegistered,
+	constants.EventUserFollowed,
+	constants.EventFollowRequested,
+}
+
+// webhookPayload is the body POSTed to webhooks. ID identifies the event, it is
+// the same for every delivery of it, redeliveries included.
+type webhookPayload struct {
+	ID        int64           `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"createdAt"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// WebhookService delivers events to the URLs admins subscribed. Events are
+// queued per webhook from the outbox and POSTed with a signature header,
+//
+//	X-Feed-Signature: t=<unix time>,v1=<hex HMAC-SHA256 of "<unix time>.<body>">
+//
+// keyed with the webhook secret. Receivers should check the signature and reject
+// old timestamps, so a captured request cannot be replayed. Failed deliveries
+// are retried with exponential backoff, and a webhook whose deliveries keep
+// failing is turned off.
+type WebhookService struct {
+	config       *config.Config
+	db           *sql.DB
+	logger       *zap.SugaredLogger
+	webhookRepo  repository.WebhookRepository
+	auditService *AuditService
+	client       *http.Client
+}
+
+func NewWebhookService(
+	config *config.Config,
+	db *sql.DB,
+	logger *zap.SugaredLogger,
+	webhookRepo repository.WebhookRepository,
+	auditService *AuditService,
+	eventService *EventService,
+) *WebhookService {
+	s := &WebhookService{
+		config:       config,
+		db:           db,
+		logger:       logger,
+		webhookRepo:  webhookRepo,
+		auditService: auditService,
+		client: &http.Client{
+			Timeout:   config.Webhook.Timeout,
+			Transport: webhookTransport(),
+			// a redirect is a misconfigured URL, not a delivery
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+
+	for _, eventType := range webhookEvents {
+		eventService.Subscribe(eventType, "webhook_"+eventType, s.enqueue)
+	}
+
+	return s
+}
+
+// enqueue queues an outbox event for the webhooks subscribed to it.
+func (s *WebhookService) enqueue(ctx context.Context, event *models.Event) error {
+	payload, err := json.Marshal(webhookPayload{
+		ID:        event.ID,
+		Type:      event.EventType,
+		CreatedAt: event.CreatedAt,
+		Data:      event.Payload,
+	})
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.webhookRepo.Enqueue(ctx, event.ID, event.EventType, payload); err != nil {
+		s.logger.Errorw("failed to queue webhook deliveries", "eventID", event.ID, "eventType", event.EventType, "error", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (s *WebhookService) Create(ctx context.Context, userCtx middleware.UserContext, req *types.WebhookRequest) (*models.Webhook, error) {
+	if err := validateWebhookURL(req.URL); err != nil {
+		return nil, err
+	}
+
+	secret, err := generateWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	webhook := &models.Webhook{
+		URL:         req.URL,
+		Description: req.Description,
+		Events:      req.Events,
+		Secret:      secret,
+		IsActive:    req.IsActive == nil || *req.IsActive,
+		CreatedBy:   userCtx.ID,
+	}
+
+	err = withTx(ctx, s.db, func(tx *sql.Tx) error {
+		if err := s.webhookRepo.Save(ctx, tx, webhook); err != nil {
+			s.logger.Errorw("failed to save webhook", "url", req.URL, "error", err.Error())
+			return err
+		}
+
+		return s.auditService.Record(ctx, tx, userCtx, constants.AuditWebhookCreated, constants.AuditTargetWebhook, webhook.ID, nil, withoutSecret(webhook))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return webhook, nil
+}
+
+func (s *WebhookService) GetAll(ctx context.Context, offset int, limit int) ([]*models.Webhook, int, error) {
+	webhooks, total, err := s.webhookRepo.FindAll(ctx, offset, limit)
+	if err != nil {
+		s.logger.Errorw("failed to find webhooks", "error", err.Error())
+		return nil, 0, err
+	}
+
+	for _, webhook := range webhooks {
+		webhook.Secret = ""
+	}
+
+	return webhooks, total, nil
+}
+
+func (s *WebhookService) GetByID(ctx context.Context, webhookID string) (*models.Webhook, error) {
+	webhook, err := s.webhookRepo.FindByID(ctx, webhookID)
+	if err != nil {
+		s.logger.Errorw("failed to find webhook by id", "webhookID", webhookID, "error", err.Error())
+		return nil, err
+	}
+
+	webhook.Secret = ""
+
+	return webhook, nil
+}
+
+func (s *WebhookService) Update(ctx context.Context, userCtx middleware.UserContext, webhookID string, req *types.WebhookRequest) (*models.Webhook, error) {
+	if err := validateWebhookURL(req.URL); err != nil {
+		return nil, err
+	}
+
+	webhook, err := s.GetByID(ctx, webhookID)
+	if err != nil {
+		return nil, err
+	}
+
+	before := *webhook
+
+	webhook.URL = req.URL
+	webhook.Description = req.Description
+	webhook.Events = req.Events
+	webhook.UpdatedBy = &userCtx.ID
+	if req.IsActive != nil {
+		webhook.IsActive = *req.IsActive
+	}
+
+	err = withTx(ctx, s.db, func(tx *sql.Tx) error {
+		if err := s.webhookRepo.Update(ctx, tx, webhook); err != nil {
+			s.logger.Errorw("failed to update webhook", "webhookID", webhookID, "error", err.Error())
+			return err
+		}
+
+		return s.auditService.Record(ctx, tx, userCtx, constants.AuditWebhookUpdated, constants.AuditTargetWebhook, webhook.ID, before, webhook)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return webhook, nil
+}
+
+func (s *WebhookService) Delete(ctx context.Context, userCtx middleware.UserContext, webhookID string) error {
+	webhook, err := s.GetByID(ctx, webhookID)
+	if err != nil {
+		return err
+	}
+
+	return withTx(ctx, s.db, func(tx *sql.Tx) error {
+		if err := s.webhookRepo.Delete(ctx, tx, webhookID); err != nil {
+			if !errors.Is(err, sql.ErrNoRows) {
+				s.logger.Errorw("failed to delete webhook", "webhookID", webhookID, "error", err.Error())
+			}
+			return err
+		}
+
+		return s.auditService.Record(ctx, tx, userCtx, constants.AuditWebhookDeleted, constants.AuditTargetWebhook, webhookID, webhook, nil)
+	})
+}
+
+// GetDeliveries lists the deliveries of a webhook, optionally only those with status.
+func (s *WebhookService) GetDeliveries(ctx context.Context, webhookID string, status string, offset int, limit int) ([]*models.WebhookDelivery, int, error) {
+	if _, err := s.GetByID(ctx, webhookID); err != nil {
+		return nil, 0, err
+	}
+
+	deliveries, total, err := s.webhookRepo.FindDeliveries(ctx, webhookID, status, offset, limit)
+	if err != nil {
+		s.logger.Errorw("failed to find webhook deliveries", "webhookID", webhookID, "error", err.Error())
+		return nil, 0, err
+	}
+
+	return deliveries, total, nil
+}
+
+// Redeliver sends the event of a finished delivery again, as a new delivery.
+func (s *WebhookService) Redeliver(ctx context.Context, webhookID string, deliveryID int64) (*models.WebhookDelivery, error) {
+	delivery, err := s.webhookRepo.FindDeliveryByID(ctx, webhookID, deliveryID)
+	if err != nil {
+		s.logger.Errorw("failed to find webhook delivery by id", "webhookID", webhookID, "deliveryID", deliveryID, "error", err.Error())
+		return nil, err
+	}
+	if delivery.Status == constants.WebhookDeliveryPending {
+		return nil, constants.ErrDeliveryPending
+	}
+
+	redelivery, err := s.webhookRepo.Redeliver(ctx, delivery)
+	if err != nil {
+		s.logger.Errorw("failed to redeliver webhook delivery", "deliveryID", deliveryID, "error", err.Error())
+		return nil, err
+	}
+
+	return redelivery, nil
+}
+
+// Run sends due deliveries on every poll interval until ctx is cancelled.
+func (s *WebhookService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.config.Webhook.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.sendDue(ctx)
+		}
+	}
+}
+
+// sendDue sends batches of due deliveries until none are left.
+func (s *WebhookService) sendDue(ctx context.Context) {
+	for ctx.Err() == nil {
+		deliveries, err := s.webhookRepo.ClaimDue(ctx, s.config.Webhook.BatchSize, s.config.Webhook.Lease)
+		if err != nil {
+			s.logger.Errorw("failed to claim webhook deliveries", "error", err.Error())
+			return
+		}
+
+		webhooks := make(map[string]*models.Webhook)
+		for _, delivery := range deliveries {
+			webhook, ok := webhooks[delivery.WebhookID]
+			if !ok {
+				webhook, err = s.webhookRepo.FindByID(ctx, delivery.WebhookID)
+				if err != nil {
+					s.logger.Errorw("failed to find webhook by id", "webhookID", delivery.WebhookID, "error", err.Error())
+					continue
+				}
+				webhooks[delivery.WebhookID] = webhook
+			}
+
+			// turned off by an earlier delivery of the batch, the rest waits until it is back on
+			if !webhook.IsActive {
+				continue
+			}
+
+			s.send(ctx, webhook, delivery)
+		}
+
+		if len(deliveries) < s.config.Webhook.BatchSize {
+			return
+		}
+	}
+}
+
+func (s *WebhookService) send(ctx context.Context, webhook *models.Webhook, delivery *models.WebhookDelivery) {
+	attempt := s.post(ctx, webhook, delivery)
+	if attempt.Error == nil {
+		if err := s.webhookRepo.MarkSucceeded(ctx, delivery.ID, attempt); err != nil {
+			s.logger.Errorw("failed to mark webhook delivery succeeded", "deliveryID", delivery.ID, "error", err.Error())
+		}
+		if err := s.webhookRepo.RecordSuccess(ctx, webhook.ID); err != nil {
+			s.logger.Errorw("failed to record webhook success", "webhookID", webhook.ID, "error", err.Error())
+		}
+		return
+	}
+
+	if delivery.Attempts < s.config.Webhook.MaxAttempts {
+		nextAttemptAt := time.Now().Add(s.backoff(delivery.Attempts))
+		s.logger.Warnw("webhook delivery failed, will retry", "deliveryID", delivery.ID, "webhookID", webhook.ID,
+			"attempts", delivery.Attempts, "nextAttemptAt", nextAttemptAt, "error", *attempt.Error)
+		if err := s.webhookRepo.MarkFailed(ctx, delivery.ID, attempt, &nextAttemptAt); err != nil {
+			s.logger.Errorw("failed to mark webhook delivery failed", "deliveryID", delivery.ID, "error", err.Error())
+		}
+		return
+	}
+
+	s.logger.Errorw("webhook delivery gave up", "deliveryID", delivery.ID, "webhookID", webhook.ID,
+		"attempts", delivery.Attempts, "error", *attempt.Error)
+	if err := s.webhookRepo.MarkFailed(ctx, delivery.ID, attempt, nil); err != nil {
+		s.logger.Errorw("failed to mark webhook delivery failed", "deliveryID", delivery.ID, "error", err.Error())
+	}
+
+	disabled, err := s.webhookRepo.RecordFailure(ctx, webhook.ID, s.config.Webhook.DisableAfter)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			s.logger.Errorw("failed to record webhook failure", "webhookID", webhook.ID, "error", err.Error())
+		}
+		return
+	}
+	if disabled {
+		webhook.IsActive = false
+		s.logger.Warnw("webhook disabled after failing deliveries", "webhookID", webhook.ID, "url", webhook.URL)
+	}
+}
+
+// post makes one delivery attempt. Anything but a 2xx response is a failure.
+func (s *WebhookService) post(ctx context.Context, webhook *models.Webhook, delivery *models.WebhookDelivery) *models.WebhookAttempt {
+	attempt := &models.WebhookAttempt{}
+	fail := func(err error) *models.WebhookAttempt {
+		message := err.Error()
+		attempt.Error = &message
+		return attempt
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return fail(err)
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Feed-Webhooks/1.0")
+	req.Header.Set("X-Feed-Event", delivery.EventType)
+	req.Header.Set("X-Feed-Delivery", strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set("X-Feed-Signature", "t="+timestamp+",v1="+signWebhook(webhook.Secret, timestamp, delivery.Payload))
+
+	start := time.Now()
+	res, err := s.client.Do(req)
+	attempt.DurationMs = int(time.Since(start).Milliseconds())
+	if err != nil {
+		return fail(err)
+	}
+	defer res.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(res.Body, webhookResponseLimit))
+	responseBody := string(bytes.ToValidUTF8(body, nil))
+	attempt.ResponseStatus = &res.StatusCode
+	attempt.ResponseBody = &responseBody
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fail(fmt.Errorf("unexpected response status %d", res.StatusCode))
+	}
+
+	return attempt
+}
+
+// backoff doubles the retry delay with every attempt, up to the configured maximum.
+func (s *WebhookService) backoff(attempts int) time.Duration {
+	delay := s.config.Webhook.RetryBackoff
+	for i := 1; i < attempts && delay < s.config.Webhook.MaxBackoff; i++ {
+		delay *= 2
+	}
+
+	return min(delay, s.config.Webhook.MaxBackoff)
+}
+
+// withoutSecret is a copy of webhook for the audit log, which must not hold secrets.
+func withoutSecret(webhook *models.Webhook) *models.Webhook {
+	clean := *webhook
+	clean.Secret = ""
+	return &clean
+}
+
+func signWebhook(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func generateWebhookSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+
+	return "whsec_" + hex.EncodeToString(secret), nil
+}
+
+// webhookTransport only connects to public addresses, so webhooks cannot be
+// pointed at the API's own network. The check runs on the resolved address, as
+// a public hostname may resolve to a private one. Proxies are not used, since
+// the check would then only see the proxy.
+func webhookTransport() *http.Transport {
+	dialer := &net.Dialer{
+		Timeout: 30 * time.Second,
+		Control: func(network string, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+
+			ip, err := netip.ParseAddr(host)
+			if err != nil || !isPublicIP(ip) {
+				return fmt.Errorf("%w: %s", constants.ErrPrivateAddress, host)
+			}
+
+			return nil
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return transport
+}
+
+// validateWebhookURL rejects URLs that are not http or https, and hosts that
+// are obviously not public. Hostnames are checked again when connecting.
+func validateWebhookURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" || u.User != nil {
+		return constants.ErrInvalidWebhookURL
+	}
+
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	if ip, err := netip.ParseAddr(host); err == nil {
+		if !isPublicIP(ip) {
+			return constants.ErrInvalidWebhookURL
+		}
+		return nil
+	}
+
+	if !strings.Contains(host, ".") || host == "localhost" || strings.HasSuffix(host, ".localhost") ||
+		strings.HasSuffix(host, ".local") || strings.HasSuffix(host, ".internal") {
+		return constants.ErrInvalidWebhookURL
+	}
+
+	return nil
+}
+
+func isPublicIP(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	if !ip.IsGlobalUnicast() || ip.IsPrivate() {
+		return false
+	}
+
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(ip) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,235 @@
+package services
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/wanafiq/feed-api/internal/config"
+	"github.com/wanafiq/feed-api/internal/constants"
+	"github.com/wanafiq/feed-api/internal/middleware"
+	"github.com/wanafiq/feed-api/internal/models"
+	"github.com/wanafiq/feed-api/internal/repository"
+	"github.com/wanafiq/feed-api/internal/types"
+	"github.com/wanafiq/feed-api/internal/utils"
+	"github.com/wanafiq/feed-api/internal/wordpress"
+	"go.uber.org/zap"
+	"io"
+	"slices"
+	"strings"
+)
+
+const (
+	importStatusImported = "imported"
+	importStatusSkipped  = "skipped"
+	importStatusFailed   = "failed"
+)
+
+type WordPressImportService struct {
+	config       *config.Config
+	db           *sql.DB
+	logger       *zap.SugaredLogger
+	userRepo     repository.UserRepository
+	importRepo   repository.ImportRepository
+	postService  *PostService
+	auditService *AuditService
+}
+
+func NewWordPressImportService(
+	config *config.Config,
+	db *sql.DB,
+	logger *zap.SugaredLogger,
+	userRepo repository.UserRepository,
+	importRepo repository.ImportRepository,
+	postService *PostService,
+	auditService *AuditService,
+) *WordPressImportService {
+	return &WordPressImportService{
+		config:       config,
+		db:           db,
+		logger:       logger,
+		userRepo:     userRepo,
+		importRepo:   importRepo,
+		postService:  postService,
+		auditService: auditService,
+	}
+}
+
+// Import creates a post for every post of a WXR export. WordPress authors are
+// mapped to users by email; the posts of authors without an account go to
+// defaultAuthorEmail, or fail when it is empty. Items imported by a previous run
+// are skipped, so an import can safely be repeated. Posts are saved one by one,
+// so the import is audited once it is over, best effort, with actor empty when
+// run from the command line.
+func (s *WordPressImportService) Import(ctx context.Context, actor middleware.UserContext, r io.Reader, defaultAuthorEmail string) (*models.WordPressImportResult, error) {
+	export, err := wordpress.Parse(r)
+	if err != nil {
+		return nil, constants.ErrInvalidWXR
+	}
+
+	var defaultAuthor *models.User
+	if defaultAuthorEmail != "" {
+		defaultAuthor, err = s.userRepo.FindByEmail(ctx, defaultAuthorEmail)
+		if err != nil {
+			s.logger.Errorw("failed to find user by email", "email", defaultAuthorEmail, "error", err.Error())
+			return nil, err
+		}
+	}
+
+	result := &models.WordPressImportResult{Source: export.Source(), Items: []*models.WordPressImportItem{}}
+
+	authors, err := s.mapAuthors(ctx, export, defaultAuthor, result)
+	if err != nil {
+		return nil, err
+	}
+
+	converter := wordpress.NewConverter(cmp.Or(export.BaseSiteURL, export.Link))
+
+	for _, item := range export.Items {
+		if item.PostType != wordpress.TypePost || !importable(item.Status) {
+			continue
+		}
+
+		itemResult := &models.WordPressImportItem{
+			SourceID: item.PostID,
+			Title:    item.Title,
+			Slug:     item.Slug,
+		}
+		result.Items = append(result.Items, itemResult)
+
+		if err := s.importItem(ctx, result.Source, item, authors, converter, itemResult); err != nil {
+			itemResult.Status = importStatusFailed
+			itemResult.Error = err.Error()
+			result.Failed++
+			continue
+		}
+
+		switch itemResult.Status {
+		case importStatusImported:
+			result.Imported++
+		case importStatusSkipped:
+			result.Skipped++
+		}
+	}
+
+	s.logger.Infow("imported wordpress export", "source", result.Source,
+		"imported", result.Imported, "skipped", result.Skipped, "failed", result.Failed)
+
+	summary := map[string]any{
+		"format":   "wordpress",
+		"source":   result.Source,
+		"imported": result.Imported,
+		"skipped":  result.Skipped,
+		"failed":   result.Failed,
+	}
+	_ = s.auditService.Record(ctx, nil, actor, constants.AuditPostsImported, constants.AuditTargetImport, "", nil, summary)
+
+	return result, nil
+}
+
+// mapAuthors maps WordPress logins to user ids.
+func (s *WordPressImportService) mapAuthors(ctx context.Context, export *wordpress.Export, defaultAuthor *models.User, result *models.WordPressImportResult) (map[string]string, error) {
+	authors := make(map[string]string)
+
+	for _, author := range export.Authors {
+		user, err := s.userRepo.FindByEmail(ctx, author.Email)
+		if err == nil {
+			authors[author.Login] = user.ID
+			continue
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			s.logger.Errorw("failed to find user by email", "email", author.Email, "error", err.Error())
+			return nil, err
+		}
+
+		result.UnmappedAuthors = append(result.UnmappedAuthors, author.Login)
+		if defaultAuthor != nil {
+			authors[author.Login] = defaultAuthor.ID
+		}
+	}
+
+	return authors, nil
+}
+
+func (s *WordPressImportService) importItem(
+	ctx context.Context,
+	source string,
+	item wordpress.Item,
+	authors map[string]string,
+	converter *wordpress.Converter,
+	itemResult *models.WordPressImportItem,
+) error {
+	postID, err := s.importRepo.FindPostID(ctx, source, item.PostID)
+	if err == nil {
+		itemResult.Status = importStatusSkipped
+		itemResult.PostID = postID
+		return nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		s.logger.Errorw("failed to find imported post", "source", source, "sourceID", item.PostID, "error", err.Error())
+		return errors.New("failed to check previous imports")
+	}
+
+	authorID, ok := authors[item.Creator]
+	if !ok {
+		return fmt.Errorf("no user for author %q", item.Creator)
+	}
+
+	content, err := converter.ToMarkdown(item.Content)
+	if err != nil {
+		return fmt.Errorf("failed to convert content: %w", err)
+	}
+
+	tags := item.Terms()
+	slices.Sort(tags)
+
+	req := &types.PostRequest{
+		Title:      cmp.Or(strings.TrimSpace(item.Title), "Untitled"),
+		Content:    content,
+		Tags:       slices.Compact(tags),
+		Publish:    item.Status != wordpress.StatusDraft && item.Status != wordpress.StatusPending,
+		Visibility: constants.PostVisibilityPublic,
+	}
+	if item.Status == wordpress.StatusPrivate {
+		req.Visibility = constants.PostVisibilityPrivate
+	}
+	if err := binding.Validator.ValidateStruct(req); err != nil {
+		return err
+	}
+
+	origin := postOrigin{
+		slug:        utils.GenerateSlug(item.Slug),
+		createdAt:   item.PublishedAt(),
+		publishedAt: item.PublishedAt(),
+		onSave: func(tx *sql.Tx, post *models.Post) error {
+			if err := s.importRepo.Save(ctx, tx, source, item.PostID, post.ID); err != nil {
+				s.logger.Errorw("failed to save imported post", "source", source, "sourceID", item.PostID, "error", err.Error())
+				return err
+			}
+			return nil
+		},
+	}
+
+	post, err := s.postService.save(ctx, authorID, req, origin)
+	if err != nil {
+		return errors.New("failed to save post")
+	}
+
+	itemResult.Status = importStatusImported
+	itemResult.PostID = post.ID
+	itemResult.Slug = post.Slug
+
+	return nil
+}
+
+// importable leaves out trashed posts and the auto-drafts WordPress creates on its own.
+func importable(status string) bool {
+	switch status {
+	case wordpress.StatusPublish, wordpress.StatusFuture, wordpress.StatusPrivate, wordpress.StatusDraft, wordpress.StatusPending:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,81 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LocalStorage writes files under a directory that is served at urlPrefix.
+type LocalStorage struct {
+	dir       string
+	urlPrefix string
+}
+
+func NewLocalStorage(dir string, urlPrefix string) (*LocalStorage, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	return &LocalStorage{
+		dir:       dir,
+		urlPrefix: strings.TrimRight(urlPrefix, "/"),
+	}, nil
+}
+
+func (s *LocalStorage) Put(ctx context.Context, key string, r io.Reader, contentType string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	// write to a temporary file first so readers never see a partial upload
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+func (s *LocalStorage) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+func (s *LocalStorage) URL(key string) string {
+	return s.urlPrefix + "/" + key
+}
+
+// path resolves key inside the storage directory, rejecting keys that escape it.
+func (s *LocalStorage) path(key string) (string, error) {
+	if !filepath.IsLocal(filepath.FromSlash(key)) {
+		return "", errors.New("invalid storage key")
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(key)), nil
+}
@@ -0,0 +1,13 @@
+package storage
+
+import (
+	"context"
+	"io"
+)
+
+// Storage keeps uploaded files. Keys are slash separated paths chosen by the caller.
+type Storage interface {
+	Put(ctx context.Context, key string, r io.Reader, contentType string) error
+	Delete(ctx context.Context, key string) error
+	URL(key string) string
+}
@@ -4,6 +4,7 @@ type RegisterRequest struct {
 	Username string `json:"username" binding:"required,min=3"`
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required,min=8,max=72"`
+	Locale   string `json:"locale" binding:"omitempty,max=35,bcp47_language_tag"`
 }
 
 type LoginRequest struct {
@@ -0,0 +1,9 @@
+package types
+
+type BookmarkRequest struct {
+	CollectionID string `json:"collectionId"`
+}
+
+type BookmarkCollectionRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
@@ -0,0 +1,5 @@
+package types
+
+type DigestSettingsRequest struct {
+	Frequency string `json:"frequency" binding:"required,oneof=off daily weekly"`
+}
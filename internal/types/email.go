@@ -4,3 +4,21 @@ type ConfirmationEmailData struct {
 	Username      string
 	ActivationUrl string
 }
+
+type DigestEmailData struct {
+	Username          string
+	Frequency         string // "daily" or "weekly"
+	Posts             []DigestPostData
+	MorePosts         int
+	Notifications     []string
+	MoreNotifications int
+	NotificationsUrl  string
+	UnsubscribeUrl    string
+}
+
+type DigestPostData struct {
+	Title   string
+	Author  string
+	Excerpt string
+	Url     string
+}
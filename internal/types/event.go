@@ -0,0 +1,32 @@
+package types
+
+type UserRegisteredEvent struct {
+	UserID string `json:"userId"`
+}
+
+type UserFollowedEvent struct {
+	FollowerID string `json:"followerId"`
+	FolloweeID string `json:"followeeId"`
+	Approved   bool   `json:"approved,omitempty"` // the followee approved a follow request
+}
+
+type FollowRequestedEvent struct {
+	RequesterID string `json:"requesterId"`
+	TargetID    string `json:"targetId"`
+}
+
+type PostReactedEvent struct {
+	UserID string `json:"userId"`
+	PostID string `json:"postId"`
+	Type   string `json:"type"`
+}
+
+type PostPublishedEvent struct {
+	PostID   string `json:"postId"`
+	AuthorID string `json:"authorId"`
+}
+
+type PostUpdatedEvent struct {
+	PostID   string `json:"postId"`
+	AuthorID string `json:"authorId"`
+}
@@ -1,8 +1,22 @@
 package types
 
 type PostRequest struct {
-	Title   string   `json:"title" binding:"required"`
-	Content string   `json:"content" binding:"required"`
-	Tags    []string `json:"tags"`
-	Publish bool     `json:"publish"`
+	Title      string   `json:"title" binding:"required"`
+	Content    string   `json:"content" binding:"required"`
+	Summary    string   `json:"summary" binding:"omitempty,max=500"`
+	Tags       []string `json:"tags"`
+	Publish    bool     `json:"publish"`
+	Visibility string   `json:"visibility" binding:"omitempty,oneof=public unlisted followers private"`
+
+	CoverImage      string `json:"coverImage" binding:"omitempty,http_url,max=2048"`
+	MetaDescription string `json:"metaDescription" binding:"omitempty,max=300"`
+	CanonicalURL    string `json:"canonicalUrl" binding:"omitempty,http_url,max=2048"`
+	OGTitle         string `json:"ogTitle" binding:"omitempty,max=255"`
+	OGDescription   string `json:"ogDescription" binding:"omitempty,max=300"`
+	OGImage         string `json:"ogImage" binding:"omitempty,http_url,max=2048"`
+	TwitterCard     string `json:"twitterCard" binding:"omitempty,oneof=summary summary_large_image"`
+}
+
+type CoAuthorRequest struct {
+	UserID string `json:"userId" binding:"required"`
 }
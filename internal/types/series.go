@@ -0,0 +1,10 @@
+package types
+
+type SeriesRequest struct {
+	Title       string `json:"title" binding:"required,max=255"`
+	Description string `json:"description"`
+}
+
+type SeriesOrderRequest struct {
+	PostIDs []string `json:"postIds" binding:"required,min=1"`
+}
@@ -0,0 +1,8 @@
+package types
+
+import "time"
+
+type StreamTicketResponse struct {
+	Ticket    string    `json:"ticket"`
+	ExpiredAt time.Time `json:"expiredAt"`
+}
@@ -0,0 +1,13 @@
+package types
+
+type PrivacyRequest struct {
+	IsPrivate *bool `json:"isPrivate" binding:"required"`
+}
+
+type LocaleRequest struct {
+	Locale string `json:"locale" binding:"required,max=35,bcp47_language_tag"`
+}
+
+type FollowResponse struct {
+	Status string `json:"status"`
+}
@@ -0,0 +1,8 @@
+package types
+
+type WebhookRequest struct {
+	URL         string   `json:"url" binding:"required,http_url,max=2000"`
+	Description string   `json:"description" binding:"max=255"`
+	Events      []string `json:"events" binding:"required,min=1,unique,dive,oneof=post.published post.updated user.registered user.followed user.follow_requested"`
+	IsActive    *bool    `json:"isActive"`
+}
@@ -0,0 +1,34 @@
+package utils
+
+import (
+	"encoding/base64"
+	"github.com/wanafiq/feed-api/internal/constants"
+	"strings"
+	"time"
+)
+
+// EncodeCursor builds an opaque keyset cursor from a timestamp and an id.
+func EncodeCursor(t time.Time, id string) string {
+	raw := t.UTC().Format(time.RFC3339Nano) + "|" + id
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeCursor reverses EncodeCursor.
+func DecodeCursor(cursor string) (time.Time, string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, "", constants.ErrInvalidCursor
+	}
+
+	parts := strings.SplitN(string(raw), "|", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return time.Time{}, "", constants.ErrInvalidCursor
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return time.Time{}, "", constants.ErrInvalidCursor
+	}
+
+	return t, parts[1], nil
+}
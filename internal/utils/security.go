@@ -1,6 +1,8 @@
 package utils
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"github.com/dgrijalva/jwt-go"
 	"github.com/wanafiq/feed-api/internal/constants"
 	"github.com/wanafiq/feed-api/internal/models"
@@ -15,7 +17,9 @@ const (
 
 type CustomClaims struct {
 	jwt.StandardClaims
-	Role string `json:"role"`
+	Role     string `json:"role"`
+	Username string `json:"username"`
+	Email    string `json:"email"`
 }
 
 func Hash(value string) (string, error) {
@@ -31,6 +35,12 @@ func VerifyHash(hashedValue, value string) bool {
 	return err == nil
 }
 
+// HashToken hashes a random token so it can be stored and, unlike Hash, looked up.
+func HashToken(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
+
 func GenerateJWT(user *models.User, secret string, expiredAt time.Time, issuer string, audience string) (string, error) {
 	secretKey := []byte(secret)
 	now := time.Now()
@@ -44,7 +54,9 @@ func GenerateJWT(user *models.User, secret string, expiredAt time.Time, issuer s
 			Issuer:    issuer,
 			Audience:  audience,
 		},
-		Role: user.Role.Name,
+		Role:     user.Role.Name,
+		Username: user.Username,
+		Email:    user.Email,
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -0,0 +1,74 @@
+package wordpress
+
+import (
+	md "github.com/JohannesKaufmann/html-to-markdown"
+	"github.com/JohannesKaufmann/html-to-markdown/plugin"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	blockComment   = regexp.MustCompile(`<!--\s*/?wp:[\s\S]*?-->`)
+	blockTag       = regexp.MustCompile(`(?i)^<(p|div|h[1-6]|ul|ol|li|blockquote|pre|table|figure|hr|img|iframe)\b`)
+	blankLines     = regexp.MustCompile(`\n\s*\n`)
+	preBlock       = regexp.MustCompile(`(?is)<pre\b.*?</pre>`)
+	prePlaceholder = regexp.MustCompile(`\x00(\d+)\x00`)
+)
+
+// Converter turns WordPress post content into Markdown.
+type Converter struct {
+	converter *md.Converter
+}
+
+// NewConverter resolves relative links and images against siteURL.
+func NewConverter(siteURL string) *Converter {
+	converter := md.NewConverter(md.DomainFromURL(siteURL), true, nil)
+	converter.Use(plugin.GitHubFlavored())
+
+	return &Converter{converter: converter}
+}
+
+func (c *Converter) ToMarkdown(content string) (string, error) {
+	content = blockComment.ReplaceAllString(content, "")
+
+	markdown, err := c.converter.ConvertString(autoParagraph(content))
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(markdown), nil
+}
+
+// autoParagraph wraps the blank-line separated paragraphs of classic editor
+// content in <p>, as WordPress does when rendering it. Preformatted blocks are
+// left untouched.
+func autoParagraph(content string) string {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+
+	var pres []string
+	content = preBlock.ReplaceAllStringFunc(content, func(pre string) string {
+		pres = append(pres, pre)
+		return "\n\n\x00" + strconv.Itoa(len(pres)-1) + "\x00\n\n"
+	})
+
+	var b strings.Builder
+	for _, chunk := range blankLines.Split(content, -1) {
+		chunk = strings.TrimSpace(chunk)
+		if chunk == "" {
+			continue
+		}
+
+		if blockTag.MatchString(chunk) || prePlaceholder.MatchString(chunk) {
+			b.WriteString(chunk)
+		} else {
+			b.WriteString("<p>" + chunk + "</p>")
+		}
+		b.WriteString("\n\n")
+	}
+
+	return prePlaceholder.ReplaceAllStringFunc(b.String(), func(placeholder string) string {
+		i, _ := strconv.Atoi(strings.Trim(placeholder, "\x00"))
+		return pres[i]
+	})
+}
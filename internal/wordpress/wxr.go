@@ -0,0 +1,108 @@
+package wordpress
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+	"time"
+)
+
+// Item statuses and types of a WXR export.
+const (
+	StatusPublish = "publish"
+	StatusFuture  = "future"
+	StatusPrivate = "private"
+	StatusDraft   = "draft"
+	StatusPending = "pending"
+
+	TypePost = "post"
+)
+
+// Export is the part of a WordPress eXtended RSS (WXR) file the importer uses.
+// Elements are matched by local name so every WXR version (1.0 to 1.2) parses.
+type Export struct {
+	Title       string   `xml:"channel>title"`
+	Link        string   `xml:"channel>link"`
+	BaseSiteURL string   `xml:"channel>base_site_url"`
+	Authors     []Author `xml:"channel>author"`
+	Items       []Item   `xml:"channel>item"`
+}
+
+type Author struct {
+	Login       string `xml:"author_login"`
+	Email       string `xml:"author_email"`
+	DisplayName string `xml:"author_display_name"`
+}
+
+type Item struct {
+	Title      string     `xml:"title"`
+	Link       string     `xml:"link"`
+	Creator    string     `xml:"creator"`
+	Content    string     `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	PostID     string     `xml:"post_id"`
+	PostDate   string     `xml:"post_date_gmt"`
+	Slug       string     `xml:"post_name"`
+	Status     string     `xml:"status"`
+	PostType   string     `xml:"post_type"`
+	Categories []Category `xml:"category"`
+}
+
+// Category is a category or a tag of an item, told apart by Domain.
+type Category struct {
+	Domain   string `xml:"domain,attr"`
+	Nicename string `xml:"nicename,attr"`
+	Name     string `xml:",chardata"`
+}
+
+func Parse(r io.Reader) (*Export, error) {
+	var export Export
+	if err := xml.NewDecoder(r).Decode(&export); err != nil {
+		return nil, err
+	}
+
+	return &export, nil
+}
+
+// Source identifies the site the export came from.
+func (e *Export) Source() string {
+	site := e.BaseSiteURL
+	if site == "" {
+		site = e.Link
+	}
+
+	return "wordpress:" + strings.TrimSuffix(site, "/")
+}
+
+// PublishedAt returns the publish date of an item. WordPress leaves it zeroed
+// for drafts.
+func (i *Item) PublishedAt() *time.Time {
+	if i.PostDate == "" || strings.HasPrefix(i.PostDate, "0000") {
+		return nil
+	}
+
+	t, err := time.Parse(time.DateTime, i.PostDate)
+	if err != nil {
+		return nil
+	}
+
+	return &t
+}
+
+// Terms returns the names of the item's categories and tags.
+func (i *Item) Terms() []string {
+	var terms []string
+	for _, category := range i.Categories {
+		if category.Domain != "category" && category.Domain != "post_tag" {
+			continue
+		}
+		// the default category of every WordPress site says nothing about the post
+		if category.Domain == "category" && category.Nicename == "uncategorized" {
+			continue
+		}
+		if name := strings.TrimSpace(category.Name); name != "" {
+			terms = append(terms, name)
+		}
+	}
+
+	return terms
+}
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS blocks
+(
+    blocker_id UUID        NOT NULL,
+    blocked_id UUID        NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (blocker_id, blocked_id),
+    FOREIGN KEY (blocker_id) REFERENCES users (id),
+    FOREIGN KEY (blocked_id) REFERENCES users (id)
+)
\ No newline at end of file
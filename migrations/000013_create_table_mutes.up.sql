@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS mutes
+(
+    muter_id   UUID        NOT NULL,
+    muted_id   UUID        NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (muter_id, muted_id),
+    FOREIGN KEY (muter_id) REFERENCES users (id),
+    FOREIGN KEY (muted_id) REFERENCES users (id)
+)
\ No newline at end of file
@@ -0,0 +1,2 @@
+ALTER TABLE users
+    DROP COLUMN IF EXISTS is_private;
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS follow_requests;
\ No newline at end of file
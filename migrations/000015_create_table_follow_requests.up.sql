@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS follow_requests
+(
+    requester_id UUID        NOT NULL,
+    target_id    UUID        NOT NULL,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (requester_id, target_id),
+    FOREIGN KEY (requester_id) REFERENCES users (id),
+    FOREIGN KEY (target_id) REFERENCES users (id)
+)
\ No newline at end of file
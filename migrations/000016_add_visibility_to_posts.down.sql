@@ -0,0 +1,3 @@
+ALTER TABLE posts
+    DROP CONSTRAINT IF EXISTS chk_post_visibility,
+    DROP COLUMN IF EXISTS visibility;
\ No newline at end of file
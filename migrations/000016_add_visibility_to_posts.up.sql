@@ -0,0 +1,3 @@
+ALTER TABLE posts
+    ADD COLUMN visibility VARCHAR(20) NOT NULL DEFAULT 'public',
+    ADD CONSTRAINT chk_post_visibility CHECK (visibility IN ('public', 'unlisted', 'followers', 'private'));
\ No newline at end of file
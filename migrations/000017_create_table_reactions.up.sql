@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS reactions
+(
+    user_id    UUID        NOT NULL,
+    post_id    UUID        NOT NULL,
+    type       VARCHAR(30) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (user_id, post_id, type),
+    FOREIGN KEY (user_id) REFERENCES users (id),
+    FOREIGN KEY (post_id) REFERENCES posts (id)
+);
+
+CREATE INDEX idx_reactions_post_id ON reactions (post_id);
\ No newline at end of file
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS bookmark_collections
+(
+    id         UUID         DEFAULT gen_random_uuid() PRIMARY KEY,
+    user_id    UUID         NOT NULL REFERENCES users (id),
+    name       VARCHAR(100) NOT NULL,
+    created_at TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+
+    UNIQUE (user_id, name)
+)
\ No newline at end of file
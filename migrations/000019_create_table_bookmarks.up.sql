@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS bookmarks
+(
+    id            UUID DEFAULT gen_random_uuid() PRIMARY KEY,
+    user_id       UUID        NOT NULL REFERENCES users (id),
+    post_id       UUID        NOT NULL REFERENCES posts (id),
+    collection_id UUID REFERENCES bookmark_collections (id) ON DELETE SET NULL,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    UNIQUE (user_id, post_id)
+);
+
+CREATE INDEX idx_bookmarks_user_created ON bookmarks (user_id, created_at DESC, id DESC);
\ No newline at end of file
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS post_views_daily
+(
+    post_id UUID NOT NULL REFERENCES posts (id),
+    day     DATE NOT NULL,
+    views   INT  NOT NULL DEFAULT 0,
+
+    PRIMARY KEY (post_id, day)
+)
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS post_daily_viewers;
\ No newline at end of file
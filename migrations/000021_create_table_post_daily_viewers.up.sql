@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS post_daily_viewers
+(
+    post_id    UUID        NOT NULL REFERENCES posts (id),
+    day        DATE        NOT NULL,
+    viewer_key VARCHAR(64) NOT NULL, -- user id or hashed client fingerprint
+
+    PRIMARY KEY (post_id, day, viewer_key)
+)
\ No newline at end of file
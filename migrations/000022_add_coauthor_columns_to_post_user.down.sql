@@ -0,0 +1,6 @@
+ALTER TABLE post_user
+    DROP CONSTRAINT IF EXISTS chk_post_user_status,
+    DROP CONSTRAINT IF EXISTS chk_post_user_role,
+    DROP COLUMN IF EXISTS created_at,
+    DROP COLUMN IF EXISTS status,
+    DROP COLUMN IF EXISTS role;
\ No newline at end of file
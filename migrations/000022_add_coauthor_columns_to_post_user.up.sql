@@ -0,0 +1,6 @@
+ALTER TABLE post_user
+    ADD COLUMN role       VARCHAR(20) NOT NULL DEFAULT 'author',
+    ADD COLUMN status     VARCHAR(20) NOT NULL DEFAULT 'accepted',
+    ADD COLUMN created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    ADD CONSTRAINT chk_post_user_role CHECK (role IN ('author', 'coauthor')),
+    ADD CONSTRAINT chk_post_user_status CHECK (status IN ('pending', 'accepted'));
\ No newline at end of file
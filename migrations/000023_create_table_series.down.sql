@@ -0,0 +1 @@
+DROP TABLE IF EXISTS series;
\ No newline at end of file
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS series
+(
+    id          UUID         DEFAULT gen_random_uuid() PRIMARY KEY,
+    author_id   UUID         NOT NULL REFERENCES users (id),
+    title       VARCHAR(255) NOT NULL,
+    slug        VARCHAR(255) NOT NULL,
+    description TEXT,
+    created_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_series_author ON series (author_id);
\ No newline at end of file
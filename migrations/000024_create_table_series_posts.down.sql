@@ -0,0 +1 @@
+DROP TABLE IF EXISTS series_posts;
\ No newline at end of file
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS series_posts
+(
+    series_id UUID        NOT NULL REFERENCES series (id) ON DELETE CASCADE,
+    post_id   UUID        NOT NULL UNIQUE REFERENCES posts (id),
+    position  INT         NOT NULL,
+    added_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (series_id, post_id),
+    -- deferred so posts can swap positions within a transaction
+    UNIQUE (series_id, position) DEFERRABLE INITIALLY DEFERRED
+);
\ No newline at end of file
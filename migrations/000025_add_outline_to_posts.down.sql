@@ -0,0 +1,5 @@
+ALTER TABLE posts
+    DROP COLUMN excerpt,
+    DROP COLUMN word_count,
+    DROP COLUMN reading_time,
+    DROP COLUMN toc;
\ No newline at end of file
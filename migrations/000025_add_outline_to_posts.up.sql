@@ -0,0 +1,10 @@
+ALTER TABLE posts
+    ADD COLUMN excerpt      TEXT  NOT NULL DEFAULT '',
+    ADD COLUMN word_count   INT   NOT NULL DEFAULT 0,
+    ADD COLUMN reading_time INT   NOT NULL DEFAULT 0,
+    ADD COLUMN toc          JSONB NOT NULL DEFAULT '[]';
+
+UPDATE posts
+SET excerpt      = LEFT(content, 200),
+    word_count   = COALESCE(array_length(regexp_split_to_array(btrim(content), '\s+'), 1), 0),
+    reading_time = CEIL(COALESCE(array_length(regexp_split_to_array(btrim(content), '\s+'), 1), 0) / 200.0);
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS media;
\ No newline at end of file
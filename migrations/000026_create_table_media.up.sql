@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS media
+(
+    id           UUID         DEFAULT gen_random_uuid() PRIMARY KEY,
+    user_id      UUID         NOT NULL REFERENCES users (id),
+    post_id      UUID REFERENCES posts (id) ON DELETE SET NULL,
+    storage_key  VARCHAR(255) NOT NULL UNIQUE,
+    content_type VARCHAR(100) NOT NULL,
+    size         BIGINT       NOT NULL,
+    width        INT          NOT NULL,
+    height       INT          NOT NULL,
+    thumbnails   JSONB        NOT NULL DEFAULT '[]',
+    orphaned_at  TIMESTAMPTZ,
+    created_at   TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_media_user ON media (user_id);
+CREATE INDEX idx_media_post ON media (post_id);
+CREATE INDEX idx_media_orphaned ON media (orphaned_at) WHERE post_id IS NULL;
\ No newline at end of file
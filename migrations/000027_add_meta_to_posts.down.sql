@@ -0,0 +1,8 @@
+ALTER TABLE posts
+    DROP COLUMN cover_image,
+    DROP COLUMN meta_description,
+    DROP COLUMN canonical_url,
+    DROP COLUMN og_title,
+    DROP COLUMN og_description,
+    DROP COLUMN og_image,
+    DROP COLUMN twitter_card;
\ No newline at end of file
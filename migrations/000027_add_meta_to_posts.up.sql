@@ -0,0 +1,8 @@
+ALTER TABLE posts
+    ADD COLUMN cover_image      VARCHAR(2048) NOT NULL DEFAULT '',
+    ADD COLUMN meta_description VARCHAR(300)  NOT NULL DEFAULT '',
+    ADD COLUMN canonical_url    VARCHAR(2048) NOT NULL DEFAULT '',
+    ADD COLUMN og_title         VARCHAR(255)  NOT NULL DEFAULT '',
+    ADD COLUMN og_description   VARCHAR(300)  NOT NULL DEFAULT '',
+    ADD COLUMN og_image         VARCHAR(2048) NOT NULL DEFAULT '',
+    ADD COLUMN twitter_card     VARCHAR(30)   NOT NULL DEFAULT '';
\ No newline at end of file
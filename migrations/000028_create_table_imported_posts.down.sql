@@ -0,0 +1 @@
+DROP TABLE IF EXISTS imported_posts;
\ No newline at end of file
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS imported_posts
+(
+    source     TEXT        NOT NULL,
+    source_id  TEXT        NOT NULL,
+    post_id    UUID        NOT NULL REFERENCES posts (id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (source, source_id)
+);
\ No newline at end of file
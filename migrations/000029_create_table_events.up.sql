@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS events
+(
+    id              BIGSERIAL PRIMARY KEY,
+    event_type      VARCHAR(100) NOT NULL,
+    handler         VARCHAR(100) NOT NULL,
+    payload         JSONB        NOT NULL,
+    status          VARCHAR(20)  NOT NULL DEFAULT 'pending',
+    attempts        INT          NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    last_error      TEXT,
+    created_at      TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    created_by      VARCHAR(100) NOT NULL,
+    updated_at      TIMESTAMPTZ,
+    updated_by      VARCHAR(100)
+);
+
+CREATE INDEX idx_events_due ON events (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX idx_events_status ON events (status, created_at);
\ No newline at end of file
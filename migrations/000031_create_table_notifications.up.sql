@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS notifications
+(
+    id         BIGSERIAL PRIMARY KEY,
+    user_id    UUID        NOT NULL,
+    type       VARCHAR(30) NOT NULL,
+    actor_id   UUID        NOT NULL,
+    post_id    UUID,
+    reaction   VARCHAR(30) NOT NULL DEFAULT '',
+    read_at    TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    FOREIGN KEY (actor_id) REFERENCES users (id) ON DELETE CASCADE,
+    FOREIGN KEY (post_id) REFERENCES posts (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_notifications_user_id ON notifications (user_id, created_at DESC);
+CREATE INDEX idx_notifications_unread ON notifications (user_id) WHERE read_at IS NULL;
\ No newline at end of file
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS notification_preferences
+(
+    user_id    UUID        NOT NULL,
+    type       VARCHAR(30) NOT NULL,
+    enabled    BOOLEAN     NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (user_id, type),
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS digest_settings;
\ No newline at end of file
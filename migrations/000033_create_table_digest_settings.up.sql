@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS digest_settings
+(
+    user_id    UUID        PRIMARY KEY,
+    frequency  VARCHAR(10) NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    CONSTRAINT chk_digest_frequency CHECK (frequency IN ('off', 'daily', 'weekly'))
+);
\ No newline at end of file
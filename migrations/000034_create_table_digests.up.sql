@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS digests
+(
+    id                 BIGSERIAL PRIMARY KEY,
+    user_id            UUID        NOT NULL,
+    frequency          VARCHAR(10) NOT NULL,
+    period_start       TIMESTAMPTZ NOT NULL,
+    period_end         TIMESTAMPTZ NOT NULL,
+    post_count         INT         NOT NULL DEFAULT 0,
+    notification_count INT         NOT NULL DEFAULT 0,
+    sent_at            TIMESTAMPTZ,
+    created_at         TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    UNIQUE (user_id, period_start)
+);
+
+CREATE INDEX idx_digests_user_period_end ON digests (user_id, period_end DESC);
\ No newline at end of file
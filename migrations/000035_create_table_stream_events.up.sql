@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS stream_events
+(
+    id         BIGSERIAL PRIMARY KEY,
+    type       VARCHAR(30) NOT NULL,
+    user_id    UUID REFERENCES users (id) ON DELETE CASCADE,
+    author_id  UUID REFERENCES users (id) ON DELETE CASCADE,
+    data       JSONB       NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_stream_events_user_id ON stream_events (user_id, id) WHERE user_id IS NOT NULL;
+CREATE INDEX idx_stream_events_author_id ON stream_events (author_id, id) WHERE author_id IS NOT NULL;
+CREATE INDEX idx_stream_events_created_at ON stream_events (created_at);
\ No newline at end of file
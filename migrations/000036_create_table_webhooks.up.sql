@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS webhooks
+(
+    id            UUID         DEFAULT gen_random_uuid() PRIMARY KEY,
+    url           TEXT         NOT NULL,
+    description   VARCHAR(255) NOT NULL DEFAULT '',
+    events        TEXT[]       NOT NULL,
+    secret        VARCHAR(100) NOT NULL,
+    is_active     BOOLEAN      NOT NULL DEFAULT TRUE,
+    failure_count INT          NOT NULL DEFAULT 0, -- deliveries failed in a row
+    disabled_at   TIMESTAMPTZ,
+    created_at    TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    created_by    VARCHAR(100) NOT NULL,
+    updated_at    TIMESTAMPTZ,
+    updated_by    VARCHAR(100)
+);
+
+CREATE INDEX idx_webhooks_events ON webhooks USING GIN (events) WHERE is_active;
\ No newline at end of file
@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS webhook_deliveries
+(
+    id              BIGSERIAL PRIMARY KEY,
+    webhook_id      UUID         NOT NULL REFERENCES webhooks (id) ON DELETE CASCADE,
+    event_id        BIGINT       NOT NULL,
+    event_type      VARCHAR(100) NOT NULL,
+    payload         JSONB        NOT NULL,
+    status          VARCHAR(20)  NOT NULL DEFAULT 'pending',
+    attempts        INT          NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    response_status INT,
+    response_body   TEXT,
+    last_error      TEXT,
+    duration_ms     INT,
+    redelivery_of   BIGINT REFERENCES webhook_deliveries (id) ON DELETE SET NULL,
+    created_at      TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    delivered_at    TIMESTAMPTZ
+);
+
+-- an event is queued once per webhook, however often the outbox hands it over
+CREATE UNIQUE INDEX idx_webhook_deliveries_event ON webhook_deliveries (webhook_id, event_id) WHERE redelivery_of IS NULL;
+CREATE INDEX idx_webhook_deliveries_due ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX idx_webhook_deliveries_webhook_id ON webhook_deliveries (webhook_id, created_at DESC);
\ No newline at end of file
@@ -0,0 +1,37 @@
+CREATE TABLE IF NOT EXISTS audit_logs
+(
+    id          BIGSERIAL PRIMARY KEY,
+    actor_id    UUID, -- NULL when nobody is logged in, e.g. a failed login
+    action      VARCHAR(50)  NOT NULL,
+    target_type VARCHAR(30)  NOT NULL,
+    target_id   VARCHAR(100) NOT NULL DEFAULT '',
+    before      JSONB,
+    after       JSONB,
+    ip          VARCHAR(45)  NOT NULL DEFAULT '',
+    user_agent  TEXT         NOT NULL DEFAULT '',
+    created_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_audit_logs_created_at ON audit_logs (created_at DESC);
+CREATE INDEX idx_audit_logs_actor_id ON audit_logs (actor_id, created_at DESC);
+CREATE INDEX idx_audit_logs_target ON audit_logs (target_type, target_id, created_at DESC);
+CREATE INDEX idx_audit_logs_action ON audit_logs (action, created_at DESC);
+
+CREATE OR REPLACE FUNCTION audit_logs_append_only() RETURNS TRIGGER AS
+$$
+BEGIN
+    RAISE EXCEPTION 'audit_logs is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_logs_no_update_delete
+    BEFORE UPDATE OR DELETE
+    ON audit_logs
+    FOR EACH ROW
+EXECUTE FUNCTION audit_logs_append_only();
+
+CREATE TRIGGER audit_logs_no_truncate
+    BEFORE TRUNCATE
+    ON audit_logs
+    FOR EACH STATEMENT
+EXECUTE FUNCTION audit_logs_append_only();
\ No newline at end of file
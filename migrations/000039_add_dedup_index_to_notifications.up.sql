@@ -0,0 +1,11 @@
+DELETE FROM notifications n
+    USING notifications d
+WHERE n.user_id = d.user_id
+  AND n.type = d.type
+  AND n.actor_id = d.actor_id
+  AND n.post_id IS NOT DISTINCT FROM d.post_id
+  AND n.reaction = d.reaction
+  AND n.id > d.id;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_notifications_dedup
+    ON notifications (user_id, type, actor_id, post_id, reaction) NULLS NOT DISTINCT;
\ No newline at end of file
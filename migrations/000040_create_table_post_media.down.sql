@@ -0,0 +1,12 @@
+DROP INDEX IF EXISTS idx_media_orphaned;
+
+ALTER TABLE media
+    ADD COLUMN post_id UUID REFERENCES posts (id) ON DELETE SET NULL;
+
+UPDATE media m
+SET post_id = (SELECT pm.post_id FROM post_media pm WHERE pm.media_id = m.id LIMIT 1);
+
+CREATE INDEX idx_media_post ON media (post_id);
+CREATE INDEX idx_media_orphaned ON media (orphaned_at) WHERE post_id IS NULL;
+
+DROP TABLE IF EXISTS post_media;
\ No newline at end of file
@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS post_media
+(
+    post_id  UUID NOT NULL,
+    media_id UUID NOT NULL,
+
+    PRIMARY KEY (post_id, media_id),
+    FOREIGN KEY (post_id) REFERENCES posts (id) ON DELETE CASCADE,
+    FOREIGN KEY (media_id) REFERENCES media (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_post_media_media_id ON post_media (media_id);
+
+INSERT INTO post_media (post_id, media_id)
+SELECT post_id, id
+FROM media
+WHERE post_id IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_media_orphaned;
+DROP INDEX IF EXISTS idx_media_post;
+
+ALTER TABLE media
+    DROP COLUMN post_id;
+
+-- orphaned_at is set while no post references the upload
+CREATE INDEX idx_media_orphaned ON media (orphaned_at) WHERE orphaned_at IS NOT NULL;
\ No newline at end of file
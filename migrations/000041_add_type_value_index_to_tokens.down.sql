@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_tokens_type_value;
\ No newline at end of file
@@ -0,0 +1 @@
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tokens_type_value ON tokens (type, value);
\ No newline at end of file